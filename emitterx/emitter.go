@@ -1,45 +1,146 @@
 package emitterx
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 )
 
 // EventPayload represents an event with a dynamic data type.
 type EventPayload struct {
-	Event string      `json:"event"`
-	Data  interface{} `json:"data"`
+	ID             string            `json:"id,omitempty"`
+	IdempotencyKey string            `json:"idempotencyKey,omitempty"`
+	Source         string            `json:"source,omitempty"`
+	Event          string            `json:"event"`
+	Data           interface{}       `json:"data"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
 }
 
 // EventHandler is a function that handles an event.
 type EventHandler func(EventPayload)
 
+// ContextHandler is a function that handles an event together with the
+// context it was emitted with.
+type ContextHandler func(ctx context.Context, event EventPayload)
+
 // EventEmitter is a struct that manages event listeners and emits events.
 type EventEmitter struct {
-	listeners map[string][]EventHandler
-	mu        sync.Mutex
+	listeners         map[string][]ContextHandler
+	middleware        []MiddlewareFunc
+	handlerMiddleware []MiddlewareFunc
+	scheduler         *Scheduler
+	mu                sync.Mutex
 }
 
 // NewEventEmitter creates a new EventEmitter.
 func NewEventEmitter() *EventEmitter {
 	return &EventEmitter{
-		listeners: make(map[string][]EventHandler),
+		listeners: make(map[string][]ContextHandler),
 	}
 }
 
 // On adds a new listener for an event.
 func (e *EventEmitter) On(eventName string, handler EventHandler) {
+	e.OnContext(eventName, func(_ context.Context, event EventPayload) {
+		handler(event)
+	})
+}
+
+// OnContext adds a new listener that also receives the emit context.
+func (e *EventEmitter) OnContext(eventName string, handler ContextHandler) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.listeners[eventName] = append(e.listeners[eventName], handler)
 }
 
+// Use adds middleware that runs once around every Emit, before the event is
+// handed to listeners.
+func (e *EventEmitter) Use(middleware ...MiddlewareFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.middleware = append(e.middleware, middleware...)
+}
+
+// UseHandler adds middleware that runs around every individual listener call.
+func (e *EventEmitter) UseHandler(middleware ...MiddlewareFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlerMiddleware = append(e.handlerMiddleware, middleware...)
+}
+
 // Emit emits an event to all registered listeners.
 func (e *EventEmitter) Emit(event EventPayload) {
+	e.EmitContext(context.Background(), event)
+}
+
+// EmitContext emits an event to all registered listeners, passing ctx through
+// the middleware chain to context-aware listeners.
+func (e *EventEmitter) EmitContext(ctx context.Context, event EventPayload) {
+	e.emit(ctx, event, false)
+}
+
+// EmitAndWait emits an event and blocks until every listener has returned.
+// A panicking listener is recovered and reported as an error, as is any error
+// middleware recorded with EventContext.Error.
+func (e *EventEmitter) EmitAndWait(event EventPayload) error {
+	return e.emit(context.Background(), event, true)
+}
+
+func (e *EventEmitter) emit(ctx context.Context, event EventPayload, wait bool) error {
 	e.mu.Lock()
-	defer e.mu.Unlock()
-	if handlers, found := e.listeners[event.Event]; found {
-		for _, handler := range handlers {
-			go handler(event)
+	handlers := append([]ContextHandler(nil), e.listeners[event.Event]...)
+	middleware := append([]MiddlewareFunc(nil), e.middleware...)
+	handlerMiddleware := append([]MiddlewareFunc(nil), e.handlerMiddleware...)
+	e.mu.Unlock()
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		errs  []error
+	)
+
+	dispatch := func(c *EventContext) {
+		for i, handler := range handlers {
+			wg.Add(1)
+			go func(index int, handler ContextHandler) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						errMu.Lock()
+						errs = append(errs, fmt.Errorf("handler for %s panicked: %v", c.Payload.Event, r))
+						errMu.Unlock()
+					}
+				}()
+
+				call := func(hc *EventContext) {
+					start := time.Now()
+					defer func() { hc.took = time.Since(start) }()
+					handler(hc.Context(), hc.Payload)
+				}
+				hc := newEventContext(c.Context(), c.Payload, HandlerPhase, index, chain(handlerMiddleware, call))
+				hc.Next()
+				if len(hc.errors) > 0 {
+					errMu.Lock()
+					errs = append(errs, hc.errors...)
+					errMu.Unlock()
+				}
+			}(i, handler)
 		}
 	}
+
+	root := newEventContext(ctx, event, EmitPhase, -1, chain(middleware, dispatch))
+	root.Next()
+
+	if !wait {
+		return nil
+	}
+	wg.Wait()
+
+	errs = append(root.errors, errs...)
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
 }
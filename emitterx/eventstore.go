@@ -0,0 +1,651 @@
+package emitterx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// AnyVersion disables the optimistic concurrency check in EventStore.Append.
+const AnyVersion int64 = -1
+
+// ErrVersionConflict is returned by Append when the stream has moved past the expected version.
+var ErrVersionConflict = errors.New("stream version conflict")
+
+// StoredEvent is an event appended to a stream. Versions start at 1.
+type StoredEvent struct {
+	StreamID       string            `json:"streamId"`
+	Version        int64             `json:"version"`
+	ID             string            `json:"id"`
+	IdempotencyKey string            `json:"idempotencyKey,omitempty"`
+	Event          string            `json:"event"`
+	Data           json.RawMessage   `json:"data"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	RecordedAt     time.Time         `json:"recordedAt"`
+}
+
+// Payload converts the stored event back into an EventPayload.
+func (s StoredEvent) Payload() EventPayload {
+	key := s.IdempotencyKey
+	if key == "" {
+		key = s.ID
+	}
+	return EventPayload{
+		ID:             s.ID,
+		IdempotencyKey: key,
+		Event:          s.Event,
+		Data:           s.Data,
+		Metadata:       s.Metadata,
+	}
+}
+
+// Snapshot is the serialised state of a projection at a stream version.
+type Snapshot struct {
+	StreamID   string          `json:"streamId"`
+	Projection string          `json:"projection"`
+	Version    int64           `json:"version"`
+	State      json.RawMessage `json:"state"`
+	TakenAt    time.Time       `json:"takenAt"`
+}
+
+// EventStore is an append-only log of events grouped into per-aggregate streams.
+type EventStore interface {
+	// Append adds events to the end of a stream. When expectedVersion is not
+	// AnyVersion and differs from the current stream version, ErrVersionConflict is returned.
+	// When the stream already holds an event with the ID or idempotency key of
+	// one of events, ErrDuplicateEvent is returned and nothing is appended.
+	Append(streamID string, expectedVersion int64, events ...EventPayload) ([]StoredEvent, error)
+	// Load returns the events of a stream with a version greater than afterVersion.
+	Load(streamID string, afterVersion int64) ([]StoredEvent, error)
+	// Version returns the current version of a stream, or 0 if it is empty.
+	Version(streamID string) (int64, error)
+	// Streams returns the IDs of all streams.
+	Streams() ([]string, error)
+	// SaveSnapshot stores the latest snapshot of a projection of a stream.
+	SaveSnapshot(snapshot Snapshot) error
+	// LoadSnapshot returns the latest snapshot of a projection of a stream, or nil if none was taken.
+	LoadSnapshot(streamID, projection string) (*Snapshot, error)
+}
+
+// eventLog holds the streams and snapshots of an EventStore. Callers
+// synchronise access to it.
+type eventLog struct {
+	Streams   map[string][]StoredEvent `json:"streams"`
+	Snapshots map[string]Snapshot      `json:"snapshots"`
+	// keys holds the IDs and idempotency keys recorded in each stream.
+	keys map[string]map[string]bool
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{
+		Streams:   make(map[string][]StoredEvent),
+		Snapshots: make(map[string]Snapshot),
+		keys:      make(map[string]map[string]bool),
+	}
+}
+
+// index rebuilds keys after the streams were loaded.
+func (l *eventLog) index() {
+	l.keys = make(map[string]map[string]bool, len(l.Streams))
+	for streamID, stream := range l.Streams {
+		keys := make(map[string]bool, len(stream))
+		for _, event := range stream {
+			addEventKeys(keys, event)
+		}
+		l.keys[streamID] = keys
+	}
+}
+
+func addEventKeys(keys map[string]bool, event StoredEvent) {
+	keys[event.ID] = true
+	if event.IdempotencyKey != "" {
+		keys[event.IdempotencyKey] = true
+	}
+}
+
+func (l *eventLog) append(streamID string, expectedVersion int64, stored []StoredEvent) error {
+	current := int64(len(l.Streams[streamID]))
+	if expectedVersion != AnyVersion && expectedVersion != current {
+		return fmt.Errorf("%w: stream %s is at version %d, expected %d", ErrVersionConflict, streamID, current, expectedVersion)
+	}
+
+	batch := make(map[string]bool, len(stored))
+	for _, event := range stored {
+		for _, key := range []string{event.ID, event.IdempotencyKey} {
+			if key == "" {
+				continue
+			}
+			if l.keys[streamID][key] || batch[key] {
+				return fmt.Errorf("%w: %s in stream %s", ErrDuplicateEvent, key, streamID)
+			}
+			batch[key] = true
+		}
+	}
+
+	keys, ok := l.keys[streamID]
+	if !ok {
+		keys = make(map[string]bool)
+		l.keys[streamID] = keys
+	}
+	for i := range stored {
+		stored[i].Version = current + int64(i) + 1
+		addEventKeys(keys, stored[i])
+	}
+	l.Streams[streamID] = append(l.Streams[streamID], stored...)
+	return nil
+}
+
+func (l *eventLog) load(streamID string, afterVersion int64) []StoredEvent {
+	stream := l.Streams[streamID]
+	if afterVersion < 0 {
+		afterVersion = 0
+	}
+	if afterVersion >= int64(len(stream)) {
+		return nil
+	}
+	return append([]StoredEvent(nil), stream[afterVersion:]...)
+}
+
+func (l *eventLog) streamIDs() []string {
+	ids := make([]string, 0, len(l.Streams))
+	for id := range l.Streams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// saveSnapshot reports whether snapshot replaced the stored one.
+func (l *eventLog) saveSnapshot(snapshot Snapshot) bool {
+	key := snapshot.StreamID + "/" + snapshot.Projection
+	if existing, ok := l.Snapshots[key]; ok && existing.Version > snapshot.Version {
+		return false
+	}
+	l.Snapshots[key] = snapshot
+	return true
+}
+
+func (l *eventLog) loadSnapshot(streamID, projection string) *Snapshot {
+	snapshot, ok := l.Snapshots[streamID+"/"+projection]
+	if !ok {
+		return nil
+	}
+	return &snapshot
+}
+
+// MemoryEventStore is an in-memory EventStore. Its events are lost when the
+// process exits; use FileEventStore to keep them.
+type MemoryEventStore struct {
+	log *eventLog
+	mu  sync.RWMutex
+}
+
+// NewMemoryEventStore creates an empty MemoryEventStore.
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{log: newEventLog()}
+}
+
+func (s *MemoryEventStore) Append(streamID string, expectedVersion int64, events ...EventPayload) ([]StoredEvent, error) {
+	stored, err := newStoredEvents(streamID, events)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.log.append(streamID, expectedVersion, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (s *MemoryEventStore) Load(streamID string, afterVersion int64) ([]StoredEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.load(streamID, afterVersion), nil
+}
+
+func (s *MemoryEventStore) Version(streamID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.log.Streams[streamID])), nil
+}
+
+func (s *MemoryEventStore) Streams() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.streamIDs(), nil
+}
+
+func (s *MemoryEventStore) SaveSnapshot(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log.saveSnapshot(snapshot)
+	return nil
+}
+
+func (s *MemoryEventStore) LoadSnapshot(streamID, projection string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.loadSnapshot(streamID, projection), nil
+}
+
+// FileEventStore is an EventStore that keeps its streams and snapshots in a
+// JSON file, rewritten on every change. It is intended for tests and
+// single-process tools.
+type FileEventStore struct {
+	path string
+	log  *eventLog
+	mu   sync.RWMutex
+}
+
+// NewFileEventStore opens the store at path, loading existing streams if the file exists.
+func NewFileEventStore(path string) (*FileEventStore, error) {
+	s := &FileEventStore{path: path, log: newEventLog()}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, s.log); err != nil {
+			return nil, fmt.Errorf("failed to read event store file %s: %v", path, err)
+		}
+	}
+	if s.log.Streams == nil {
+		s.log.Streams = make(map[string][]StoredEvent)
+	}
+	if s.log.Snapshots == nil {
+		s.log.Snapshots = make(map[string]Snapshot)
+	}
+	s.log.index()
+
+	return s, nil
+}
+
+func (s *FileEventStore) Append(streamID string, expectedVersion int64, events ...EventPayload) ([]StoredEvent, error) {
+	stored, err := newStoredEvents(streamID, events)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.log.Streams[streamID]
+	if err := s.log.append(streamID, expectedVersion, stored); err != nil {
+		return nil, err
+	}
+	if err := writeJSONFile(s.path, s.log); err != nil {
+		// Keep memory in line with the file.
+		s.log.Streams[streamID] = previous
+		s.log.index()
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (s *FileEventStore) Load(streamID string, afterVersion int64) ([]StoredEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.load(streamID, afterVersion), nil
+}
+
+func (s *FileEventStore) Version(streamID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int64(len(s.log.Streams[streamID])), nil
+}
+
+func (s *FileEventStore) Streams() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.streamIDs(), nil
+}
+
+func (s *FileEventStore) SaveSnapshot(snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.log.saveSnapshot(snapshot) {
+		return nil
+	}
+	return writeJSONFile(s.path, s.log)
+}
+
+func (s *FileEventStore) LoadSnapshot(streamID, projection string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.log.loadSnapshot(streamID, projection), nil
+}
+
+func newStoredEvents(streamID string, events []EventPayload) ([]StoredEvent, error) {
+	now := time.Now().UTC()
+	stored := make([]StoredEvent, len(events))
+	for i, event := range events {
+		var data json.RawMessage
+		switch raw := event.Data.(type) {
+		case json.RawMessage:
+			data = raw
+		default:
+			encoded, err := json.Marshal(event.Data)
+			if err != nil {
+				return nil, err
+			}
+			data = encoded
+		}
+
+		id := event.ID
+		if id == "" {
+			generated, err := uuid.NewV4()
+			if err != nil {
+				return nil, err
+			}
+			id = generated.String()
+		}
+
+		stored[i] = StoredEvent{
+			StreamID:       streamID,
+			ID:             id,
+			IdempotencyKey: event.IdempotencyKey,
+			Event:          event.Event,
+			Data:           data,
+			Metadata:       event.Metadata,
+			RecordedAt:     now,
+		}
+	}
+	return stored, nil
+}
+
+// Projection builds state by applying the events of a stream in order.
+type Projection interface {
+	Apply(event StoredEvent) error
+}
+
+// SnapshotProjection is a Projection whose state can be saved and restored.
+// ProjectionName identifies the snapshots that belong to it.
+type SnapshotProjection interface {
+	Projection
+	ProjectionName() string
+	Snapshot() (json.RawMessage, error)
+	Restore(state json.RawMessage) error
+}
+
+// Replay applies the events of a stream to projection and returns the last
+// applied version. When projection supports snapshots, replay starts from the
+// latest snapshot instead of the beginning of the stream.
+func Replay(store EventStore, streamID string, projection Projection) (int64, error) {
+	var version int64
+	if snapshotter, ok := projection.(SnapshotProjection); ok {
+		snapshot, err := store.LoadSnapshot(streamID, snapshotter.ProjectionName())
+		if err != nil {
+			return 0, err
+		}
+		if snapshot != nil {
+			if err := snapshotter.Restore(snapshot.State); err != nil {
+				return 0, err
+			}
+			version = snapshot.Version
+		}
+	}
+
+	events, err := store.Load(streamID, version)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		if err := projection.Apply(event); err != nil {
+			return version, fmt.Errorf("failed to apply %s v%d: %v", streamID, event.Version, err)
+		}
+		version = event.Version
+	}
+
+	return version, nil
+}
+
+// TakeSnapshot replays a stream into projection and stores its state as a snapshot.
+func TakeSnapshot(store EventStore, streamID string, projection SnapshotProjection) error {
+	version, err := Replay(store, streamID, projection)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+
+	state, err := projection.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	return store.SaveSnapshot(Snapshot{
+		StreamID:   streamID,
+		Projection: projection.ProjectionName(),
+		Version:    version,
+		State:      state,
+		TakenAt:    time.Now().UTC(),
+	})
+}
+
+// StreamKeyFunc returns the stream an event belongs to, or "" to skip it.
+type StreamKeyFunc func(event EventPayload) string
+
+// StreamByField uses a top-level field of the event data, such as "reference"
+// or "voucherId", as the stream ID, prefixed with prefix.
+func StreamByField(prefix, field string) StreamKeyFunc {
+	return func(event EventPayload) string {
+		fields, ok := dataFields(event)
+		if !ok {
+			return ""
+		}
+		var value interface{}
+		if err := json.Unmarshal(fields[field], &value); err != nil || value == nil {
+			return ""
+		}
+		return prefix + fmt.Sprint(value)
+	}
+}
+
+// EventRecorder appends emitted events to an EventStore and optionally
+// snapshots streams every few events.
+type EventRecorder struct {
+	store         EventStore
+	streamKey     StreamKeyFunc
+	snapshotEvery int64
+	newProjection func() SnapshotProjection
+}
+
+// NewEventRecorder creates a recorder that appends events to the stream chosen by streamKey.
+func NewEventRecorder(store EventStore, streamKey StreamKeyFunc) *EventRecorder {
+	return &EventRecorder{
+		store:     store,
+		streamKey: streamKey,
+	}
+}
+
+// SnapshotEvery takes a snapshot of a stream each time its version reaches a
+// multiple of every, using projections created by newProjection.
+func (r *EventRecorder) SnapshotEvery(every int64, newProjection func() SnapshotProjection) {
+	r.snapshotEvery = every
+	r.newProjection = newProjection
+}
+
+// Attach records every occurrence of the named events emitted by emitter.
+// Events are recorded on the emitting goroutine before any listener runs, so
+// each stream holds them in the order they were emitted. Events already in
+// their stream, such as redeliveries, are skipped.
+func (r *EventRecorder) Attach(emitter *EventEmitter, eventNames ...string) {
+	names := make(map[string]bool, len(eventNames))
+	for _, name := range eventNames {
+		names[name] = true
+	}
+	emitter.Use(func(c *EventContext) {
+		if names[c.Payload.Event] {
+			if _, err := r.Record(c.Payload); err != nil && !errors.Is(err, ErrDuplicateEvent) {
+				logrus.WithField("event", c.Payload.Event).Error("failed to record event: ", err)
+			}
+		}
+		c.Next()
+	})
+}
+
+// Record appends a single event to its stream. It returns ErrDuplicateEvent
+// when the stream already holds an event with the same ID or idempotency key.
+func (r *EventRecorder) Record(event EventPayload) (*StoredEvent, error) {
+	streamID := r.streamKey(event)
+	if streamID == "" {
+		return nil, nil
+	}
+
+	stored, err := r.store.Append(streamID, AnyVersion, event)
+	if err != nil {
+		return nil, err
+	}
+	recorded := stored[0]
+
+	if r.snapshotEvery > 0 && r.newProjection != nil && recorded.Version%r.snapshotEvery == 0 {
+		if err := TakeSnapshot(r.store, streamID, r.newProjection()); err != nil {
+			logrus.WithField("stream", streamID).Warn("failed to snapshot stream: ", err)
+		}
+	}
+
+	return &recorded, nil
+}
+
+// AuditEntry is a single event in an AuditTrail.
+type AuditEntry struct {
+	Version    int64           `json:"version"`
+	Event      string          `json:"event"`
+	Data       json.RawMessage `json:"data"`
+	RecordedAt time.Time       `json:"recordedAt"`
+}
+
+// AuditTrail is a projection that keeps every event of a stream, suitable for
+// rebuilding the lifecycle of a voucher or any other aggregate.
+type AuditTrail struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+func (a *AuditTrail) Apply(event StoredEvent) error {
+	a.Entries = append(a.Entries, AuditEntry{
+		Version:    event.Version,
+		Event:      event.Event,
+		Data:       event.Data,
+		RecordedAt: event.RecordedAt,
+	})
+	return nil
+}
+
+func (a *AuditTrail) ProjectionName() string {
+	return "audit-trail"
+}
+
+func (a *AuditTrail) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(a)
+}
+
+func (a *AuditTrail) Restore(state json.RawMessage) error {
+	return json.Unmarshal(state, a)
+}
+
+// StatusChange is a status transition recorded by TransactionHistory.
+type StatusChange struct {
+	From       interfacesx.TransactionStatus `json:"from"`
+	To         interfacesx.TransactionStatus `json:"to"`
+	Event      string                        `json:"event"`
+	Version    int64                         `json:"version"`
+	RecordedAt time.Time                     `json:"recordedAt"`
+	Valid      bool                          `json:"valid"`
+}
+
+// TransactionHistory is a projection that rebuilds the status lifecycle of a
+// transaction from events whose data carries a "status" field. Transitions
+// that the lifecycle does not allow are kept but flagged as invalid.
+type TransactionHistory struct {
+	Reference string                        `json:"reference"`
+	Status    interfacesx.TransactionStatus `json:"status"`
+	Changes   []StatusChange                `json:"changes"`
+}
+
+var transactionTransitions = map[interfacesx.TransactionStatus][]interfacesx.TransactionStatus{
+	interfacesx.Pending:    {interfacesx.Processing, interfacesx.Approved, interfacesx.Hold, interfacesx.Completed, interfacesx.Failed, interfacesx.Canceled},
+	interfacesx.Processing: {interfacesx.Approved, interfacesx.Hold, interfacesx.Completed, interfacesx.Failed, interfacesx.Canceled},
+	interfacesx.Approved:   {interfacesx.Processing, interfacesx.Completed, interfacesx.Failed},
+	interfacesx.Hold:       {interfacesx.Processing, interfacesx.Approved, interfacesx.Failed, interfacesx.Canceled},
+	interfacesx.Completed:  {interfacesx.Reversed, interfacesx.Refunded},
+}
+
+// IsValidTransition reports whether a transaction may move from one status to another.
+func IsValidTransition(from, to interfacesx.TransactionStatus) bool {
+	if from == "" {
+		return true
+	}
+	for _, allowed := range transactionTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *TransactionHistory) Apply(event StoredEvent) error {
+	var data struct {
+		Reference string                        `json:"reference"`
+		Status    interfacesx.TransactionStatus `json:"status"`
+	}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+
+	if h.Reference == "" {
+		h.Reference = data.Reference
+	}
+	if data.Status == "" || data.Status == h.Status {
+		return nil
+	}
+
+	h.Changes = append(h.Changes, StatusChange{
+		From:       h.Status,
+		To:         data.Status,
+		Event:      event.Event,
+		Version:    event.Version,
+		RecordedAt: event.RecordedAt,
+		Valid:      IsValidTransition(h.Status, data.Status),
+	})
+	h.Status = data.Status
+	return nil
+}
+
+func (h *TransactionHistory) ProjectionName() string {
+	return "transaction-history"
+}
+
+func (h *TransactionHistory) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(h)
+}
+
+func (h *TransactionHistory) Restore(state json.RawMessage) error {
+	return json.Unmarshal(state, h)
+}
+
+// InvalidChanges returns the transitions that broke the transaction lifecycle.
+func (h *TransactionHistory) InvalidChanges() []StatusChange {
+	var invalid []StatusChange
+	for _, change := range h.Changes {
+		if !change.Valid {
+			invalid = append(invalid, change)
+		}
+	}
+	return invalid
+}
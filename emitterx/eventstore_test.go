@@ -0,0 +1,156 @@
+package emitterx
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+
+	"github.com/stretchr/testify/require"
+)
+
+type transactionStatusChanged struct {
+	Reference string                        `json:"reference"`
+	Status    interfacesx.TransactionStatus `json:"status"`
+}
+
+func TestEventStoreAppendAndLoad(t *testing.T) {
+	store := NewMemoryEventStore()
+
+	stored, err := store.Append("tx-1", 0,
+		EventPayload{Event: "transaction.created", Data: transactionStatusChanged{Reference: "tx-1", Status: interfacesx.Pending}},
+		EventPayload{Event: "transaction.processing", Data: transactionStatusChanged{Status: interfacesx.Processing}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stored[0].Version)
+	require.Equal(t, int64(2), stored[1].Version)
+
+	_, err = store.Append("tx-1", 1, EventPayload{Event: "transaction.completed"})
+	require.True(t, errors.Is(err, ErrVersionConflict))
+
+	events, err := store.Load("tx-1", 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "transaction.processing", events[0].Event)
+}
+
+func TestReplayTransactionHistory(t *testing.T) {
+	store := NewMemoryEventStore()
+
+	recorder := NewEventRecorder(store, StreamByField("transaction-", "reference"))
+	recorder.SnapshotEvery(2, func() SnapshotProjection { return &TransactionHistory{} })
+
+	statuses := []interfacesx.TransactionStatus{interfacesx.Pending, interfacesx.Processing, interfacesx.Completed, interfacesx.Pending}
+	for _, status := range statuses {
+		_, err := recorder.Record(EventPayload{Event: "transaction.updated", Data: transactionStatusChanged{Reference: "ref-9", Status: status}})
+		require.NoError(t, err)
+	}
+
+	snapshot, err := store.LoadSnapshot("transaction-ref-9", "transaction-history")
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	require.Equal(t, int64(4), snapshot.Version)
+
+	var history TransactionHistory
+	version, err := Replay(store, "transaction-ref-9", &history)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), version)
+	require.Equal(t, interfacesx.Pending, history.Status)
+	require.Len(t, history.Changes, 4)
+
+	invalid := history.InvalidChanges()
+	require.Len(t, invalid, 1)
+	require.Equal(t, interfacesx.Completed, invalid[0].From)
+
+	var trail AuditTrail
+	_, err = Replay(store, "transaction-ref-9", &trail)
+	require.NoError(t, err)
+	require.Len(t, trail.Entries, 4)
+}
+
+func TestEventRecorderAttach(t *testing.T) {
+	store := NewMemoryEventStore()
+	emitter := NewEventEmitter()
+	NewEventRecorder(store, StreamByField("voucher-", "voucherId")).Attach(emitter, "voucher.created", "voucher.redeemed")
+
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "voucher.created", Data: map[string]string{"voucherId": "v-1"}}))
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "voucher.redeemed", Data: map[string]string{"voucherId": "v-1"}}))
+
+	var trail AuditTrail
+	version, err := Replay(store, "voucher-v-1", &trail)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), version)
+	require.Equal(t, "voucher.redeemed", trail.Entries[1].Event)
+}
+
+func TestEventRecorderAttachKeepsEmitOrder(t *testing.T) {
+	store := NewMemoryEventStore()
+	emitter := NewEventEmitter()
+	NewEventRecorder(store, StreamByField("transaction-", "reference")).Attach(emitter, "transaction.updated")
+
+	for i := 0; i < 50; i++ {
+		emitter.Emit(EventPayload{ID: fmt.Sprintf("evt-%d", i), Event: "transaction.updated", Data: map[string]string{"reference": "ref-1"}})
+	}
+
+	events, err := store.Load("transaction-ref-1", 0)
+	require.NoError(t, err)
+	require.Len(t, events, 50)
+	for i, event := range events {
+		require.Equal(t, fmt.Sprintf("evt-%d", i), event.ID)
+	}
+}
+
+func TestEventRecorderRejectsDuplicates(t *testing.T) {
+	store := NewMemoryEventStore()
+	recorder := NewEventRecorder(store, StreamByField("transaction-", "reference"))
+	data := map[string]string{"reference": "ref-2"}
+
+	_, err := recorder.Record(EventPayload{ID: "evt-1", IdempotencyKey: "paystack:trf-1", Event: "transaction.updated", Data: data})
+	require.NoError(t, err)
+	_, err = recorder.Record(EventPayload{ID: "evt-1", Event: "transaction.updated", Data: data})
+	require.True(t, errors.Is(err, ErrDuplicateEvent))
+	_, err = recorder.Record(EventPayload{IdempotencyKey: "paystack:trf-1", Event: "transaction.updated", Data: data})
+	require.True(t, errors.Is(err, ErrDuplicateEvent))
+
+	version, err := store.Version("transaction-ref-2")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), version)
+
+	emitter := NewEventEmitter()
+	recorder.Attach(emitter, "transaction.updated")
+	require.NoError(t, emitter.EmitAndWait(EventPayload{ID: "evt-1", Event: "transaction.updated", Data: data}))
+	version, err = store.Version("transaction-ref-2")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), version)
+}
+
+func TestFileEventStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	store, err := NewFileEventStore(path)
+	require.NoError(t, err)
+
+	recorder := NewEventRecorder(store, StreamByField("transaction-", "reference"))
+	recorder.SnapshotEvery(2, func() SnapshotProjection { return &TransactionHistory{} })
+	for i, status := range []interfacesx.TransactionStatus{interfacesx.Pending, interfacesx.Processing, interfacesx.Completed} {
+		_, err := recorder.Record(EventPayload{ID: fmt.Sprintf("evt-%d", i), Event: "transaction.updated", Data: transactionStatusChanged{Reference: "ref-3", Status: status}})
+		require.NoError(t, err)
+	}
+
+	reopened, err := NewFileEventStore(path)
+	require.NoError(t, err)
+	snapshot, err := reopened.LoadSnapshot("transaction-ref-3", "transaction-history")
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	require.Equal(t, int64(2), snapshot.Version)
+
+	var history TransactionHistory
+	version, err := Replay(reopened, "transaction-ref-3", &history)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), version)
+	require.Equal(t, interfacesx.Completed, history.Status)
+
+	_, err = reopened.Append("transaction-ref-3", AnyVersion, EventPayload{ID: "evt-0", Event: "transaction.updated"})
+	require.True(t, errors.Is(err, ErrDuplicateEvent))
+}
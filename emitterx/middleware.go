@@ -0,0 +1,454 @@
+package emitterx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Phase tells a middleware whether it is wrapping an Emit call or a single listener.
+type Phase int
+
+const (
+	EmitPhase Phase = iota
+	HandlerPhase
+)
+
+func (p Phase) String() string {
+	if p == HandlerPhase {
+		return "handler"
+	}
+	return "emit"
+}
+
+// MiddlewareFunc is a step in the emitter middleware chain. It calls Next to
+// continue the chain or Abort to stop it, in the same way as gin handlers.
+type MiddlewareFunc func(c *EventContext)
+
+// EventContext carries an event through the middleware chain.
+type EventContext struct {
+	Payload EventPayload
+
+	ctx     context.Context
+	phase   Phase
+	handler int
+	chain   []MiddlewareFunc
+	index   int
+	aborted bool
+	keys    map[string]interface{}
+	errors  []error
+	// took is how long the listener itself ran, in the handler phase.
+	took time.Duration
+}
+
+func newEventContext(ctx context.Context, payload EventPayload, phase Phase, handler int, chain []MiddlewareFunc) *EventContext {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &EventContext{
+		Payload: payload,
+		ctx:     ctx,
+		phase:   phase,
+		handler: handler,
+		chain:   chain,
+		index:   -1,
+	}
+}
+
+// chain returns a new slice holding middleware followed by final.
+func chain(middleware []MiddlewareFunc, final MiddlewareFunc) []MiddlewareFunc {
+	handlers := make([]MiddlewareFunc, 0, len(middleware)+1)
+	handlers = append(handlers, middleware...)
+	return append(handlers, final)
+}
+
+// Next runs the remaining middleware in the chain.
+func (c *EventContext) Next() {
+	c.index++
+	for c.index < len(c.chain) && !c.aborted {
+		c.chain[c.index](c)
+		c.index++
+	}
+}
+
+// Abort prevents the remaining middleware, and the listeners, from running.
+func (c *EventContext) Abort() {
+	c.aborted = true
+}
+
+// Error records err against the event. EmitAndWait returns the first error
+// recorded by any middleware.
+func (c *EventContext) Error(err error) {
+	c.errors = append(c.errors, err)
+}
+
+// Errors returns the errors recorded with Error.
+func (c *EventContext) Errors() []error {
+	return c.errors
+}
+
+// HandlerDuration returns how long the listener ran, not counting the
+// middleware around it. It is zero in the emit phase and until the listener
+// has returned.
+func (c *EventContext) HandlerDuration() time.Duration {
+	return c.took
+}
+
+// IsAborted reports whether the chain was aborted.
+func (c *EventContext) IsAborted() bool {
+	return c.aborted
+}
+
+// Phase reports whether the chain wraps an Emit or a listener call.
+func (c *EventContext) Phase() Phase {
+	return c.phase
+}
+
+// HandlerIndex returns the position of the listener being called, or -1 in the emit phase.
+func (c *EventContext) HandlerIndex() int {
+	return c.handler
+}
+
+// Context returns the context the event was emitted with.
+func (c *EventContext) Context() context.Context {
+	return c.ctx
+}
+
+// SetContext replaces the context passed down the chain.
+func (c *EventContext) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Set stores a value for later middleware in the same chain.
+func (c *EventContext) Set(key string, value interface{}) {
+	if c.keys == nil {
+		c.keys = make(map[string]interface{})
+	}
+	c.keys[key] = value
+}
+
+// Get returns a value stored with Set.
+func (c *EventContext) Get(key string) (interface{}, bool) {
+	value, ok := c.keys[key]
+	return value, ok
+}
+
+// SetMetadata sets a metadata entry on the payload without mutating maps shared with other listeners.
+func (c *EventContext) SetMetadata(key, value string) {
+	metadata := make(map[string]string, len(c.Payload.Metadata)+1)
+	for k, v := range c.Payload.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	c.Payload.Metadata = metadata
+}
+
+// Logger logs every emitted event, and every listener call with the time the
+// listener took. Emit returns before listeners run, so emits are logged
+// without a duration.
+func Logger(logger logrus.FieldLogger) MiddlewareFunc {
+	return func(c *EventContext) {
+		c.Next()
+
+		fields := logrus.Fields{
+			"event": c.Payload.Event,
+			"phase": c.Phase().String(),
+		}
+		if c.Payload.ID != "" {
+			fields["id"] = c.Payload.ID
+		}
+		if traceID := c.Payload.Metadata[TraceIDKey]; traceID != "" {
+			fields["traceId"] = traceID
+		}
+		if c.Phase() == HandlerPhase {
+			fields["handler"] = c.HandlerIndex()
+			fields["duration"] = c.HandlerDuration().String()
+		}
+
+		if c.IsAborted() {
+			fields["aborted"] = true
+		}
+		logger.WithFields(fields).Info("event handled")
+	}
+}
+
+// Recovery recovers panics raised further down the chain and logs them, so a
+// failing listener does not crash the process. The panic is recorded as an
+// error, so EmitAndWait still reports the failure.
+func Recovery(logger logrus.FieldLogger) MiddlewareFunc {
+	return func(c *EventContext) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.WithField("event", c.Payload.Event).Error(fmt.Sprintf("event listener panicked: %v", r))
+				c.Error(fmt.Errorf("handler for %s panicked: %v", c.Payload.Event, r))
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
+
+// EventStats holds the counters collected for a single event name.
+type EventStats struct {
+	Emitted       int64         `json:"emitted"`
+	HandlerCalls  int64         `json:"handlerCalls"`
+	Aborted       int64         `json:"aborted"`
+	TotalDuration time.Duration `json:"totalDuration"`
+	MaxDuration   time.Duration `json:"maxDuration"`
+}
+
+// AverageDuration returns the mean listener duration.
+func (s EventStats) AverageDuration() time.Duration {
+	if s.HandlerCalls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.HandlerCalls)
+}
+
+// EventMetrics collects emit counts and listener durations per event name.
+type EventMetrics struct {
+	stats map[string]*EventStats
+	mu    sync.Mutex
+}
+
+// NewEventMetrics creates an empty EventMetrics.
+func NewEventMetrics() *EventMetrics {
+	return &EventMetrics{stats: make(map[string]*EventStats)}
+}
+
+// Middleware returns the middleware that records into m. Register it with
+// both Use and UseHandler to collect emit counts and listener durations.
+func (m *EventMetrics) Middleware() MiddlewareFunc {
+	return func(c *EventContext) {
+		c.Next()
+		elapsed := c.HandlerDuration()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		stats, ok := m.stats[c.Payload.Event]
+		if !ok {
+			stats = &EventStats{}
+			m.stats[c.Payload.Event] = stats
+		}
+
+		if c.IsAborted() {
+			stats.Aborted++
+			return
+		}
+		if c.Phase() == EmitPhase {
+			stats.Emitted++
+			return
+		}
+		stats.HandlerCalls++
+		stats.TotalDuration += elapsed
+		if elapsed > stats.MaxDuration {
+			stats.MaxDuration = elapsed
+		}
+	}
+}
+
+// Stats returns the counters for an event name.
+func (m *EventMetrics) Stats(eventName string) EventStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stats, ok := m.stats[eventName]; ok {
+		return *stats
+	}
+	return EventStats{}
+}
+
+// Events returns the names of all events seen so far, sorted.
+func (m *EventMetrics) Events() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.stats))
+	for name := range m.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TraceIDKey is the metadata key that carries the trace ID of an event.
+const TraceIDKey = "traceId"
+
+type traceIDContextKey struct{}
+
+// ContextWithTraceID returns a copy of ctx carrying traceID.
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID stored in ctx, if any.
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDContextKey{}).(string)
+	return traceID
+}
+
+// Tracing propagates a trace ID between the emit context, the event metadata
+// and listener contexts. A new ID is generated when neither carries one.
+func Tracing() MiddlewareFunc {
+	return func(c *EventContext) {
+		traceID := c.Payload.Metadata[TraceIDKey]
+		if traceID == "" {
+			traceID = TraceIDFromContext(c.Context())
+		}
+		if traceID == "" {
+			id, err := uuid.NewV4()
+			if err == nil {
+				traceID = id.String()
+			}
+		}
+
+		if c.Payload.Metadata[TraceIDKey] != traceID {
+			c.SetMetadata(TraceIDKey, traceID)
+		}
+		if TraceIDFromContext(c.Context()) != traceID {
+			c.SetContext(ContextWithTraceID(c.Context(), traceID))
+		}
+		c.Next()
+	}
+}
+
+// Predicate decides whether an event should be delivered.
+type Predicate func(EventPayload) bool
+
+// Filter aborts the chain for events that do not match predicate. Used with
+// Use it drops events for every listener; use Where to filter a single listener.
+func Filter(predicate Predicate) MiddlewareFunc {
+	return func(c *EventContext) {
+		if !predicate(c.Payload) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Where wraps handler so it only receives events that match predicate.
+func Where(predicate Predicate, handler EventHandler) EventHandler {
+	return func(event EventPayload) {
+		if predicate(event) {
+			handler(event)
+		}
+	}
+}
+
+// OnWhere adds a listener that only receives events matching predicate.
+func (e *EventEmitter) OnWhere(eventName string, predicate Predicate, handler EventHandler) {
+	e.On(eventName, Where(predicate, handler))
+}
+
+// And matches when all predicates match.
+func And(predicates ...Predicate) Predicate {
+	return func(event EventPayload) bool {
+		for _, predicate := range predicates {
+			if !predicate(event) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches when any predicate matches.
+func Or(predicates ...Predicate) Predicate {
+	return func(event EventPayload) bool {
+		for _, predicate := range predicates {
+			if predicate(event) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not inverts a predicate.
+func Not(predicate Predicate) Predicate {
+	return func(event EventPayload) bool {
+		return !predicate(event)
+	}
+}
+
+// FromSource matches events received from the given Transport source.
+func FromSource(source string) Predicate {
+	return func(event EventPayload) bool {
+		return event.Source == source
+	}
+}
+
+// FieldEquals matches events whose data has a top-level JSON field equal to
+// value. Numbers are compared by value, so 1500.5 matches a moneyx.Money
+// written as 1500.50.
+func FieldEquals(field string, value interface{}) Predicate {
+	return func(event EventPayload) bool {
+		fields, ok := dataFields(event)
+		if !ok {
+			return false
+		}
+		raw, ok := fields[field]
+		if !ok {
+			return false
+		}
+		expected, err := json.Marshal(value)
+		if err != nil {
+			return false
+		}
+		if string(raw) == string(expected) {
+			return true
+		}
+		got, ok := new(big.Rat).SetString(string(raw))
+		if !ok {
+			return false
+		}
+		want, ok := new(big.Rat).SetString(string(expected))
+		return ok && got.Cmp(want) == 0
+	}
+}
+
+// AmountAbove matches events whose data has a numeric "amount" field greater than threshold.
+func AmountAbove(threshold float64) Predicate {
+	return func(event EventPayload) bool {
+		amount, ok := dataNumber(event, "amount")
+		return ok && amount > threshold
+	}
+}
+
+// AmountBelow matches events whose data has a numeric "amount" field lower than threshold.
+func AmountBelow(threshold float64) Predicate {
+	return func(event EventPayload) bool {
+		amount, ok := dataNumber(event, "amount")
+		return ok && amount < threshold
+	}
+}
+
+func dataFields(event EventPayload) (map[string]json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := DecodeData(event, &fields); err != nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+func dataNumber(event EventPayload, field string) (float64, bool) {
+	fields, ok := dataFields(event)
+	if !ok {
+		return 0, false
+	}
+	raw, ok := fields[field]
+	if !ok {
+		return 0, false
+	}
+	var number float64
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,166 @@
+package emitterx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type transactionCreated struct {
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	emitter := NewEventEmitter()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, step)
+	}
+
+	emitter.Use(func(c *EventContext) {
+		record("emit")
+		c.Next()
+	})
+	emitter.UseHandler(func(c *EventContext) {
+		record("handler:before")
+		c.Next()
+		record("handler:after")
+	})
+	emitter.On("transaction.created", func(EventPayload) { record("listener") })
+
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "transaction.created"}))
+	require.Equal(t, []string{"emit", "handler:before", "listener", "handler:after"}, order)
+}
+
+func TestFilterMiddlewareAbortsEmit(t *testing.T) {
+	emitter := NewEventEmitter()
+	metrics := NewEventMetrics()
+	emitter.Use(metrics.Middleware(), Filter(AmountAbove(1000)))
+	emitter.UseHandler(metrics.Middleware())
+
+	var calls int32
+	emitter.On("transaction.created", func(EventPayload) { atomic.AddInt32(&calls, 1) })
+
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "transaction.created", Data: transactionCreated{Amount: 500}}))
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "transaction.created", Data: transactionCreated{Amount: 5000}}))
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	stats := metrics.Stats("transaction.created")
+	require.Equal(t, int64(1), stats.Emitted)
+	require.Equal(t, int64(1), stats.Aborted)
+	require.Equal(t, int64(1), stats.HandlerCalls)
+}
+
+func TestOnWhere(t *testing.T) {
+	emitter := NewEventEmitter()
+
+	var large, all int32
+	emitter.OnWhere("transaction.created", AmountAbove(10000), func(EventPayload) { atomic.AddInt32(&large, 1) })
+	emitter.On("transaction.created", func(EventPayload) { atomic.AddInt32(&all, 1) })
+
+	for _, amount := range []float64{50, 20000, 15000.5} {
+		require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "transaction.created", Data: transactionCreated{Amount: amount}}))
+	}
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "transaction.created", Data: map[string]interface{}{"amount": 99999, "reference": "ref-1"}}))
+
+	require.Equal(t, int32(3), atomic.LoadInt32(&large))
+	require.Equal(t, int32(4), atomic.LoadInt32(&all))
+
+	require.True(t, And(AmountAbove(10), FieldEquals("reference", "ref-1"))(EventPayload{Data: transactionCreated{Reference: "ref-1", Amount: 20}}))
+	require.False(t, Not(FieldEquals("reference", "ref-1"))(EventPayload{Data: transactionCreated{Reference: "ref-1"}}))
+
+	// Money data is written as a bare number, so the predicates still match it.
+	money := EventPayload{Data: interfacesx.CreateTransactionRequest{ReferenceID: "ref-1", Amount: moneyx.New(150050, "NGN")}}
+	require.True(t, AmountAbove(1500)(money))
+	require.True(t, AmountBelow(1501)(money))
+	require.True(t, FieldEquals("amount", 1500.5)(money))
+	require.True(t, FieldEquals("amount", moneyx.New(150050, "NGN"))(money))
+	require.False(t, FieldEquals("amount", 1500)(money))
+}
+
+func TestTracingPropagatesToListeners(t *testing.T) {
+	emitter := NewEventEmitter()
+	emitter.Use(Tracing())
+
+	traces := make(chan [2]string, 1)
+	emitter.OnContext("voucher.redeemed", func(ctx context.Context, event EventPayload) {
+		traces <- [2]string{TraceIDFromContext(ctx), event.Metadata[TraceIDKey]}
+	})
+
+	ctx := ContextWithTraceID(context.Background(), "trace-123")
+	emitter.EmitContext(ctx, EventPayload{Event: "voucher.redeemed"})
+
+	select {
+	case trace := <-traces:
+		require.Equal(t, [2]string{"trace-123", "trace-123"}, trace)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestLoggerAndRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+
+	emitter := NewEventEmitter()
+	emitter.UseHandler(Logger(logger), Recovery(logger))
+	emitter.On("balance.updated", func(EventPayload) { panic("boom") })
+
+	require.EqualError(t, emitter.EmitAndWait(EventPayload{Event: "balance.updated"}), "handler for balance.updated panicked: boom")
+	require.Contains(t, buf.String(), "event listener panicked: boom")
+	require.Contains(t, buf.String(), "aborted=true")
+}
+
+func TestOutboxRetriesRecoveredPanic(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	emitter := NewEventEmitter()
+	emitter.UseHandler(Recovery(logger))
+	emitter.On("payout.requested", func(EventPayload) { panic("gateway down") })
+
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(store, emitter)
+	require.Error(t, outbox.Publish(EventPayload{ID: "payout-1", Event: "payout.requested"}))
+
+	record, err := store.Get("payout-1")
+	require.NoError(t, err)
+	require.Equal(t, OutboxPending, record.Status)
+	require.Contains(t, record.LastError, "gateway down")
+}
+
+func TestMetricsTimeTheListener(t *testing.T) {
+	metrics := NewEventMetrics()
+	emitter := NewEventEmitter()
+	emitter.Use(metrics.Middleware())
+	emitter.UseHandler(metrics.Middleware(), func(c *EventContext) {
+		// Slow middleware around the listener is not counted.
+		time.Sleep(30 * time.Millisecond)
+		c.Next()
+	})
+	emitter.On("balance.updated", func(EventPayload) { time.Sleep(10 * time.Millisecond) })
+
+	require.NoError(t, emitter.EmitAndWait(EventPayload{Event: "balance.updated"}))
+	stats := metrics.Stats("balance.updated")
+	require.Equal(t, int64(1), stats.Emitted)
+	require.Equal(t, int64(1), stats.HandlerCalls)
+	require.GreaterOrEqual(t, stats.MaxDuration, 10*time.Millisecond)
+	require.Less(t, stats.MaxDuration, 30*time.Millisecond)
+}
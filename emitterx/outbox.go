@@ -0,0 +1,414 @@
+package emitterx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDuplicateEvent is returned by an OutboxStore or EventStore when an event
+// with the same idempotency key has already been recorded.
+var ErrDuplicateEvent = errors.New("event with this idempotency key already exists")
+
+// ErrOutboxRecordNotFound is returned when an outbox record does not exist.
+var ErrOutboxRecordNotFound = errors.New("outbox record not found")
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "PENDING"
+	OutboxDone    OutboxStatus = "DONE"
+	OutboxFailed  OutboxStatus = "FAILED"
+)
+
+// OutboxRecord is an event persisted in the outbox together with its delivery state.
+type OutboxRecord struct {
+	ID        string       `json:"id"`
+	Payload   EventPayload `json:"payload"`
+	Status    OutboxStatus `json:"status"`
+	Attempts  int          `json:"attempts"`
+	LastError string       `json:"lastError,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// OutboxStore persists outbox records. Implementations must be safe for concurrent use.
+type OutboxStore interface {
+	// Save stores a new pending record. It returns ErrDuplicateEvent if a record
+	// with the same idempotency key already exists.
+	Save(record OutboxRecord) error
+	// Pending returns up to limit pending records, oldest first. A limit of zero returns all.
+	Pending(limit int) ([]OutboxRecord, error)
+	// MarkDone marks a record as delivered.
+	MarkDone(id string) error
+	// MarkFailed records a failed delivery attempt. When retry is false the
+	// record is moved to OutboxFailed and is no longer returned by Pending.
+	MarkFailed(id string, reason string, retry bool) error
+}
+
+// Outbox writes events to an OutboxStore before dispatching them through an
+// EventEmitter, so events survive a crash between commit and delivery.
+// Delivery is at-least-once: handlers should use EventPayload.IdempotencyKey
+// to discard repeats.
+type Outbox struct {
+	store       OutboxStore
+	emitter     *EventEmitter
+	maxAttempts int
+	mu          sync.Mutex
+}
+
+// NewOutbox creates an Outbox that stores events in store and dispatches them through emitter.
+func NewOutbox(store OutboxStore, emitter *EventEmitter) *Outbox {
+	return &Outbox{
+		store:       store,
+		emitter:     emitter,
+		maxAttempts: 5,
+	}
+}
+
+// SetMaxAttempts sets how many times a record is dispatched before it is marked as failed.
+func (o *Outbox) SetMaxAttempts(attempts int) {
+	if attempts > 0 {
+		o.maxAttempts = attempts
+	}
+}
+
+// Record stores an event without dispatching it. The event ID and idempotency
+// key are generated when empty. Recording an event whose idempotency key is
+// already stored is a no-op.
+func (o *Outbox) Record(event EventPayload) (*OutboxRecord, error) {
+	if event.ID == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		event.ID = id.String()
+	}
+	if event.IdempotencyKey == "" {
+		event.IdempotencyKey = event.ID
+	}
+
+	now := time.Now().UTC()
+	record := OutboxRecord{
+		ID:        event.ID,
+		Payload:   event,
+		Status:    OutboxPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := o.store.Save(record); err != nil {
+		if errors.Is(err, ErrDuplicateEvent) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Publish stores an event and immediately dispatches it. If dispatching fails
+// the record stays pending and is retried by Dispatch or Run.
+func (o *Outbox) Publish(event EventPayload) error {
+	record, err := o.Record(event)
+	if err != nil || record == nil {
+		return err
+	}
+
+	return o.deliver(*record)
+}
+
+// Dispatch delivers every pending record and returns the number delivered successfully.
+func (o *Outbox) Dispatch() (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	records, err := o.store.Pending(0)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, record := range records {
+		if err := o.deliver(record); err != nil {
+			logrus.WithField("event", record.Payload.Event).WithField("id", record.ID).Warn(err)
+			continue
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// Run dispatches pending records every interval until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.Dispatch(); err != nil {
+			logrus.Error("outbox dispatch failed: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) deliver(record OutboxRecord) error {
+	if err := o.emitter.EmitAndWait(record.Payload); err != nil {
+		retry := record.Attempts+1 < o.maxAttempts
+		if markErr := o.store.MarkFailed(record.ID, err.Error(), retry); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	return o.store.MarkDone(record.ID)
+}
+
+// NewIdempotentHandler wraps handler so that events with an idempotency key
+// already seen by this handler are dropped.
+func NewIdempotentHandler(handler EventHandler) EventHandler {
+	var seen sync.Map
+	return func(event EventPayload) {
+		if event.IdempotencyKey != "" {
+			if _, loaded := seen.LoadOrStore(event.IdempotencyKey, struct{}{}); loaded {
+				return
+			}
+		}
+		handler(event)
+	}
+}
+
+// MemoryOutboxStore is an in-memory OutboxStore.
+type MemoryOutboxStore struct {
+	records map[string]*OutboxRecord
+	keys    map[string]string
+	mu      sync.Mutex
+}
+
+// NewMemoryOutboxStore creates an empty MemoryOutboxStore.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{
+		records: make(map[string]*OutboxRecord),
+		keys:    make(map[string]string),
+	}
+}
+
+func (s *MemoryOutboxStore) Save(record OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return saveRecord(s.records, s.keys, record)
+}
+
+func (s *MemoryOutboxStore) Pending(limit int) ([]OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return pendingRecords(s.records, limit), nil
+}
+
+func (s *MemoryOutboxStore) MarkDone(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return markDone(s.records, id)
+}
+
+func (s *MemoryOutboxStore) MarkFailed(id string, reason string, retry bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return markFailed(s.records, id, reason, retry)
+}
+
+// Get returns a copy of the record with the given ID.
+func (s *MemoryOutboxStore) Get(id string) (*OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, ErrOutboxRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// FileOutboxStore is an OutboxStore that keeps its records in a JSON file.
+// It is intended for tests and single-process tools.
+type FileOutboxStore struct {
+	path    string
+	records map[string]*OutboxRecord
+	keys    map[string]string
+	mu      sync.Mutex
+}
+
+// NewFileOutboxStore opens the store at path, loading existing records if the file exists.
+func NewFileOutboxStore(path string) (*FileOutboxStore, error) {
+	s := &FileOutboxStore{
+		path:    path,
+		records: make(map[string]*OutboxRecord),
+		keys:    make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	var records []OutboxRecord
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to read outbox file %s: %v", path, err)
+		}
+	}
+	for i := range records {
+		record := records[i]
+		s.records[record.ID] = &record
+		s.keys[record.Payload.IdempotencyKey] = record.ID
+	}
+
+	return s, nil
+}
+
+func (s *FileOutboxStore) Save(record OutboxRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := saveRecord(s.records, s.keys, record); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *FileOutboxStore) Pending(limit int) ([]OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return pendingRecords(s.records, limit), nil
+}
+
+func (s *FileOutboxStore) MarkDone(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := markDone(s.records, id); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *FileOutboxStore) MarkFailed(id string, reason string, retry bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := markFailed(s.records, id, reason, retry); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// flush writes all records to the store file.
+func (s *FileOutboxStore) flush() error {
+	records := make([]OutboxRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, *record)
+	}
+	sortRecords(records)
+	return writeJSONFile(s.path, records)
+}
+
+// writeJSONFile writes v to a temporary file and renames it over path, so a
+// crash never leaves a half-written file behind.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func saveRecord(records map[string]*OutboxRecord, keys map[string]string, record OutboxRecord) error {
+	if _, exists := keys[record.Payload.IdempotencyKey]; exists {
+		return ErrDuplicateEvent
+	}
+	if _, exists := records[record.ID]; exists {
+		return ErrDuplicateEvent
+	}
+	records[record.ID] = &record
+	keys[record.Payload.IdempotencyKey] = record.ID
+	return nil
+}
+
+func pendingRecords(records map[string]*OutboxRecord, limit int) []OutboxRecord {
+	var pending []OutboxRecord
+	for _, record := range records {
+		if record.Status == OutboxPending {
+			pending = append(pending, *record)
+		}
+	}
+	sortRecords(pending)
+
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending
+}
+
+func markDone(records map[string]*OutboxRecord, id string) error {
+	record, ok := records[id]
+	if !ok {
+		return ErrOutboxRecordNotFound
+	}
+	record.Status = OutboxDone
+	record.Attempts++
+	record.LastError = ""
+	record.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func markFailed(records map[string]*OutboxRecord, id string, reason string, retry bool) error {
+	record, ok := records[id]
+	if !ok {
+		return ErrOutboxRecordNotFound
+	}
+	record.Attempts++
+	record.LastError = reason
+	record.UpdatedAt = time.Now().UTC()
+	if !retry {
+		record.Status = OutboxFailed
+	}
+	return nil
+}
+
+func sortRecords(records []OutboxRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].ID < records[j].ID
+		}
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+}
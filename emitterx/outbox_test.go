@@ -0,0 +1,115 @@
+package emitterx
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxPublishMarksDone(t *testing.T) {
+	store := NewMemoryOutboxStore()
+	emitter := NewEventEmitter()
+
+	var calls int32
+	emitter.On("balance.updated", func(event EventPayload) {
+		atomic.AddInt32(&calls, 1)
+		require.NotEmpty(t, event.IdempotencyKey)
+	})
+
+	outbox := NewOutbox(store, emitter)
+	require.NoError(t, outbox.Publish(EventPayload{Event: "balance.updated", Data: 100}))
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	pending, err := store.Pending(0)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestOutboxDuplicateIdempotencyKey(t *testing.T) {
+	store := NewMemoryOutboxStore()
+	emitter := NewEventEmitter()
+
+	var calls int32
+	emitter.On("voucher.created", func(EventPayload) { atomic.AddInt32(&calls, 1) })
+
+	outbox := NewOutbox(store, emitter)
+	event := EventPayload{Event: "voucher.created", IdempotencyKey: "voucher-1"}
+	require.NoError(t, outbox.Publish(event))
+	require.NoError(t, outbox.Publish(event))
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOutboxRetriesFailedDelivery(t *testing.T) {
+	store := NewMemoryOutboxStore()
+	emitter := NewEventEmitter()
+
+	var calls int32
+	emitter.On("transaction.created", func(EventPayload) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			panic("database unavailable")
+		}
+	})
+
+	outbox := NewOutbox(store, emitter)
+	require.Error(t, outbox.Publish(EventPayload{Event: "transaction.created"}))
+
+	pending, err := store.Pending(0)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].Attempts)
+
+	delivered, err := outbox.Dispatch()
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+
+	record, err := store.Get(pending[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, OutboxDone, record.Status)
+}
+
+func TestOutboxMarksFailedAfterMaxAttempts(t *testing.T) {
+	store := NewMemoryOutboxStore()
+	emitter := NewEventEmitter()
+	emitter.On("transaction.created", func(EventPayload) { panic("always fails") })
+
+	outbox := NewOutbox(store, emitter)
+	outbox.SetMaxAttempts(2)
+	require.Error(t, outbox.Publish(EventPayload{ID: "tx-1", Event: "transaction.created"}))
+
+	_, err := outbox.Dispatch()
+	require.NoError(t, err)
+
+	record, err := store.Get("tx-1")
+	require.NoError(t, err)
+	require.Equal(t, OutboxFailed, record.Status)
+	require.Equal(t, 2, record.Attempts)
+}
+
+func TestFileOutboxStoreSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+
+	store, err := NewFileOutboxStore(path)
+	require.NoError(t, err)
+
+	// Record without dispatching, as if the process died right after the commit.
+	_, err = NewOutbox(store, NewEventEmitter()).Record(EventPayload{Event: "balance.updated", Data: "user-1"})
+	require.NoError(t, err)
+
+	reopened, err := NewFileOutboxStore(path)
+	require.NoError(t, err)
+
+	emitter := NewEventEmitter()
+	var received EventPayload
+	emitter.On("balance.updated", func(event EventPayload) { received = event })
+
+	delivered, err := NewOutbox(reopened, emitter).Dispatch()
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, "user-1", received.Data)
+
+	pending, err := reopened.Pending(0)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
@@ -0,0 +1,471 @@
+package emitterx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrScheduleNotFound is returned when a scheduled event does not exist.
+var ErrScheduleNotFound = errors.New("scheduled event not found")
+
+// Clock abstracts time so schedules can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer is a timer created by a Clock.
+type ClockTimer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+// SystemClock returns a Clock backed by the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock is a Clock that only moves when Advance is called.
+type FakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	mu     sync.Mutex
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	f     func()
+}
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward by d, running due timers in order on the calling goroutine.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].when.Before(c.timers[j].when) })
+		if len(c.timers) == 0 || c.timers[0].when.After(target) {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.when.After(c.now) {
+			c.now = timer.when
+		}
+		c.mu.Unlock()
+
+		timer.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduledEvent is an event waiting to be emitted. Recurring events carry a cron spec.
+type ScheduledEvent struct {
+	ID        string       `json:"id"`
+	Payload   EventPayload `json:"payload"`
+	RunAt     time.Time    `json:"runAt"`
+	Cron      string       `json:"cron,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// ScheduleStore persists scheduled events so they survive restarts.
+type ScheduleStore interface {
+	Save(event ScheduledEvent) error
+	Delete(id string) error
+	List() ([]ScheduledEvent, error)
+}
+
+// MemoryScheduleStore is an in-memory ScheduleStore.
+type MemoryScheduleStore struct {
+	events map[string]ScheduledEvent
+	mu     sync.Mutex
+}
+
+// NewMemoryScheduleStore creates an empty MemoryScheduleStore.
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{events: make(map[string]ScheduledEvent)}
+}
+
+func (s *MemoryScheduleStore) Save(event ScheduledEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[event.ID] = event
+	return nil
+}
+
+func (s *MemoryScheduleStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.events[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	delete(s.events, id)
+	return nil
+}
+
+func (s *MemoryScheduleStore) List() ([]ScheduledEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedSchedules(s.events), nil
+}
+
+// FileScheduleStore is a ScheduleStore that keeps its events in a JSON file.
+type FileScheduleStore struct {
+	path   string
+	events map[string]ScheduledEvent
+	mu     sync.Mutex
+}
+
+// NewFileScheduleStore opens the store at path, loading existing events if the file exists.
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	s := &FileScheduleStore{path: path, events: make(map[string]ScheduledEvent)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+
+	var events []ScheduledEvent
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, fmt.Errorf("failed to read schedule file %s: %v", path, err)
+		}
+	}
+	for _, event := range events {
+		s.events[event.ID] = event
+	}
+
+	return s, nil
+}
+
+func (s *FileScheduleStore) Save(event ScheduledEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[event.ID] = event
+	return writeJSONFile(s.path, sortedSchedules(s.events))
+}
+
+func (s *FileScheduleStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.events[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	delete(s.events, id)
+	return writeJSONFile(s.path, sortedSchedules(s.events))
+}
+
+func (s *FileScheduleStore) List() ([]ScheduledEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedSchedules(s.events), nil
+}
+
+func sortedSchedules(events map[string]ScheduledEvent) []ScheduledEvent {
+	list := make([]ScheduledEvent, 0, len(events))
+	for _, event := range events {
+		list = append(list, event)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].RunAt.Equal(list[j].RunAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].RunAt.Before(list[j].RunAt)
+	})
+	return list
+}
+
+// ScheduleHandle identifies a scheduled event and can cancel it.
+type ScheduleHandle struct {
+	ID        string
+	RunAt     time.Time
+	scheduler *Scheduler
+}
+
+// Cancel stops the scheduled event from firing.
+func (h *ScheduleHandle) Cancel() error {
+	return h.scheduler.Cancel(h.ID)
+}
+
+// Scheduler emits events at a given time or on a cron schedule.
+type Scheduler struct {
+	emitter *EventEmitter
+	store   ScheduleStore
+	clock   Clock
+	timers  map[string]ClockTimer
+	firing  map[string]bool
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a Scheduler that emits through emitter. Call Restore to
+// re-arm events saved in store by a previous process.
+func NewScheduler(emitter *EventEmitter, store ScheduleStore, clock Clock) *Scheduler {
+	return &Scheduler{
+		emitter: emitter,
+		store:   store,
+		clock:   clock,
+		timers:  make(map[string]ClockTimer),
+		firing:  make(map[string]bool),
+	}
+}
+
+// EmitAt emits event at the given time.
+func (s *Scheduler) EmitAt(event EventPayload, at time.Time) (*ScheduleHandle, error) {
+	return s.schedule(event, at, "")
+}
+
+// EmitAfter emits event once d has elapsed.
+func (s *Scheduler) EmitAfter(event EventPayload, d time.Duration) (*ScheduleHandle, error) {
+	return s.schedule(event, s.clock.Now().Add(d), "")
+}
+
+// EmitCron emits event every time the cron spec matches. See ParseCron for the supported syntax.
+func (s *Scheduler) EmitCron(spec string, event EventPayload) (*ScheduleHandle, error) {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	next := cron.Next(s.clock.Now())
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron spec %q never fires", spec)
+	}
+	return s.schedule(event, next, spec)
+}
+
+// Cancel removes a scheduled event.
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	if timer, ok := s.timers[id]; ok {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+	if _, ok := s.firing[id]; ok {
+		// Stop a recurring event that is firing right now from being re-armed.
+		s.firing[id] = true
+	}
+	s.mu.Unlock()
+
+	return s.store.Delete(id)
+}
+
+// Restore arms every event in the store. Events whose time has passed fire immediately.
+func (s *Scheduler) Restore() (int, error) {
+	events, err := s.store.List()
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		s.arm(event)
+	}
+	return len(events), nil
+}
+
+// Stop disarms all timers without removing the events from the store.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+func (s *Scheduler) schedule(event EventPayload, at time.Time, cron string) (*ScheduleHandle, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := ScheduledEvent{
+		ID:        id.String(),
+		Payload:   event,
+		RunAt:     at,
+		Cron:      cron,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.store.Save(scheduled); err != nil {
+		return nil, err
+	}
+	s.arm(scheduled)
+
+	return &ScheduleHandle{ID: scheduled.ID, RunAt: at, scheduler: s}, nil
+}
+
+func (s *Scheduler) arm(event ScheduledEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.armLocked(event)
+}
+
+// armLocked must be called with s.mu held.
+func (s *Scheduler) armLocked(event ScheduledEvent) {
+	delay := event.RunAt.Sub(s.clock.Now())
+	if delay < 0 {
+		delay = 0
+	}
+	if timer, ok := s.timers[event.ID]; ok {
+		timer.Stop()
+	}
+	s.timers[event.ID] = s.clock.AfterFunc(delay, func() { s.fire(event) })
+}
+
+func (s *Scheduler) fire(event ScheduledEvent) {
+	s.mu.Lock()
+	if _, ok := s.timers[event.ID]; !ok {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.timers, event.ID)
+	s.firing[event.ID] = false
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.firing, event.ID)
+		s.mu.Unlock()
+	}()
+
+	payload := event.Payload
+	if payload.IdempotencyKey == "" {
+		payload.IdempotencyKey = fmt.Sprintf("%s:%d", event.ID, event.RunAt.Unix())
+	}
+	if err := s.emitter.EmitAndWait(payload); err != nil {
+		logrus.WithField("event", payload.Event).WithField("schedule", event.ID).Error(err)
+	}
+
+	if event.Cron == "" {
+		if err := s.store.Delete(event.ID); err != nil && !errors.Is(err, ErrScheduleNotFound) {
+			logrus.WithField("schedule", event.ID).Error("failed to delete fired schedule: ", err)
+		}
+		return
+	}
+
+	cron, err := ParseCron(event.Cron)
+	if err != nil {
+		logrus.WithField("schedule", event.ID).Error(err)
+		return
+	}
+	// Runs missed while the scheduler was down or the handlers were slow are
+	// skipped rather than fired back to back.
+	from := s.clock.Now()
+	if event.RunAt.After(from) {
+		from = event.RunAt
+	}
+	event.RunAt = cron.Next(from)
+
+	// The lock is held until the next run is armed so a Cancel that lands
+	// while the handlers ran cannot be undone by saving the schedule again.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.firing[event.ID] {
+		return
+	}
+	if event.RunAt.IsZero() {
+		s.store.Delete(event.ID)
+		return
+	}
+	if err := s.store.Save(event); err != nil {
+		logrus.WithField("schedule", event.ID).Error("failed to save recurring schedule: ", err)
+		return
+	}
+	s.armLocked(event)
+}
+
+// SetScheduler attaches a scheduler to the emitter and re-arms the events in
+// store. EmitAt, EmitAfter and EmitCron use it; without it they fall back to an
+// in-memory store and the system clock.
+func (e *EventEmitter) SetScheduler(store ScheduleStore, clock Clock) (*Scheduler, error) {
+	scheduler := NewScheduler(e, store, clock)
+	if _, err := scheduler.Restore(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	previous := e.scheduler
+	e.scheduler = scheduler
+	e.mu.Unlock()
+
+	if previous != nil {
+		previous.Stop()
+	}
+	return scheduler, nil
+}
+
+// EmitAt emits event at the given time.
+func (e *EventEmitter) EmitAt(event EventPayload, at time.Time) (*ScheduleHandle, error) {
+	return e.getScheduler().EmitAt(event, at)
+}
+
+// EmitAfter emits event once d has elapsed.
+func (e *EventEmitter) EmitAfter(event EventPayload, d time.Duration) (*ScheduleHandle, error) {
+	return e.getScheduler().EmitAfter(event, d)
+}
+
+// EmitCron emits event every time the cron spec matches.
+func (e *EventEmitter) EmitCron(spec string, event EventPayload) (*ScheduleHandle, error) {
+	return e.getScheduler().EmitCron(spec, event)
+}
+
+func (e *EventEmitter) getScheduler() *Scheduler {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.scheduler == nil {
+		e.scheduler = NewScheduler(e, NewMemoryScheduleStore(), SystemClock())
+	}
+	return e.scheduler
+}
@@ -0,0 +1,178 @@
+package emitterx
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var schedulerEpoch = time.Date(2024, time.June, 3, 9, 30, 0, 0, time.UTC)
+
+func TestEmitAfterAndCancel(t *testing.T) {
+	clock := NewFakeClock(schedulerEpoch)
+	emitter := NewEventEmitter()
+	_, err := emitter.SetScheduler(NewMemoryScheduleStore(), clock)
+	require.NoError(t, err)
+
+	var expired, cancelled int32
+	emitter.On("otp.expired", func(EventPayload) { atomic.AddInt32(&expired, 1) })
+	emitter.On("voucher.expired", func(EventPayload) { atomic.AddInt32(&cancelled, 1) })
+
+	_, err = emitter.EmitAfter(EventPayload{Event: "otp.expired"}, 5*time.Minute)
+	require.NoError(t, err)
+	handle, err := emitter.EmitAt(EventPayload{Event: "voucher.expired"}, schedulerEpoch.Add(time.Hour))
+	require.NoError(t, err)
+
+	clock.Advance(4 * time.Minute)
+	require.Equal(t, int32(0), atomic.LoadInt32(&expired))
+
+	clock.Advance(time.Minute)
+	require.Equal(t, int32(1), atomic.LoadInt32(&expired))
+
+	require.NoError(t, handle.Cancel())
+	clock.Advance(2 * time.Hour)
+	require.Equal(t, int32(0), atomic.LoadInt32(&cancelled))
+}
+
+func TestEmitCronRecurs(t *testing.T) {
+	clock := NewFakeClock(schedulerEpoch)
+	store := NewMemoryScheduleStore()
+	emitter := NewEventEmitter()
+	_, err := emitter.SetScheduler(store, clock)
+	require.NoError(t, err)
+
+	var runs []time.Time
+	emitter.On("withdrawal.timeout.check", func(EventPayload) { runs = append(runs, clock.Now()) })
+
+	handle, err := emitter.EmitCron("*/15 * * * *", EventPayload{Event: "withdrawal.timeout.check"})
+	require.NoError(t, err)
+	require.Equal(t, schedulerEpoch.Add(15*time.Minute), handle.RunAt)
+
+	clock.Advance(time.Hour)
+	require.Len(t, runs, 4)
+	require.Equal(t, 10, runs[3].Hour())
+	require.Equal(t, 30, runs[3].Minute())
+
+	require.NoError(t, handle.Cancel())
+	events, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestEmitCronSkipsMissedRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	store, err := NewFileScheduleStore(path)
+	require.NoError(t, err)
+	first := NewEventEmitter()
+	scheduler, err := first.SetScheduler(store, NewFakeClock(schedulerEpoch))
+	require.NoError(t, err)
+	_, err = first.EmitCron("*/15 * * * *", EventPayload{Event: "withdrawal.timeout.check"})
+	require.NoError(t, err)
+	scheduler.Stop()
+
+	// The process comes back three hours later: twelve runs were missed.
+	clock := NewFakeClock(schedulerEpoch.Add(3 * time.Hour))
+	reopened, err := NewFileScheduleStore(path)
+	require.NoError(t, err)
+	second := NewEventEmitter()
+	var runs []time.Time
+	second.On("withdrawal.timeout.check", func(EventPayload) { runs = append(runs, clock.Now()) })
+	_, err = second.SetScheduler(reopened, clock)
+	require.NoError(t, err)
+
+	clock.Advance(0)
+	require.Len(t, runs, 1)
+
+	events, err := reopened.List()
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, schedulerEpoch.Add(3*time.Hour+15*time.Minute), events[0].RunAt)
+
+	clock.Advance(15 * time.Minute)
+	require.Len(t, runs, 2)
+}
+
+func TestCancelWhileCronFires(t *testing.T) {
+	clock := NewFakeClock(schedulerEpoch)
+	store := NewMemoryScheduleStore()
+	emitter := NewEventEmitter()
+	_, err := emitter.SetScheduler(store, clock)
+	require.NoError(t, err)
+
+	var handle *ScheduleHandle
+	var runs int
+	emitter.On("withdrawal.timeout.check", func(EventPayload) {
+		runs++
+		require.NoError(t, handle.Cancel())
+	})
+	handle, err = emitter.EmitCron("*/15 * * * *", EventPayload{Event: "withdrawal.timeout.check"})
+	require.NoError(t, err)
+
+	clock.Advance(time.Hour)
+	require.Equal(t, 1, runs)
+	events, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestScheduleSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	clock := NewFakeClock(schedulerEpoch)
+
+	store, err := NewFileScheduleStore(path)
+	require.NoError(t, err)
+	first := NewEventEmitter()
+	scheduler, err := first.SetScheduler(store, clock)
+	require.NoError(t, err)
+	_, err = first.EmitAfter(EventPayload{Event: "voucher.expired", Data: "v-1"}, time.Hour)
+	require.NoError(t, err)
+	scheduler.Stop()
+
+	reopened, err := NewFileScheduleStore(path)
+	require.NoError(t, err)
+	second := NewEventEmitter()
+	var received EventPayload
+	second.On("voucher.expired", func(event EventPayload) { received = event })
+	_, err = second.SetScheduler(reopened, clock)
+	require.NoError(t, err)
+
+	clock.Advance(time.Hour)
+	require.Equal(t, "v-1", received.Data)
+	require.NotEmpty(t, received.IdempotencyKey)
+
+	events, err := reopened.List()
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2024, time.June, 3, 9, 30, 0, 0, time.UTC) // a Monday
+
+	testCases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"0 0 * * *", time.Date(2024, time.June, 4, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.June, 3, 10, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2024, time.June, 4, 8, 30, 0, 0, time.UTC)},
+		{"0 12 1 * *", time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.June, 9, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := ParseCron(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.next, schedule.Next(from))
+		})
+	}
+
+	for _, spec := range []string{"* * *", "60 * * * *", "*/0 * * * *", "@every -1m"} {
+		_, err := ParseCron(spec)
+		require.Error(t, err, spec)
+	}
+}
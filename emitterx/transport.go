@@ -0,0 +1,183 @@
+package emitterx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const cloudEventsSpecVersion = "1.0"
+
+// CloudEvent is a CloudEvents 1.0 envelope used to carry an EventPayload between services.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	IdempotencyKey  string          `json:"idempotencykey,omitempty"`
+	TraceID         string          `json:"traceid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// NewCloudEvent wraps an event in a CloudEvent envelope originating from source.
+func NewCloudEvent(source string, event EventPayload) (CloudEvent, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	id := event.ID
+	if id == "" {
+		generated, err := uuid.NewV4()
+		if err != nil {
+			return CloudEvent{}, err
+		}
+		id = generated.String()
+	}
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            event.Event,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		IdempotencyKey:  event.IdempotencyKey,
+		TraceID:         event.Metadata[TraceIDKey],
+		Data:            data,
+	}, nil
+}
+
+// Validate checks that the required CloudEvents attributes are present.
+func (c CloudEvent) Validate() error {
+	if c.SpecVersion != cloudEventsSpecVersion {
+		return fmt.Errorf("unsupported cloudevents specversion %q", c.SpecVersion)
+	}
+	if c.ID == "" || c.Source == "" || c.Type == "" {
+		return fmt.Errorf("cloudevent is missing id, source or type")
+	}
+	return nil
+}
+
+// Payload converts the envelope back into an EventPayload. Data is left as
+// json.RawMessage; use DecodeData to unmarshal it into a concrete type.
+func (c CloudEvent) Payload() EventPayload {
+	payload := EventPayload{
+		ID:             c.ID,
+		IdempotencyKey: c.IdempotencyKey,
+		Source:         c.Source,
+		Event:          c.Type,
+		Data:           c.Data,
+	}
+	if c.TraceID != "" {
+		payload.Metadata = map[string]string{TraceIDKey: c.TraceID}
+	}
+	return payload
+}
+
+// DecodeData unmarshals the data of an event into v, whether it was emitted
+// locally with a concrete value or received from a Transport as raw JSON.
+func DecodeData(event EventPayload, v interface{}) error {
+	var raw []byte
+	switch data := event.Data.(type) {
+	case json.RawMessage:
+		raw = data
+	case []byte:
+		raw = data
+	default:
+		var err error
+		raw, err = json.Marshal(data)
+		if err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// ErrTransportClosed is returned by Publish and Subscribe once a transport is closed.
+var ErrTransportClosed = errors.New("transport is closed")
+
+// Transport carries CloudEvents between processes.
+type Transport interface {
+	// Publish sends an event to the broker.
+	Publish(ctx context.Context, event CloudEvent) error
+	// Subscribe delivers events received from the broker to handler until ctx is
+	// cancelled or the transport is closed. It returns once the subscription is active.
+	Subscribe(ctx context.Context, handler func(CloudEvent)) error
+	// Close releases the resources held by the transport.
+	Close() error
+}
+
+// Bridge connects an EventEmitter to a Transport. Local events are forwarded
+// to the transport and remote events are emitted locally with their Source set,
+// so they are never forwarded back.
+type Bridge struct {
+	emitter   *EventEmitter
+	transport Transport
+	source    string
+	forwarded map[string]bool
+	mu        sync.Mutex
+}
+
+// NewBridge creates a Bridge that publishes events as coming from source,
+// for example "/longswipe/voucher-service".
+func NewBridge(emitter *EventEmitter, transport Transport, source string) *Bridge {
+	return &Bridge{
+		emitter:   emitter,
+		transport: transport,
+		source:    source,
+		forwarded: make(map[string]bool),
+	}
+}
+
+// Forward publishes every local occurrence of the named events to the transport.
+func (b *Bridge) Forward(eventNames ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range eventNames {
+		if b.forwarded[name] {
+			continue
+		}
+		b.forwarded[name] = true
+		b.emitter.On(name, b.publish)
+	}
+}
+
+// Start subscribes to the transport and emits remote events locally until ctx is cancelled.
+func (b *Bridge) Start(ctx context.Context) error {
+	return b.transport.Subscribe(ctx, func(event CloudEvent) {
+		if err := event.Validate(); err != nil {
+			logrus.Warn("dropping invalid cloudevent: ", err)
+			return
+		}
+		if event.Source == b.source {
+			return
+		}
+		b.emitter.Emit(event.Payload())
+	})
+}
+
+func (b *Bridge) publish(event EventPayload) {
+	if event.Source != "" && event.Source != b.source {
+		return
+	}
+
+	cloudEvent, err := NewCloudEvent(b.source, event)
+	if err != nil {
+		logrus.WithField("event", event.Event).Error("failed to build cloudevent: ", err)
+		return
+	}
+
+	if err := b.transport.Publish(context.Background(), cloudEvent); err != nil {
+		logrus.WithField("event", event.Event).Error("failed to publish event: ", err)
+	}
+}
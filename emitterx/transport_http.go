@@ -0,0 +1,243 @@
+package emitterx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const cloudEventsContentType = "application/cloudevents+json"
+
+// SignatureHeader carries the HMAC-SHA512 of a webhook, keyed with the shared
+// secret. See SignRequest for what is signed.
+const SignatureHeader = "X-Longswipe-Signature"
+
+// binaryModeHeaders are the CloudEvents attributes covered by the signature of
+// a binary mode request, in signing order.
+var binaryModeHeaders = []string{"ce-specversion", "ce-id", "ce-source", "ce-type", "ce-time", "ce-idempotencykey", "ce-traceid", "Content-Type"}
+
+// HTTPTransport publishes CloudEvents as webhooks to a list of endpoints and
+// receives them through its ServeHTTP method. Both sides share a secret;
+// requests without a valid signature are rejected.
+type HTTPTransport struct {
+	secret    string
+	endpoints []string
+	client    *http.Client
+	handlers  []func(CloudEvent)
+	stops     []func() bool
+	closed    bool
+	mu        sync.RWMutex
+}
+
+// NewHTTPTransport creates a transport that signs every event with secret and
+// posts it to each endpoint.
+func NewHTTPTransport(secret string, endpoints ...string) *HTTPTransport {
+	return &HTTPTransport{
+		secret:    secret,
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetHTTPClient replaces the client used to deliver webhooks.
+func (t *HTTPTransport) SetHTTPClient(client *http.Client) {
+	t.client = client
+}
+
+// Publish posts the event in CloudEvents structured mode to every endpoint.
+// A failing endpoint does not stop delivery to the others; their errors are
+// joined.
+func (t *HTTPTransport) Publish(ctx context.Context, event CloudEvent) error {
+	t.mu.RLock()
+	closed := t.closed
+	t.mu.RUnlock()
+	if closed {
+		return ErrTransportClosed
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, endpoint := range t.endpoints {
+		if err := t.post(ctx, endpoint, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *HTTPTransport) post(ctx context.Context, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", cloudEventsContentType)
+	req.Header.Set(SignatureHeader, SignRequest(t.secret, req.Header, body))
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", endpoint, res.Status)
+	}
+	return nil
+}
+
+// Subscribe registers handler for events received by ServeHTTP until ctx is
+// done or the transport is closed.
+func (t *HTTPTransport) Subscribe(ctx context.Context, handler func(CloudEvent)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrTransportClosed
+	}
+	t.handlers = append(t.handlers, handler)
+	index := len(t.handlers) - 1
+
+	t.stops = append(t.stops, context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if index < len(t.handlers) {
+			t.handlers[index] = nil
+		}
+	}))
+
+	return nil
+}
+
+// Close stops delivering received events to subscribers.
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	t.handlers = nil
+	for _, stop := range t.stops {
+		stop()
+	}
+	t.stops = nil
+	return nil
+}
+
+// ServeHTTP accepts signed CloudEvents in structured or binary content mode.
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !VerifyRequest(t.secret, r.Header, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := readCloudEvent(r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := event.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.mu.RLock()
+	handlers := make([]func(CloudEvent), len(t.handlers))
+	copy(handlers, t.handlers)
+	t.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if handler != nil {
+			handler(event)
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// SignRequest returns the SignatureHeader value for a request with header and
+// body. Structured mode requests sign the body; binary mode requests also sign
+// the CloudEvents attribute headers, so they cannot be swapped between bodies.
+func SignRequest(secret string, header http.Header, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	if !structuredMode(header) {
+		for _, name := range binaryModeHeaders {
+			mac.Write([]byte(header.Get(name)))
+			mac.Write([]byte{'\n'})
+		}
+	}
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequest checks the SignatureHeader of a request. An empty secret
+// rejects every request.
+func VerifyRequest(secret string, header http.Header, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(SignRequest(secret, header, body))
+	if err != nil {
+		return false
+	}
+	signature, err := hex.DecodeString(header.Get(SignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+	return hmac.Equal(signature, expected)
+}
+
+func structuredMode(header http.Header) bool {
+	return strings.HasPrefix(header.Get("Content-Type"), cloudEventsContentType)
+}
+
+func readCloudEvent(header http.Header, body []byte) (CloudEvent, error) {
+	var event CloudEvent
+	if structuredMode(header) {
+		if err := json.Unmarshal(body, &event); err != nil {
+			return CloudEvent{}, err
+		}
+		return event, nil
+	}
+
+	event = CloudEvent{
+		SpecVersion:     header.Get("ce-specversion"),
+		ID:              header.Get("ce-id"),
+		Source:          header.Get("ce-source"),
+		Type:            header.Get("ce-type"),
+		IdempotencyKey:  header.Get("ce-idempotencykey"),
+		TraceID:         header.Get("ce-traceid"),
+		DataContentType: header.Get("Content-Type"),
+		Data:            body,
+	}
+	if eventTime := header.Get("ce-time"); eventTime != "" {
+		var err error
+		event.Time, err = time.Parse(time.RFC3339Nano, eventTime)
+		if err != nil {
+			return CloudEvent{}, err
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,255 @@
+package emitterx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedisTransport publishes CloudEvents on a Redis pub/sub channel. It speaks
+// the RESP protocol directly, so it works with Redis and compatible servers.
+type RedisTransport struct {
+	addr     string
+	password string
+	channel  string
+	timeout  time.Duration
+	conn     net.Conn
+	reader   *bufio.Reader
+	subs     []net.Conn
+	stops    []func() bool
+	closed   bool
+	mu       sync.Mutex
+}
+
+// NewRedisTransport creates a transport for channel on the server at addr.
+func NewRedisTransport(addr, password, channel string) *RedisTransport {
+	return &RedisTransport{
+		addr:     addr,
+		password: password,
+		channel:  channel,
+		timeout:  5 * time.Second,
+	}
+}
+
+// Publish sends the event to the channel with PUBLISH.
+func (t *RedisTransport) Publish(ctx context.Context, event CloudEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrTransportClosed
+	}
+
+	if t.conn == nil {
+		conn, reader, err := t.dial(ctx)
+		if err != nil {
+			return err
+		}
+		t.conn, t.reader = conn, reader
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetDeadline(deadline)
+	} else {
+		t.conn.SetDeadline(time.Now().Add(t.timeout))
+	}
+
+	if _, err := t.command(t.conn, t.reader, "PUBLISH", t.channel, string(body)); err != nil {
+		// Drop the connection so the next publish reconnects.
+		t.conn.Close()
+		t.conn, t.reader = nil, nil
+		return err
+	}
+
+	return nil
+}
+
+// Subscribe opens a dedicated connection, subscribes to the channel and
+// delivers messages to handler in a background goroutine until ctx is done or
+// the transport is closed.
+func (t *RedisTransport) Subscribe(ctx context.Context, handler func(CloudEvent)) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrTransportClosed
+	}
+	t.mu.Unlock()
+
+	conn, reader, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(t.timeout))
+	if _, err := t.command(conn, reader, "SUBSCRIBE", t.channel); err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		stop()
+		conn.Close()
+		return ErrTransportClosed
+	}
+	t.subs = append(t.subs, conn)
+	t.stops = append(t.stops, stop)
+	t.mu.Unlock()
+
+	go func() {
+		defer stop()
+		for {
+			reply, err := readRESP(reader)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && ctx.Err() == nil {
+					logrus.Error("redis subscription ended: ", err)
+				}
+				return
+			}
+
+			message, ok := reply.([]interface{})
+			if !ok || len(message) != 3 || message[0] != "message" {
+				continue
+			}
+			body, _ := message[2].(string)
+
+			var event CloudEvent
+			if err := json.Unmarshal([]byte(body), &event); err != nil {
+				logrus.Warn("dropping malformed cloudevent: ", err)
+				continue
+			}
+			handler(event)
+		}
+	}()
+
+	return nil
+}
+
+// Close closes the publish connection and every subscription.
+func (t *RedisTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn, t.reader = nil, nil
+	}
+	for _, stop := range t.stops {
+		stop()
+	}
+	for _, conn := range t.subs {
+		conn.Close()
+	}
+	t.subs, t.stops = nil, nil
+	return nil
+}
+
+func (t *RedisTransport) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: t.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := bufio.NewReader(conn)
+
+	if t.password != "" {
+		conn.SetDeadline(time.Now().Add(t.timeout))
+		if _, err := t.command(conn, reader, "AUTH", t.password); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn.SetDeadline(time.Time{})
+	}
+
+	return conn, reader, nil
+}
+
+func (t *RedisTransport) command(conn net.Conn, reader *bufio.Reader, args ...string) (interface{}, error) {
+	if _, err := conn.Write(encodeRESPCommand(args...)); err != nil {
+		return nil, err
+	}
+	return readRESP(reader)
+}
+
+// encodeRESPCommand encodes args as a RESP array of bulk strings.
+func encodeRESPCommand(args ...string) []byte {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = append(buf, strconv.Itoa(len(arg))...)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	return buf
+}
+
+// readRESP reads a single RESP value. Simple and bulk strings are returned as
+// string, integers as int64, arrays as []interface{} and nil values as nil.
+// Error replies are returned as errors.
+func readRESP(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed resp line %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRESP(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown resp type %q", kind)
+	}
+}
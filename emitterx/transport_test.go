@@ -0,0 +1,229 @@
+package emitterx
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type voucherRedeemed struct {
+	VoucherID string `json:"voucherId"`
+	Amount    int64  `json:"amount"`
+}
+
+func TestHTTPTransportBridgesEmitters(t *testing.T) {
+	receiver := NewHTTPTransport("webhook-secret")
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	remote := NewEventEmitter()
+	received := make(chan EventPayload, 1)
+	remote.On("voucher.redeemed", func(event EventPayload) { received <- event })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, NewBridge(remote, receiver, "/longswipe/payments-service").Start(ctx))
+
+	local := NewEventEmitter()
+	NewBridge(local, NewHTTPTransport("webhook-secret", server.URL), "/longswipe/voucher-service").Forward("voucher.redeemed")
+	local.Emit(EventPayload{Event: "voucher.redeemed", IdempotencyKey: "v-1", Data: voucherRedeemed{VoucherID: "v-1", Amount: 5000}})
+
+	select {
+	case event := <-received:
+		require.Equal(t, "/longswipe/voucher-service", event.Source)
+		require.Equal(t, "v-1", event.IdempotencyKey)
+
+		var data voucherRedeemed
+		require.NoError(t, DecodeData(event, &data))
+		require.Equal(t, int64(5000), data.Amount)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestHTTPTransportRequiresSignature(t *testing.T) {
+	receiver := NewHTTPTransport("webhook-secret")
+	received := make(chan CloudEvent, 1)
+	require.NoError(t, receiver.Subscribe(context.Background(), func(event CloudEvent) { received <- event }))
+	defer receiver.Close()
+
+	binary := func(eventType, signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"balance":100}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("ce-specversion", "1.0")
+		req.Header.Set("ce-id", "evt-1")
+		req.Header.Set("ce-source", "/longswipe/user-service")
+		req.Header.Set("ce-type", eventType)
+		if signature != "" {
+			req.Header.Set(SignatureHeader, signature)
+		}
+		return req
+	}
+	signed := binary("balance.updated", "")
+	signature := SignRequest("webhook-secret", signed.Header, []byte(`{"balance":100}`))
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{name: "unsigned", req: binary("balance.updated", ""), want: http.StatusUnauthorized},
+		{name: "wrong secret", req: binary("balance.updated", SignRequest("other", signed.Header, []byte(`{"balance":100}`))), want: http.StatusUnauthorized},
+		{name: "attributes changed", req: binary("balance.deleted", signature), want: http.StatusUnauthorized},
+		{name: "signed", req: binary("balance.updated", signature), want: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			receiver.ServeHTTP(rec, tt.req)
+			require.Equal(t, tt.want, rec.Code)
+		})
+	}
+
+	require.Len(t, received, 1)
+	require.Equal(t, "balance.updated", (<-received).Type)
+}
+
+func TestHTTPTransportPublishesToEveryEndpoint(t *testing.T) {
+	var requests int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	receiver := NewHTTPTransport("webhook-secret")
+	received := make(chan CloudEvent, 1)
+	require.NoError(t, receiver.Subscribe(context.Background(), func(event CloudEvent) { received <- event }))
+	defer receiver.Close()
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	publisher := NewHTTPTransport("webhook-secret", failing.URL, server.URL)
+	event, err := NewCloudEvent("/longswipe/user-service", EventPayload{Event: "balance.updated"})
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), event)
+	require.ErrorContains(t, err, "500 Internal Server Error")
+	select {
+	case got := <-received:
+		require.Equal(t, event.ID, got.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered past the failing endpoint")
+	}
+
+	require.NoError(t, publisher.Close())
+	require.ErrorIs(t, publisher.Publish(context.Background(), event), ErrTransportClosed)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRedisTransportPublishSubscribe(t *testing.T) {
+	addr := startFakeRedis(t)
+
+	subscriber := NewRedisTransport(addr, "secret", "longswipe.events")
+	defer subscriber.Close()
+
+	received := make(chan CloudEvent, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, subscriber.Subscribe(ctx, func(event CloudEvent) { received <- event }))
+
+	publisher := NewRedisTransport(addr, "secret", "longswipe.events")
+	defer publisher.Close()
+
+	event, err := NewCloudEvent("/longswipe/user-service", EventPayload{Event: "balance.updated", Data: map[string]int{"balance": 100}})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	select {
+	case got := <-received:
+		require.Equal(t, event.ID, got.ID)
+		require.Equal(t, "balance.updated", got.Type)
+		require.JSONEq(t, `{"balance":100}`, string(got.Data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+func TestRedisTransportRejectsBadPassword(t *testing.T) {
+	addr := startFakeRedis(t)
+
+	transport := NewRedisTransport(addr, "wrong", "longswipe.events")
+	defer transport.Close()
+
+	event, err := NewCloudEvent("/test", EventPayload{Event: "ping"})
+	require.NoError(t, err)
+	require.Error(t, transport.Publish(context.Background(), event))
+}
+
+// startFakeRedis starts a minimal RESP server that understands AUTH, PUBLISH
+// and SUBSCRIBE, which is all RedisTransport needs.
+func startFakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	var (
+		mu          sync.Mutex
+		subscribers = map[string][]net.Conn{}
+	)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					reply, err := readRESP(reader)
+					if err != nil {
+						return
+					}
+					args, _ := reply.([]interface{})
+					if len(args) == 0 {
+						return
+					}
+					switch args[0] {
+					case "AUTH":
+						if args[1] != "secret" {
+							conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+							continue
+						}
+						conn.Write([]byte("+OK\r\n"))
+					case "SUBSCRIBE":
+						channel := args[1].(string)
+						mu.Lock()
+						subscribers[channel] = append(subscribers[channel], conn)
+						mu.Unlock()
+						conn.Write([]byte("*3\r\n$9\r\nsubscribe\r\n$" + strconv.Itoa(len(channel)) + "\r\n" + channel + "\r\n:1\r\n"))
+					case "PUBLISH":
+						channel, message := args[1].(string), args[2].(string)
+						mu.Lock()
+						subs := subscribers[channel]
+						for _, sub := range subs {
+							sub.Write(encodeRESPCommand("message", channel, message))
+						}
+						mu.Unlock()
+						conn.Write([]byte(":" + strconv.Itoa(len(subs)) + "\r\n"))
+					default:
+						conn.Write([]byte("-ERR unknown command\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
@@ -0,0 +1,375 @@
+package gatewayx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// DefaultFlutterwaveURL is the Flutterwave v3 API.
+const DefaultFlutterwaveURL = "https://api.flutterwave.com/v3"
+
+// Flutterwave transfer statuses.
+const (
+	FlutterwaveTransferNew        = "NEW"
+	FlutterwaveTransferPending    = "PENDING"
+	FlutterwaveTransferSuccessful = "SUCCESSFUL"
+	FlutterwaveTransferFailed     = "FAILED"
+)
+
+var flutterwaveTransferStatuses = map[string]interfacesx.TransactionStatus{
+	FlutterwaveTransferNew:        interfacesx.Pending,
+	FlutterwaveTransferPending:    interfacesx.Processing,
+	FlutterwaveTransferSuccessful: interfacesx.Completed,
+	FlutterwaveTransferFailed:     interfacesx.Failed,
+}
+
+// MapFlutterwaveTransferStatus converts a Flutterwave transfer status to a
+// TransactionStatus. Unknown statuses map to Processing so they are never
+// treated as final.
+func MapFlutterwaveTransferStatus(status string) interfacesx.TransactionStatus {
+	if mapped, ok := flutterwaveTransferStatuses[strings.ToUpper(status)]; ok {
+		return mapped
+	}
+	return interfacesx.Processing
+}
+
+// flutterwaveCountries maps a currency to the country Flutterwave lists banks for.
+var flutterwaveCountries = map[string]string{
+	"NGN": "NG",
+	"GHS": "GH",
+	"KES": "KE",
+	"UGX": "UG",
+	"TZS": "TZ",
+	"ZAR": "ZA",
+}
+
+// FlutterwaveError is returned when Flutterwave responds with a non-2xx
+// status or a status field other than "success".
+type FlutterwaveError struct {
+	StatusCode int    `json:"-"`
+	Method     string `json:"-"`
+	Endpoint   string `json:"-"`
+	Message    string `json:"message"`
+}
+
+func (e *FlutterwaveError) Error() string {
+	return fmt.Sprintf("flutterwave %s %s failed: %s", e.Method, e.Endpoint, e.Message)
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *FlutterwaveError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+type flutterwaveGateway struct {
+	baseURL   string
+	secretKey string
+	client    *http.Client
+}
+
+// FlutterwaveOption configures the gateway returned by NewFlutterwave.
+type FlutterwaveOption func(*flutterwaveGateway)
+
+// WithFlutterwaveHTTPClient replaces the HTTP client.
+func WithFlutterwaveHTTPClient(client *http.Client) FlutterwaveOption {
+	return func(f *flutterwaveGateway) {
+		f.client = client
+	}
+}
+
+// NewFlutterwave returns a PaymentGateway backed by the Flutterwave v3 API.
+// baseURL is usually DefaultFlutterwaveURL.
+func NewFlutterwave(baseURL, secretKey string, opts ...FlutterwaveOption) PaymentGateway {
+	f := &flutterwaveGateway{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+func (f *flutterwaveGateway) Provider() Provider {
+	return ProviderFlutterwave
+}
+
+// CreateCustomer makes no request: Flutterwave identifies customers by email,
+// which becomes the customer code.
+func (f *flutterwaveGateway) CreateCustomer(ctx context.Context, data *CustomerRequest) (*Customer, error) {
+	if !strings.Contains(data.Email, "@") {
+		return nil, fmt.Errorf("invalid customer email %q", data.Email)
+	}
+
+	return &Customer{
+		Provider:  ProviderFlutterwave,
+		Code:      data.Email,
+		Email:     data.Email,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		Phone:     data.Phone,
+	}, nil
+}
+
+type flutterwaveVirtualAccountRequest struct {
+	Email       string `json:"email"`
+	IsPermanent bool   `json:"is_permanent"`
+	BVN         string `json:"bvn"`
+	TxRef       string `json:"tx_ref"`
+	PhoneNumber string `json:"phonenumber,omitempty"`
+	FirstName   string `json:"firstname,omitempty"`
+	LastName    string `json:"lastname,omitempty"`
+	Narration   string `json:"narration,omitempty"`
+	Currency    string `json:"currency"`
+}
+
+type flutterwaveVirtualAccount struct {
+	ResponseCode    string `json:"response_code"`
+	ResponseMessage string `json:"response_message"`
+	FlwRef          string `json:"flw_ref"`
+	OrderRef        string `json:"order_ref"`
+	AccountNumber   string `json:"account_number"`
+	BankName        string `json:"bank_name"`
+	Note            string `json:"note"`
+}
+
+// CreateVirtualAccount creates a permanent NGN account, which Flutterwave
+// only issues with a BVN. Reference is sent as tx_ref.
+func (f *flutterwaveGateway) CreateVirtualAccount(ctx context.Context, data *VirtualAccountRequest) (*VirtualAccount, error) {
+	if !strings.EqualFold(data.Currency, "NGN") {
+		return nil, fmt.Errorf("flutterwave virtual account in %s: %w", data.Currency, ErrUnsupportedCurrency)
+	}
+	email := data.Email
+	if email == "" {
+		email = data.CustomerCode
+	}
+	if email == "" || data.BVN == "" || data.Reference == "" {
+		return nil, fmt.Errorf("email, BVN and reference are required")
+	}
+
+	narration := data.Narration
+	if narration == "" {
+		narration = strings.TrimSpace(data.FirstName + " " + data.LastName)
+	}
+
+	var account flutterwaveVirtualAccount
+	if err := f.doJSON(ctx, "POST", "virtual-account-numbers", &flutterwaveVirtualAccountRequest{
+		Email:       email,
+		IsPermanent: true,
+		BVN:         data.BVN,
+		TxRef:       data.Reference,
+		PhoneNumber: data.Phone,
+		FirstName:   data.FirstName,
+		LastName:    data.LastName,
+		Narration:   narration,
+		Currency:    "NGN",
+	}, &account); err != nil {
+		return nil, err
+	}
+
+	return &VirtualAccount{
+		Provider:      ProviderFlutterwave,
+		CustomerCode:  email,
+		BankName:      account.BankName,
+		AccountName:   narration,
+		AccountNumber: account.AccountNumber,
+		Currency:      "NGN",
+		Reference:     data.Reference,
+	}, nil
+}
+
+func (f *flutterwaveGateway) ListBanks(ctx context.Context, currency string) ([]Bank, error) {
+	country, ok := flutterwaveCountries[strings.ToUpper(currency)]
+	if !ok {
+		return nil, fmt.Errorf("flutterwave banks in %s: %w", currency, ErrUnsupportedCurrency)
+	}
+
+	var response []struct {
+		ID   int    `json:"id"`
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := f.doJSON(ctx, "GET", "banks/"+country, nil, &response); err != nil {
+		return nil, err
+	}
+
+	banks := make([]Bank, 0, len(response))
+	for _, bank := range response {
+		banks = append(banks, Bank{Name: bank.Name, Code: bank.Code, Currency: strings.ToUpper(currency)})
+	}
+	return banks, nil
+}
+
+func (f *flutterwaveGateway) ResolveBankAccount(ctx context.Context, data *ResolveBankAccountRequest) (*BankAccount, error) {
+	var response struct {
+		AccountNumber string `json:"account_number"`
+		AccountName   string `json:"account_name"`
+	}
+	if err := f.doJSON(ctx, "POST", "accounts/resolve", map[string]string{
+		"account_number": data.AccountNumber,
+		"account_bank":   data.BankCode,
+	}, &response); err != nil {
+		return nil, err
+	}
+
+	return &BankAccount{
+		Provider:      ProviderFlutterwave,
+		AccountNumber: response.AccountNumber,
+		AccountName:   response.AccountName,
+		BankCode:      data.BankCode,
+	}, nil
+}
+
+type flutterwaveTransferRequest struct {
+	AccountBank   string      `json:"account_bank"`
+	AccountNumber string      `json:"account_number"`
+	Amount        json.Number `json:"amount"`
+	Narration     string      `json:"narration,omitempty"`
+	Currency      string      `json:"currency"`
+	Reference     string      `json:"reference"`
+	DebitCurrency string      `json:"debit_currency"`
+}
+
+type flutterwaveTransfer struct {
+	ID        int         `json:"id"`
+	Reference string      `json:"reference"`
+	Currency  string      `json:"currency"`
+	Amount    json.Number `json:"amount"`
+	Status    string      `json:"status"`
+}
+
+func (t *flutterwaveTransfer) transfer() (*Transfer, error) {
+	amount, err := moneyx.Parse(t.Amount.String(), t.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("flutterwave transfer %d has an invalid amount: %v", t.ID, err)
+	}
+
+	return &Transfer{
+		Provider:       ProviderFlutterwave,
+		ID:             strconv.Itoa(t.ID),
+		Reference:      t.Reference,
+		Amount:         amount,
+		Status:         MapFlutterwaveTransferStatus(t.Status),
+		ProviderStatus: t.Status,
+	}, nil
+}
+
+// Transfer sends the amount in major units, as Flutterwave expects, debited
+// from the balance in the same currency.
+func (f *flutterwaveGateway) Transfer(ctx context.Context, data *TransferRequest) (*Transfer, error) {
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	currency := data.Amount.Currency()
+	if _, ok := flutterwaveCountries[currency]; !ok {
+		return nil, fmt.Errorf("flutterwave transfer in %s: %w", currency, ErrUnsupportedCurrency)
+	}
+
+	var response flutterwaveTransfer
+	if err := f.doJSON(ctx, "POST", "transfers", &flutterwaveTransferRequest{
+		AccountBank:   data.BankCode,
+		AccountNumber: data.AccountNumber,
+		Amount:        json.Number(data.Amount.Decimal()),
+		Narration:     data.Narration,
+		Currency:      currency,
+		Reference:     data.Reference,
+		DebitCurrency: currency,
+	}, &response); err != nil {
+		return nil, err
+	}
+
+	return response.transfer()
+}
+
+func (f *flutterwaveGateway) FetchTransfer(ctx context.Context, id string) (*Transfer, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, fmt.Errorf("flutterwave transfer %q: %w", id, ErrNotFound)
+	}
+
+	var response flutterwaveTransfer
+	if err := f.doJSON(ctx, "GET", "transfers/"+id, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.transfer()
+}
+
+func (f *flutterwaveGateway) Balance(ctx context.Context, currency string) (moneyx.Money, error) {
+	currency = strings.ToUpper(currency)
+
+	var response struct {
+		Currency         string      `json:"currency"`
+		AvailableBalance json.Number `json:"available_balance"`
+	}
+	if err := f.doJSON(ctx, "GET", "balances/"+currency, nil, &response); err != nil {
+		return moneyx.Money{}, err
+	}
+
+	balance, err := moneyx.Parse(response.AvailableBalance.String(), currency)
+	if err != nil {
+		return moneyx.Money{}, fmt.Errorf("flutterwave balance in %s is invalid: %v", currency, err)
+	}
+	return balance, nil
+}
+
+// doJSON makes the request, always closes the body and decodes the data field
+// into response. Failures become a *FlutterwaveError, wrapped in ErrNotFound
+// or ErrUnavailable where they apply.
+func (f *flutterwaveGateway) doJSON(ctx context.Context, method, endpoint string, body, response interface{}) error {
+	var requestBody []byte
+	if body != nil {
+		var err error
+		if requestBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, f.baseURL+"/"+endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.secretKey)
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	decodeErr := json.Unmarshal(raw, &envelope)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices || decodeErr == nil && envelope.Status != "success" {
+		flutterwaveErr := &FlutterwaveError{StatusCode: res.StatusCode, Method: method, Endpoint: endpoint, Message: envelope.Message}
+		if flutterwaveErr.Message == "" {
+			flutterwaveErr.Message = http.StatusText(res.StatusCode)
+		}
+		return statusErr(res.StatusCode, flutterwaveErr)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("flutterwave %s %s returned an invalid response: %v", method, endpoint, decodeErr)
+	}
+
+	return json.Unmarshal(envelope.Data, response)
+}
@@ -0,0 +1,106 @@
+package gatewayx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlutterwaveGateway(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer FLWSECK_TEST", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "POST /virtual-account-numbers":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, true, body["is_permanent"])
+			require.Equal(t, "va-1", body["tx_ref"])
+			w.Write([]byte(`{"status":"success","message":"Virtual account created","data":{"response_code":"02","flw_ref":"FLW-1","order_ref":"URF_1","account_number":"7824822527","bank_name":"WEMA BANK","note":"Please make a bank transfer to Ada Obi"}}`))
+		case "GET /banks/NG":
+			w.Write([]byte(`{"status":"success","message":"Banks fetched successfully","data":[{"id":1,"code":"044","name":"Access Bank"}]}`))
+		case "POST /accounts/resolve":
+			w.Write([]byte(`{"status":"success","message":"Account details fetched","data":{"account_number":"0690000032","account_name":"Pastor Bright"}}`))
+		case "POST /transfers":
+			var body map[string]json.RawMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "1500.50", string(body["amount"]))
+			w.Write([]byte(`{"status":"success","message":"Transfer Queued Successfully","data":{"id":190626,"account_number":"0690000032","bank_code":"044","currency":"NGN","amount":1500.5,"status":"NEW","reference":"payout-1"}}`))
+		case "GET /transfers/190626":
+			w.Write([]byte(`{"status":"success","message":"Transfer fetched","data":{"id":190626,"currency":"NGN","amount":1500.5,"status":"SUCCESSFUL","reference":"payout-1"}}`))
+		case "GET /transfers/404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":"error","message":"No transfer found"}`))
+		case "GET /balances/NGN":
+			w.Write([]byte(`{"status":"success","message":"Wallet balance fetched","data":{"currency":"NGN","available_balance":2500.75,"ledger_balance":3000}}`))
+		case "GET /balances/GHS":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","message":"Rate limit exceeded"}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	gateway := NewFlutterwave(server.URL, "FLWSECK_TEST")
+	ctx := context.Background()
+
+	customer, err := gateway.CreateCustomer(ctx, &CustomerRequest{Email: "ada@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "ada@example.com", customer.Code)
+
+	account, err := gateway.CreateVirtualAccount(ctx, &VirtualAccountRequest{Email: "ada@example.com", FirstName: "Ada", LastName: "Obi", Currency: "NGN", BVN: "12345678901", Reference: "va-1"})
+	require.NoError(t, err)
+	require.Equal(t, VirtualAccount{Provider: ProviderFlutterwave, CustomerCode: "ada@example.com", BankName: "WEMA BANK", AccountName: "Ada Obi", AccountNumber: "7824822527", Currency: "NGN", Reference: "va-1"}, *account)
+
+	_, err = gateway.CreateVirtualAccount(ctx, &VirtualAccountRequest{Email: "ada@example.com", Currency: "GHS"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	banks, err := gateway.ListBanks(ctx, "ngn")
+	require.NoError(t, err)
+	require.Equal(t, []Bank{{Name: "Access Bank", Code: "044", Currency: "NGN"}}, banks)
+
+	resolved, err := gateway.ResolveBankAccount(ctx, &ResolveBankAccountRequest{AccountNumber: "0690000032", BankCode: "044"})
+	require.NoError(t, err)
+	require.Equal(t, "Pastor Bright", resolved.AccountName)
+
+	transfer, err := gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.MustParse("1500.50", "NGN"), BankCode: "044", AccountNumber: "0690000032", Reference: "payout-1"})
+	require.NoError(t, err)
+	require.Equal(t, "190626", transfer.ID)
+	require.Equal(t, interfacesx.Pending, transfer.Status)
+	require.Equal(t, int64(150050), transfer.Amount.Minor())
+
+	fetched, err := gateway.FetchTransfer(ctx, "190626")
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Completed, fetched.Status)
+
+	_, err = gateway.FetchTransfer(ctx, "404")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = gateway.FetchTransfer(ctx, "TRF_1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	balance, err := gateway.Balance(ctx, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, "2500.75 NGN", balance.String())
+
+	_, err = gateway.Balance(ctx, "GHS")
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.EqualError(t, err, "provider unavailable: rate limited: flutterwave GET balances/GHS failed: Rate limit exceeded")
+
+	_, err = gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100, "NGN"), BankCode: "044", AccountNumber: "0690000032"})
+	require.EqualError(t, err, "reference is required to initiate a transfer")
+}
+
+func TestMapFlutterwaveTransferStatus(t *testing.T) {
+	require.Equal(t, interfacesx.Pending, MapFlutterwaveTransferStatus("NEW"))
+	require.Equal(t, interfacesx.Processing, MapFlutterwaveTransferStatus("PENDING"))
+	require.Equal(t, interfacesx.Completed, MapFlutterwaveTransferStatus("successful"))
+	require.Equal(t, interfacesx.Failed, MapFlutterwaveTransferStatus("FAILED"))
+	require.Equal(t, interfacesx.Processing, MapFlutterwaveTransferStatus("UNKNOWN"))
+}
@@ -0,0 +1,164 @@
+// Package gatewayx hides the payment provider behind one PaymentGateway
+// interface. Paystack and Flutterwave implement it, and Router picks a
+// provider per currency and falls back to the next one on failure.
+package gatewayx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+type Provider string
+
+const (
+	ProviderPaystack    Provider = "paystack"
+	ProviderFlutterwave Provider = "flutterwave"
+)
+
+var (
+	// ErrUnsupportedCurrency is returned when a provider cannot handle the
+	// currency. Nothing has been sent to the provider, so Router always falls
+	// back.
+	ErrUnsupportedCurrency = errors.New("currency not supported")
+	// ErrUnavailable is returned when the provider is overloaded or down (429
+	// or 503). Router falls back for reads, but a 503 on a transfer may come
+	// after the money moved, so transfers only fall back on ErrRateLimited.
+	ErrUnavailable = errors.New("provider unavailable")
+	// ErrRateLimited is returned for a 429: the provider turned the request
+	// away without processing it, so Router falls back even for transfers. It
+	// matches ErrUnavailable too.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrUnavailable)
+	// ErrOutcomeUnknown is returned when a transfer failed in a way that may
+	// have reached the provider and could not be looked up afterwards. The
+	// money may have moved, so Router never falls back on it.
+	ErrOutcomeUnknown = errors.New("transfer outcome unknown")
+	// ErrNotFound is returned when the provider has no record of a resource.
+	ErrNotFound = errors.New("not found")
+	// ErrNoGateway is returned by Router when no gateway is configured for a
+	// currency.
+	ErrNoGateway = errors.New("no gateway configured")
+)
+
+// PaymentGateway is implemented by each provider. Amounts are moneyx.Money so
+// callers never deal with a provider's unit.
+type PaymentGateway interface {
+	Provider() Provider
+	CreateCustomer(ctx context.Context, data *CustomerRequest) (*Customer, error)
+	CreateVirtualAccount(ctx context.Context, data *VirtualAccountRequest) (*VirtualAccount, error)
+	ListBanks(ctx context.Context, currency string) ([]Bank, error)
+	ResolveBankAccount(ctx context.Context, data *ResolveBankAccountRequest) (*BankAccount, error)
+	Transfer(ctx context.Context, data *TransferRequest) (*Transfer, error)
+	FetchTransfer(ctx context.Context, id string) (*Transfer, error)
+	Balance(ctx context.Context, currency string) (moneyx.Money, error)
+}
+
+// TransferVerifier is implemented by gateways that can look a transfer up by
+// the caller's reference. Router uses it to find out whether a transfer that
+// failed with an uncertain outcome was made after all.
+type TransferVerifier interface {
+	VerifyTransfer(ctx context.Context, reference string) (*Transfer, error)
+}
+
+// CustomerRequest creates a customer. Currency only selects the gateway when
+// the request goes through a Router.
+type CustomerRequest struct {
+	Email     string
+	FirstName string
+	LastName  string
+	Phone     string
+	Currency  string
+}
+
+type Customer struct {
+	Provider Provider
+	// Code identifies the customer at the provider.
+	Code      string
+	Email     string
+	FirstName string
+	LastName  string
+	Phone     string
+}
+
+// VirtualAccountRequest creates a permanent account the customer can fund by
+// bank transfer. CustomerCode reuses an existing customer; without it the
+// customer is created from the other fields first.
+type VirtualAccountRequest struct {
+	CustomerCode  string
+	Email         string
+	FirstName     string
+	LastName      string
+	Phone         string
+	Currency      string
+	PreferredBank string
+	BVN           string
+	Reference     string
+	Narration     string
+}
+
+type VirtualAccount struct {
+	Provider      Provider
+	CustomerCode  string
+	BankName      string
+	AccountName   string
+	AccountNumber string
+	Currency      string
+	Reference     string
+}
+
+type Bank struct {
+	Name     string
+	Code     string
+	Currency string
+}
+
+type ResolveBankAccountRequest struct {
+	AccountNumber string
+	BankCode      string
+	Currency      string
+}
+
+type BankAccount struct {
+	Provider      Provider
+	AccountNumber string
+	AccountName   string
+	BankCode      string
+}
+
+// TransferRequest sends Amount to a bank account. Reference is required and
+// must be unique; providers use it to reject duplicates.
+type TransferRequest struct {
+	Amount        moneyx.Money
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Reference     string
+	Narration     string
+}
+
+type Transfer struct {
+	Provider Provider
+	// ID identifies the transfer at the provider and is passed to FetchTransfer.
+	ID        string
+	Reference string
+	Amount    moneyx.Money
+	Status    interfacesx.TransactionStatus
+	// ProviderStatus is the status as reported by the provider.
+	ProviderStatus string
+}
+
+func (r *TransferRequest) validate() error {
+	if r.Reference == "" {
+		return fmt.Errorf("reference is required to initiate a transfer")
+	}
+	if !r.Amount.IsPositive() {
+		return fmt.Errorf("transfer amount must be positive")
+	}
+	if r.AccountNumber == "" || r.BankCode == "" {
+		return fmt.Errorf("account number and bank code are required")
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package gatewayx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// paystackAccountCurrencies are the currencies Paystack issues dedicated
+// accounts in.
+var paystackAccountCurrencies = map[string]bool{"NGN": true, "GHS": true}
+
+type paystackGateway struct {
+	client paystackx.PaystackService
+}
+
+// NewPaystack adapts a Paystack client to PaymentGateway.
+func NewPaystack(client paystackx.PaystackService) PaymentGateway {
+	return &paystackGateway{client: client}
+}
+
+func (p *paystackGateway) Provider() Provider {
+	return ProviderPaystack
+}
+
+func (p *paystackGateway) CreateCustomer(ctx context.Context, data *CustomerRequest) (*Customer, error) {
+	response, err := p.client.CreateUserWithContext(ctx, paystackx.PaystackCreateUserRequest{
+		Email:     data.Email,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		Phone:     data.Phone,
+	})
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &Customer{
+		Provider:  ProviderPaystack,
+		Code:      response.Data.CustomerCode,
+		Email:     response.Data.Email,
+		FirstName: data.FirstName,
+		LastName:  data.LastName,
+		Phone:     data.Phone,
+	}, nil
+}
+
+func (p *paystackGateway) CreateVirtualAccount(ctx context.Context, data *VirtualAccountRequest) (*VirtualAccount, error) {
+	currency := strings.ToUpper(data.Currency)
+	if !paystackAccountCurrencies[currency] {
+		return nil, fmt.Errorf("paystack virtual account in %s: %w", data.Currency, ErrUnsupportedCurrency)
+	}
+	if data.PreferredBank == "" {
+		return nil, fmt.Errorf("preferred bank is required")
+	}
+
+	customerCode := data.CustomerCode
+	if customerCode == "" {
+		customer, err := p.CreateCustomer(ctx, &CustomerRequest{Email: data.Email, FirstName: data.FirstName, LastName: data.LastName, Phone: data.Phone})
+		if err != nil {
+			return nil, err
+		}
+		customerCode = customer.Code
+	}
+
+	response, err := p.client.CreateVirtualAccountWithContext(ctx, &interfacesx.CreatePaystackVirtualAccountRequest{
+		Customer:      customerCode,
+		PreferredBank: data.PreferredBank,
+		FirstName:     data.FirstName,
+		LastName:      data.LastName,
+		Phone:         data.Phone,
+	})
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &VirtualAccount{
+		Provider:      ProviderPaystack,
+		CustomerCode:  customerCode,
+		BankName:      response.Data.Bank.Name,
+		AccountName:   response.Data.AccountName,
+		AccountNumber: response.Data.AccountNumber,
+		Currency:      response.Data.Currency,
+		Reference:     data.Reference,
+	}, nil
+}
+
+func (p *paystackGateway) ListBanks(ctx context.Context, currency string) ([]Bank, error) {
+	query, err := paystackx.BankQueryForCurrency(currency)
+	if err != nil {
+		return nil, fmt.Errorf("paystack banks in %s: %w", currency, ErrUnsupportedCurrency)
+	}
+
+	response, err := p.client.ListBanksWithContext(ctx, query)
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	banks := make([]Bank, 0, len(response.Data))
+	for _, bank := range response.Data {
+		banks = append(banks, Bank{Name: bank.Name, Code: bank.Code, Currency: bank.Currency})
+	}
+	return banks, nil
+}
+
+func (p *paystackGateway) ResolveBankAccount(ctx context.Context, data *ResolveBankAccountRequest) (*BankAccount, error) {
+	response, err := p.client.ResolveAccountNumberWithContext(ctx, &interfacesx.ResolveBankAccountRequest{
+		AccountNumber: data.AccountNumber,
+		BankCode:      data.BankCode,
+	})
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &BankAccount{
+		Provider:      ProviderPaystack,
+		AccountNumber: response.Data.AccountNumber,
+		AccountName:   response.Data.AccountName,
+		BankCode:      data.BankCode,
+	}, nil
+}
+
+// Transfer creates a transfer recipient for the account and sends the
+// transfer. A mobile money provider code such as "MTN" or "MPESA" as BankCode
+// pays the wallet of the mobile number in AccountNumber. With transfer OTP
+// enabled the result is Pending until the OTP is finalized through the
+// Paystack client.
+func (p *paystackGateway) Transfer(ctx context.Context, data *TransferRequest) (*Transfer, error) {
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	currency := data.Amount.Currency()
+	recipientType, err := paystackx.RecipientTypeFor(currency, data.BankCode)
+	if err != nil {
+		return nil, fmt.Errorf("paystack transfer in %s: %w", currency, ErrUnsupportedCurrency)
+	}
+
+	request := &paystackx.PaystackCreateTransferRecipientRequest{
+		Type:          recipientType,
+		Name:          data.AccountName,
+		AccountNumber: data.AccountNumber,
+		BankCode:      data.BankCode,
+		Currency:      currency,
+	}
+	if recipientType == paystackx.RecipientTypeMobileMoney {
+		request = paystackx.NewMobileMoneyRecipient(data.AccountName, data.AccountNumber, data.BankCode)
+	}
+	recipient, err := p.client.CreateTransferRecipientWithContext(ctx, request)
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	transfer := paystackx.NewTransferFundsRequest(data.Amount, recipient.Data.RecipientCode, data.Reference, data.Narration)
+	response, err := p.client.InitiateTransferWithContext(ctx, transfer)
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &Transfer{
+		Provider:       ProviderPaystack,
+		ID:             response.Data.TransferCode,
+		Reference:      data.Reference,
+		Amount:         moneyx.New(response.Data.Amount, response.Data.Currency),
+		Status:         paystackx.MapTransferStatus(response.Data.Status),
+		ProviderStatus: response.Data.Status,
+	}, nil
+}
+
+// FetchTransfer only accepts transfer codes, the IDs Transfer returns, so a
+// numeric ID from another provider is never matched to a Paystack transfer.
+func (p *paystackGateway) FetchTransfer(ctx context.Context, id string) (*Transfer, error) {
+	if !strings.HasPrefix(id, "TRF_") {
+		return nil, fmt.Errorf("paystack transfer %q: %w", id, ErrNotFound)
+	}
+
+	response, err := p.client.FetchTransferWithContext(ctx, id)
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &Transfer{
+		Provider:       ProviderPaystack,
+		ID:             response.Data.TransferCode,
+		Reference:      response.Data.Reference,
+		Amount:         response.Data.Money(),
+		Status:         response.Data.TransactionStatus(),
+		ProviderStatus: response.Data.Status,
+	}, nil
+}
+
+// VerifyTransfer looks a transfer up by the caller's reference.
+func (p *paystackGateway) VerifyTransfer(ctx context.Context, reference string) (*Transfer, error) {
+	response, err := p.client.VerifyTransferWithContext(ctx, reference)
+	if err != nil {
+		return nil, paystackErr(err)
+	}
+
+	return &Transfer{
+		Provider:       ProviderPaystack,
+		ID:             response.Data.TransferCode,
+		Reference:      response.Data.Reference,
+		Amount:         response.Data.Money(),
+		Status:         response.Data.TransactionStatus(),
+		ProviderStatus: response.Data.Status,
+	}, nil
+}
+
+func (p *paystackGateway) Balance(ctx context.Context, currency string) (moneyx.Money, error) {
+	response, err := p.client.FetchBalanceWithContext(ctx)
+	if err != nil {
+		return moneyx.Money{}, paystackErr(err)
+	}
+
+	balance, ok := response.Balance(currency)
+	if !ok {
+		return moneyx.Money{}, fmt.Errorf("paystack balance in %s: %w", currency, ErrUnsupportedCurrency)
+	}
+	return balance, nil
+}
+
+// paystackErr adds ErrOutcomeUnknown, ErrNotFound or ErrUnavailable to
+// Paystack errors that match them, keeping the *paystackx.PaystackError in the
+// chain.
+func paystackErr(err error) error {
+	if errors.Is(err, paystackx.ErrTransferOutcomeUnknown) {
+		return fmt.Errorf("%w: %w", ErrOutcomeUnknown, err)
+	}
+	paystackError, ok := paystackx.AsPaystackError(err)
+	if !ok {
+		return err
+	}
+	return statusErr(paystackError.StatusCode, err)
+}
+
+func statusErr(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case http.StatusServiceUnavailable:
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
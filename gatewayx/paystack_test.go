@@ -0,0 +1,102 @@
+package gatewayx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx/paystacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaystackGateway(t *testing.T) {
+	fake := paystacktest.NewServer("sk_test_gateway")
+	defer fake.Close()
+	fake.SetBalance("NGN", 1000000)
+	fake.AddBankAccount("058", "0123456789", "ADA OBI")
+	gateway := NewPaystack(fake.Client(paystackx.WithRetryPolicy(paystackx.NoRetry())))
+	ctx := context.Background()
+
+	account, err := gateway.CreateVirtualAccount(ctx, &VirtualAccountRequest{Email: "ada@example.com", FirstName: "Ada", LastName: "Obi", Phone: "+2348100000000", Currency: "NGN", PreferredBank: "wema-bank"})
+	require.NoError(t, err)
+	require.Equal(t, ProviderPaystack, account.Provider)
+	require.Equal(t, "Wema Bank", account.BankName)
+	require.NotEmpty(t, account.AccountNumber)
+	_, ok := fake.Customer(account.CustomerCode)
+	require.True(t, ok)
+
+	_, err = gateway.CreateVirtualAccount(ctx, &VirtualAccountRequest{Email: "ada@example.com", Currency: "KES", PreferredBank: "wema-bank"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	banks, err := gateway.ListBanks(ctx, "NGN")
+	require.NoError(t, err)
+	require.NotEmpty(t, banks)
+
+	resolved, err := gateway.ResolveBankAccount(ctx, &ResolveBankAccountRequest{AccountNumber: "0123456789", BankCode: "058", Currency: "NGN"})
+	require.NoError(t, err)
+	require.Equal(t, "ADA OBI", resolved.AccountName)
+
+	transfer, err := gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.New(250000, "NGN"), BankCode: "058", AccountNumber: "0123456789", AccountName: "ADA OBI", Reference: "payout-1", Narration: "Withdrawal"})
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Pending, transfer.Status)
+	require.Equal(t, "2500.00 NGN", transfer.Amount.String())
+
+	require.NoError(t, fake.CompleteTransfer("payout-1", paystackx.TransferStatusSuccess))
+	fetched, err := gateway.FetchTransfer(ctx, transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Completed, fetched.Status)
+	require.Equal(t, "payout-1", fetched.Reference)
+
+	_, err = gateway.FetchTransfer(ctx, "TRF_missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = gateway.FetchTransfer(ctx, "12345")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	balance, err := gateway.Balance(ctx, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, "7500.00 NGN", balance.String())
+
+	fake.Fail(paystacktest.Failure{Path: "/balance", StatusCode: http.StatusServiceUnavailable})
+	_, err = gateway.Balance(ctx, "NGN")
+	require.ErrorIs(t, err, ErrUnavailable)
+	_, ok = paystackx.AsPaystackError(err)
+	require.True(t, ok)
+	require.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestPaystackMobileMoneyTransfer(t *testing.T) {
+	fake := paystacktest.NewServer("sk_test_gateway")
+	defer fake.Close()
+	fake.SetBalance("GHS", 500000)
+	fake.AddBank(paystackx.Banks{ID: 61, Name: "MTN Mobile Money", Slug: "mtn-mobile-money", Code: "MTN", Country: "Ghana", Currency: "GHS", Type: "mobile_money", Active: true})
+	fake.AddBank(paystackx.Banks{ID: 131, Name: "M-PESA", Slug: "mpesa", Code: "MPESA", Country: "Kenya", Currency: "KES", Type: "mobile_money", Active: true})
+	fake.AddBankAccount("MTN", "0551234987", "KOFI MENSAH")
+	gateway := NewPaystack(fake.Client(paystackx.WithRetryPolicy(paystackx.NoRetry())))
+	ctx := context.Background()
+
+	resolved, err := gateway.ResolveBankAccount(ctx, &ResolveBankAccountRequest{AccountNumber: "+233 55 123 4987", BankCode: "MTN", Currency: "GHS"})
+	require.NoError(t, err)
+	require.Equal(t, "KOFI MENSAH", resolved.AccountName)
+
+	transfer, err := gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.New(120000, "GHS"), BankCode: "MTN", AccountNumber: "+233551234987", AccountName: "Kofi Mensah", Reference: "payout-gh-1", Narration: "Withdrawal"})
+	require.NoError(t, err)
+	require.Equal(t, "1200.00 GHS", transfer.Amount.String())
+
+	recipients, err := fake.Client().ListTransferRecipients(nil)
+	require.NoError(t, err)
+	require.Len(t, recipients.Data, 1)
+	require.Equal(t, paystackx.RecipientTypeMobileMoney, recipients.Data[0].Type)
+	require.Equal(t, "0551234987", recipients.Data[0].Details.AccountNumber)
+	require.Equal(t, "KOFI MENSAH", recipients.Data[0].Name)
+
+	// Kenyan bank accounts have no Paystack recipient type here, and an MTN
+	// Ghana wallet cannot receive shillings.
+	_, err = gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100000, "KES"), BankCode: "01", AccountNumber: "1234567890", Reference: "payout-ke-1"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	_, err = gateway.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100000, "KES"), BankCode: "MTN", AccountNumber: "0551234987", Reference: "payout-ke-2"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
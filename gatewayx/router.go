@@ -0,0 +1,291 @@
+package gatewayx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// ProviderRouter is what Router.Provider returns. Results from a Router carry
+// the provider that actually served them.
+const ProviderRouter Provider = "router"
+
+// DefaultTransferMemory is how many transfers a Router remembers the gateway
+// of for FetchTransfer.
+const DefaultTransferMemory = 10000
+
+// Router is a PaymentGateway that sends each call to the gateways configured
+// for its currency, in order. A call falls back to the next gateway when the
+// currency is unsupported, and, except for transfers, when the failure is
+// temporary. A transfer only falls back when the provider certainly did not
+// process it: a 429 or a failure before the request was sent. A transfer that
+// may have reached the provider is verified by reference instead, and never
+// sent to a second one.
+type Router struct {
+	routes   map[string][]PaymentGateway
+	fallback []PaymentGateway
+
+	mu             sync.Mutex
+	transfers      map[string]PaymentGateway
+	transferOrder  []string
+	transferMemory int
+}
+
+// RouterOption configures the Router returned by NewRouter.
+type RouterOption func(*Router)
+
+// Route sets the gateways used for currency, in order of preference.
+func Route(currency string, gateways ...PaymentGateway) RouterOption {
+	return func(r *Router) {
+		r.routes[strings.ToUpper(currency)] = gateways
+	}
+}
+
+// DefaultRoute sets the gateways used for currencies without a Route, and
+// for calls without a currency.
+func DefaultRoute(gateways ...PaymentGateway) RouterOption {
+	return func(r *Router) {
+		r.fallback = gateways
+	}
+}
+
+// RememberTransfers sets how many transfers the Router remembers the gateway
+// of. Older ones are forgotten and FetchTransfer looks them up in every
+// gateway. It defaults to DefaultTransferMemory.
+func RememberTransfers(n int) RouterOption {
+	return func(r *Router) {
+		r.transferMemory = n
+	}
+}
+
+func NewRouter(opts ...RouterOption) *Router {
+	r := &Router{
+		routes:         make(map[string][]PaymentGateway),
+		transfers:      make(map[string]PaymentGateway),
+		transferMemory: DefaultTransferMemory,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Gateways returns the gateways tried for currency, in order.
+func (r *Router) Gateways(currency string) []PaymentGateway {
+	if gateways, ok := r.routes[strings.ToUpper(currency)]; ok {
+		return gateways
+	}
+	return r.fallback
+}
+
+func (r *Router) Provider() Provider {
+	return ProviderRouter
+}
+
+func (r *Router) CreateCustomer(ctx context.Context, data *CustomerRequest) (*Customer, error) {
+	var customer *Customer
+	err := r.try(ctx, data.Currency, false, func(gateway PaymentGateway) (err error) {
+		customer, err = gateway.CreateCustomer(ctx, data)
+		return err
+	})
+	return customer, err
+}
+
+func (r *Router) CreateVirtualAccount(ctx context.Context, data *VirtualAccountRequest) (*VirtualAccount, error) {
+	var account *VirtualAccount
+	err := r.try(ctx, data.Currency, false, func(gateway PaymentGateway) (err error) {
+		account, err = gateway.CreateVirtualAccount(ctx, data)
+		return err
+	})
+	return account, err
+}
+
+func (r *Router) ListBanks(ctx context.Context, currency string) ([]Bank, error) {
+	var banks []Bank
+	err := r.try(ctx, currency, false, func(gateway PaymentGateway) (err error) {
+		banks, err = gateway.ListBanks(ctx, currency)
+		return err
+	})
+	return banks, err
+}
+
+// ResolveBankAccount routes by data.Currency. Bank codes differ between
+// providers, so only configure gateways that share codes on one route.
+func (r *Router) ResolveBankAccount(ctx context.Context, data *ResolveBankAccountRequest) (*BankAccount, error) {
+	var account *BankAccount
+	err := r.try(ctx, data.Currency, false, func(gateway PaymentGateway) (err error) {
+		account, err = gateway.ResolveBankAccount(ctx, data)
+		return err
+	})
+	return account, err
+}
+
+// Transfer routes by the currency of the amount and remembers which gateway
+// took the transfer so FetchTransfer can go straight to it. When a gateway
+// fails in a way that leaves the outcome unknown, such as a 503 or
+// ErrOutcomeUnknown, the transfer is looked up by reference at that gateway,
+// if it is a TransferVerifier, and returned when found. It is never sent to
+// the next gateway; the error is returned instead.
+func (r *Router) Transfer(ctx context.Context, data *TransferRequest) (*Transfer, error) {
+	var transfer *Transfer
+	err := r.try(ctx, data.Amount.Currency(), true, func(gateway PaymentGateway) (err error) {
+		transfer, err = gateway.Transfer(ctx, data)
+		if err != nil && !canFallBack(err, true) {
+			if verified, ok := verifyTransfer(ctx, gateway, data.Reference); ok {
+				transfer, err = verified, nil
+			}
+		}
+		if err == nil {
+			r.remember(transfer.ID, gateway)
+		}
+		return err
+	})
+	return transfer, err
+}
+
+// verifyTransfer looks reference up at gateway and reports whether it found
+// the transfer.
+func verifyTransfer(ctx context.Context, gateway PaymentGateway, reference string) (*Transfer, bool) {
+	verifier, ok := gateway.(TransferVerifier)
+	if !ok || reference == "" {
+		return nil, false
+	}
+	transfer, err := verifier.VerifyTransfer(ctx, reference)
+	return transfer, err == nil
+}
+
+// remember records the gateway of a transfer, forgetting the oldest one once
+// transferMemory is reached.
+func (r *Router) remember(id string, gateway PaymentGateway) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.transfers[id]; !ok {
+		r.transferOrder = append(r.transferOrder, id)
+	}
+	r.transfers[id] = gateway
+	for r.transferMemory > 0 && len(r.transferOrder) > r.transferMemory {
+		delete(r.transfers, r.transferOrder[0])
+		r.transferOrder = r.transferOrder[1:]
+	}
+}
+
+// FetchTransfer asks the gateway that made the transfer. Transfers made
+// elsewhere are looked up in every configured gateway until one knows the ID.
+func (r *Router) FetchTransfer(ctx context.Context, id string) (*Transfer, error) {
+	r.mu.Lock()
+	gateway, ok := r.transfers[id]
+	r.mu.Unlock()
+	if ok {
+		return gateway.FetchTransfer(ctx, id)
+	}
+
+	var errs []error
+	for _, gateway := range r.allGateways() {
+		transfer, err := gateway.FetchTransfer(ctx, id)
+		if err == nil {
+			return transfer, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", gateway.Provider(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("transfer %s: %w", id, ErrNoGateway)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (r *Router) Balance(ctx context.Context, currency string) (moneyx.Money, error) {
+	var balance moneyx.Money
+	err := r.try(ctx, currency, false, func(gateway PaymentGateway) (err error) {
+		balance, err = gateway.Balance(ctx, currency)
+		return err
+	})
+	return balance, err
+}
+
+// try calls fn with each gateway for currency until one succeeds or a failure
+// rules out falling back. The errors of every attempt are joined.
+func (r *Router) try(ctx context.Context, currency string, movesMoney bool, fn func(PaymentGateway) error) error {
+	gateways := r.Gateways(currency)
+	if len(gateways) == 0 {
+		return fmt.Errorf("currency %s: %w", currency, ErrNoGateway)
+	}
+
+	var errs []error
+	for _, gateway := range gateways {
+		err := fn(gateway)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", gateway.Provider(), err))
+		if ctx.Err() != nil || !canFallBack(err, movesMoney) {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// canFallBack reports whether a failed call may be sent to another gateway.
+// Money movement only falls back when the provider certainly did nothing; an
+// unknown outcome never falls back, whatever else is in the chain.
+func canFallBack(err error, movesMoney bool) bool {
+	if errors.Is(err, ErrOutcomeUnknown) {
+		return false
+	}
+	if errors.Is(err, ErrUnsupportedCurrency) || errors.Is(err, ErrRateLimited) || notSent(err) {
+		return true
+	}
+	if movesMoney {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// notSent reports whether err happened before the request reached the
+// provider: the connection could not be opened.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// allGateways returns every configured gateway once, routes first in
+// currency order.
+func (r *Router) allGateways() []PaymentGateway {
+	seen := make(map[PaymentGateway]bool)
+	var gateways []PaymentGateway
+	add := func(list []PaymentGateway) {
+		for _, gateway := range list {
+			if !seen[gateway] {
+				seen[gateway] = true
+				gateways = append(gateways, gateway)
+			}
+		}
+	}
+	currencies := make([]string, 0, len(r.routes))
+	for currency := range r.routes {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		add(r.routes[currency])
+	}
+	add(r.fallback)
+	return gateways
+}
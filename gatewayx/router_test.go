@@ -0,0 +1,280 @@
+package gatewayx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx/paystacktest"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGateway serves balances and transfers from memory and fails with err
+// when it is set.
+type stubGateway struct {
+	provider  Provider
+	err       error
+	calls     int
+	transfers map[string]*Transfer
+}
+
+func newStubGateway(provider Provider) *stubGateway {
+	return &stubGateway{provider: provider, transfers: make(map[string]*Transfer)}
+}
+
+func (s *stubGateway) Provider() Provider { return s.provider }
+
+func (s *stubGateway) CreateCustomer(ctx context.Context, data *CustomerRequest) (*Customer, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Customer{Provider: s.provider, Code: data.Email, Email: data.Email}, nil
+}
+
+func (s *stubGateway) CreateVirtualAccount(ctx context.Context, data *VirtualAccountRequest) (*VirtualAccount, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &VirtualAccount{Provider: s.provider, Currency: data.Currency}, nil
+}
+
+func (s *stubGateway) ListBanks(ctx context.Context, currency string) ([]Bank, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []Bank{{Name: string(s.provider), Currency: currency}}, nil
+}
+
+func (s *stubGateway) ResolveBankAccount(ctx context.Context, data *ResolveBankAccountRequest) (*BankAccount, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &BankAccount{Provider: s.provider, AccountNumber: data.AccountNumber}, nil
+}
+
+func (s *stubGateway) Transfer(ctx context.Context, data *TransferRequest) (*Transfer, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	transfer := &Transfer{Provider: s.provider, ID: fmt.Sprintf("%s-%d", s.provider, len(s.transfers)+1), Reference: data.Reference, Amount: data.Amount, Status: interfacesx.Pending}
+	s.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+func (s *stubGateway) FetchTransfer(ctx context.Context, id string) (*Transfer, error) {
+	s.calls++
+	if transfer, ok := s.transfers[id]; ok {
+		return transfer, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (s *stubGateway) Balance(ctx context.Context, currency string) (moneyx.Money, error) {
+	s.calls++
+	if s.err != nil {
+		return moneyx.Money{}, s.err
+	}
+	return moneyx.New(100, currency), nil
+}
+
+func TestRouterRoutesByCurrency(t *testing.T) {
+	paystack := newStubGateway(ProviderPaystack)
+	flutterwave := newStubGateway(ProviderFlutterwave)
+	router := NewRouter(
+		Route("NGN", paystack, flutterwave),
+		Route("kes", flutterwave),
+		DefaultRoute(flutterwave),
+	)
+	ctx := context.Background()
+
+	banks, err := router.ListBanks(ctx, "NGN")
+	require.NoError(t, err)
+	require.Equal(t, "paystack", banks[0].Name)
+
+	banks, err = router.ListBanks(ctx, "KES")
+	require.NoError(t, err)
+	require.Equal(t, "flutterwave", banks[0].Name)
+
+	account, err := router.CreateVirtualAccount(ctx, &VirtualAccountRequest{Currency: "UGX"})
+	require.NoError(t, err)
+	require.Equal(t, ProviderFlutterwave, account.Provider)
+
+	_, err = NewRouter(Route("NGN", paystack)).Balance(ctx, "GHS")
+	require.ErrorIs(t, err, ErrNoGateway)
+}
+
+var (
+	urlError  = url.Error{Op: "Get", URL: "https://api.example.com", Err: errors.New("connection reset")}
+	dialError = url.Error{Op: "Post", URL: "https://api.example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+)
+
+func TestRouterFallsBack(t *testing.T) {
+	ctx := context.Background()
+	temporary := &paystackx.PaystackError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"}
+	rejected := &paystackx.PaystackError{StatusCode: http.StatusBadRequest, Message: "Invalid bank code"}
+
+	tests := []struct {
+		name     string
+		err      error
+		transfer bool
+		fallback bool
+	}{
+		{name: "temporary read", err: temporary, fallback: true},
+		{name: "rejected read", err: rejected, fallback: false},
+		{name: "network read", err: &urlError, fallback: true},
+		{name: "unsupported transfer", err: fmt.Errorf("paystack transfer in KES: %w", ErrUnsupportedCurrency), transfer: true, fallback: true},
+		{name: "rate limited transfer", err: fmt.Errorf("%w: %w", ErrRateLimited, temporary), transfer: true, fallback: true},
+		{name: "unavailable transfer", err: fmt.Errorf("%w: %w", ErrUnavailable, temporary), transfer: true, fallback: false},
+		{name: "unavailable read", err: fmt.Errorf("%w: %w", ErrUnavailable, temporary), fallback: true},
+		{name: "unsent transfer", err: &dialError, transfer: true, fallback: true},
+		{name: "temporary transfer", err: temporary, transfer: true, fallback: false},
+		{name: "network transfer", err: &urlError, transfer: true, fallback: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := newStubGateway(ProviderPaystack)
+			primary.err = tt.err
+			secondary := newStubGateway(ProviderFlutterwave)
+			router := NewRouter(Route("NGN", primary, secondary))
+
+			var err error
+			if tt.transfer {
+				_, err = router.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100, "NGN"), Reference: "ref"})
+			} else {
+				_, err = router.Balance(ctx, "NGN")
+			}
+
+			require.Equal(t, 1, primary.calls)
+			if tt.fallback {
+				require.NoError(t, err)
+				require.Equal(t, 1, secondary.calls)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.err))
+			require.Zero(t, secondary.calls)
+		})
+	}
+}
+
+func TestRouterJoinsErrors(t *testing.T) {
+	primary := newStubGateway(ProviderPaystack)
+	primary.err = ErrUnavailable
+	secondary := newStubGateway(ProviderFlutterwave)
+	secondary.err = fmt.Errorf("flutterwave banks in XOF: %w", ErrUnsupportedCurrency)
+	router := NewRouter(DefaultRoute(primary, secondary))
+
+	_, err := router.ListBanks(context.Background(), "XOF")
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	require.EqualError(t, err, "paystack: provider unavailable\nflutterwave: flutterwave banks in XOF: currency not supported")
+}
+
+func TestRouterFetchTransfer(t *testing.T) {
+	paystack := newStubGateway(ProviderPaystack)
+	flutterwave := newStubGateway(ProviderFlutterwave)
+	router := NewRouter(Route("NGN", paystack), Route("GHS", flutterwave))
+	ctx := context.Background()
+
+	transfer, err := router.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100, "GHS"), Reference: "ref"})
+	require.NoError(t, err)
+	flutterwave.calls = 0
+
+	fetched, err := router.FetchTransfer(ctx, transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, ProviderFlutterwave, fetched.Provider)
+	require.Zero(t, paystack.calls)
+
+	// A transfer made by another process is looked up in every gateway.
+	flutterwave.transfers["flutterwave-9"] = &Transfer{Provider: ProviderFlutterwave, ID: "flutterwave-9"}
+	fetched, err = NewRouter(Route("NGN", paystack), Route("GHS", flutterwave)).FetchTransfer(ctx, "flutterwave-9")
+	require.NoError(t, err)
+	require.Equal(t, "flutterwave-9", fetched.ID)
+
+	_, err = router.FetchTransfer(ctx, "missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, 1, paystack.calls)
+}
+
+func TestRouterVerifiesUncertainTransfer(t *testing.T) {
+	fake := paystacktest.NewServer("sk_test_router")
+	defer fake.Close()
+	fake.SetBalance("NGN", 1000000)
+	fake.AddBankAccount("058", "0123456789", "ADA OBI")
+	paystack := NewPaystack(fake.Client(paystackx.WithRetryPolicy(paystackx.NoRetry())))
+	flutterwave := newStubGateway(ProviderFlutterwave)
+	router := NewRouter(Route("NGN", paystack, flutterwave))
+	ctx := context.Background()
+
+	// Paystack queues the transfer but the response is lost behind a 503.
+	fake.Fail(paystacktest.Failure{Method: http.MethodPost, Path: "/transfer", ExactPath: true, StatusCode: http.StatusServiceUnavailable, AfterEffect: true, Times: 1})
+	transfer, err := router.Transfer(ctx, &TransferRequest{Amount: moneyx.New(250000, "NGN"), BankCode: "058", AccountNumber: "0123456789", AccountName: "ADA OBI", Reference: "payout-1"})
+	require.NoError(t, err)
+	require.Equal(t, ProviderPaystack, transfer.Provider)
+	require.Equal(t, "payout-1", transfer.Reference)
+	require.Len(t, fake.Transfers(), 1)
+	require.Zero(t, flutterwave.calls)
+
+	// A 503 that left nothing behind is still not sent to Flutterwave.
+	fake.Fail(paystacktest.Failure{Method: http.MethodPost, Path: "/transfer", ExactPath: true, StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, err = router.Transfer(ctx, &TransferRequest{Amount: moneyx.New(250000, "NGN"), BankCode: "058", AccountNumber: "0123456789", AccountName: "ADA OBI", Reference: "payout-2"})
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.Len(t, fake.Transfers(), 1)
+	require.Zero(t, flutterwave.calls)
+}
+
+func TestRouterNeverFallsBackWhenTransferOutcomeUnknown(t *testing.T) {
+	fakeA := paystacktest.NewServer("sk_test_router_a")
+	defer fakeA.Close()
+	fakeB := paystacktest.NewServer("sk_test_router_b")
+	defer fakeB.Close()
+	for _, fake := range []*paystacktest.Server{fakeA, fakeB} {
+		fake.SetBalance("NGN", 1000000)
+		fake.AddBankAccount("058", "0123456789", "ADA OBI")
+	}
+	retry := paystackx.WithRetryPolicy(paystackx.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	router := NewRouter(Route("NGN", NewPaystack(fakeA.Client(retry)), NewPaystack(fakeB.Client(retry))))
+
+	// Provider A makes the transfer but the response is lost, and the lookup
+	// that would settle it is rate limited.
+	fakeA.Fail(paystacktest.Failure{Method: http.MethodPost, Path: "/transfer", ExactPath: true, AfterEffect: true, Drop: true, Times: 1})
+	fakeA.Fail(paystacktest.Failure{Method: http.MethodGet, Path: "/transfer/verify", StatusCode: http.StatusTooManyRequests})
+
+	_, err := router.Transfer(context.Background(), &TransferRequest{Amount: moneyx.New(250000, "NGN"), BankCode: "058", AccountNumber: "0123456789", AccountName: "ADA OBI", Reference: "payout-1"})
+	require.ErrorIs(t, err, ErrOutcomeUnknown)
+	require.ErrorIs(t, err, paystackx.ErrTransferOutcomeUnknown)
+	require.False(t, errors.Is(err, ErrRateLimited))
+	require.Len(t, fakeA.Transfers(), 1)
+	require.Empty(t, fakeB.Transfers())
+}
+
+func TestRouterForgetsOldTransfers(t *testing.T) {
+	paystack := newStubGateway(ProviderPaystack)
+	router := NewRouter(Route("NGN", paystack), RememberTransfers(2))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := router.Transfer(ctx, &TransferRequest{Amount: moneyx.New(100, "NGN"), Reference: fmt.Sprintf("ref-%d", i)})
+		require.NoError(t, err)
+	}
+	require.Len(t, router.transfers, 2)
+	require.NotContains(t, router.transfers, "paystack-1")
+
+	// A forgotten transfer is still found by asking every gateway.
+	fetched, err := router.FetchTransfer(ctx, "paystack-1")
+	require.NoError(t, err)
+	require.Equal(t, "paystack-1", fetched.ID)
+}
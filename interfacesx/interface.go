@@ -3,6 +3,7 @@ package interfacesx
 import (
 	"time"
 
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
 )
@@ -200,18 +201,18 @@ type CreateWalletRequest struct {
 }
 
 type CreateVirtualAccount struct {
-	Email     string  `json:"email"`
-	Amount    float64 `json:"amount"`
-	Narration string  `json:"narration"`
-	TxtRef    string  `json:"tx_ref"`
+	Email     string       `json:"email"`
+	Amount    moneyx.Money `json:"amount"`
+	Narration string       `json:"narration"`
+	TxtRef    string       `json:"tx_ref"`
 }
 
 type CreateTransactionRequest struct {
 	ReferenceID   string                `json:"referenceId"`
-	Amount        float64               `json:"amount"`
+	Amount        moneyx.Money          `json:"amount"`
 	Title         string                `json:"title"`
 	UserID        uuid.UUID             `json:"userId"`
-	ChargedAmount float64               `json:"chargedAmount"`
+	ChargedAmount moneyx.Money          `json:"chargedAmount"`
 	Status        TransactionStatus     `json:"status"`
 	ChargeType    TransactionChargeType `json:"chargeType"`
 	Type          TransactionType       `json:"type"`
@@ -219,8 +220,8 @@ type CreateTransactionRequest struct {
 }
 
 type AwardBonusRequest struct {
-	EmailPhoneOrID string  `json:"emailPhoneOrID" validate:"required"`
-	Amount         float64 `json:"amount" validate:"required"`
+	EmailPhoneOrID string       `json:"emailPhoneOrID" validate:"required"`
+	Amount         moneyx.Money `json:"amount" validate:"required"`
 }
 
 type WebhookPayload struct {
@@ -229,25 +230,28 @@ type WebhookPayload struct {
 	EventType string `json:"event.type"`
 }
 
+// Data is the Flutterwave charge payload. Flutterwave sends amounts as bare
+// major-unit numbers beside a separate currency, so the amounts decode
+// without a currency; call WithCurrency(Currency) before doing arithmetic.
 type Data struct {
-	ID                int       `json:"id"`
-	TxRef             string    `json:"tx_ref"`
-	FlwRef            string    `json:"flw_ref"`
-	DeviceFingerprint string    `json:"device_fingerprint"`
-	Amount            float64   `json:"amount"`
-	Currency          string    `json:"currency"`
-	ChargedAmount     float64   `json:"charged_amount"`
-	AppFee            float64   `json:"app_fee"`
-	MerchantFee       float64   `json:"merchant_fee"`
-	ProcessorResponse string    `json:"processor_response"`
-	AuthModel         string    `json:"auth_model"`
-	IP                string    `json:"ip"`
-	Narration         string    `json:"narration"`
-	Status            string    `json:"status"`
-	PaymentType       string    `json:"payment_type"`
-	CreatedAt         time.Time `json:"created_at"`
-	AccountID         int       `json:"account_id"`
-	Customer          Customer  `json:"customer"`
+	ID                int          `json:"id"`
+	TxRef             string       `json:"tx_ref"`
+	FlwRef            string       `json:"flw_ref"`
+	DeviceFingerprint string       `json:"device_fingerprint"`
+	Amount            moneyx.Money `json:"amount"`
+	Currency          string       `json:"currency"`
+	ChargedAmount     moneyx.Money `json:"charged_amount"`
+	AppFee            moneyx.Money `json:"app_fee"`
+	MerchantFee       moneyx.Money `json:"merchant_fee"`
+	ProcessorResponse string       `json:"processor_response"`
+	AuthModel         string       `json:"auth_model"`
+	IP                string       `json:"ip"`
+	Narration         string       `json:"narration"`
+	Status            string       `json:"status"`
+	PaymentType       string       `json:"payment_type"`
+	CreatedAt         time.Time    `json:"created_at"`
+	AccountID         int          `json:"account_id"`
+	Customer          Customer     `json:"customer"`
 }
 
 type Customer struct {
@@ -259,9 +263,9 @@ type Customer struct {
 }
 
 type UpdateTransactionStatusRequest struct {
-	ChargedAmount float64   `json:"chargedAmount"`
-	TransactionID uuid.UUID `json:"transactionId"`
-	Status        string    `json:"status"`
+	ChargedAmount moneyx.Money `json:"chargedAmount"`
+	TransactionID uuid.UUID    `json:"transactionId"`
+	Status        string       `json:"status"`
 }
 
 type UserBalanceResponse struct {
@@ -321,7 +325,7 @@ type PaginationInfo struct {
 }
 
 type AddFundsToPoolRequest struct {
-	Amount float64 `json:"amount" binding:"required"`
+	Amount moneyx.Money `json:"amount" binding:"required"`
 }
 
 type GamePlayResponse struct {
@@ -440,9 +444,9 @@ type VoucherListResponse struct {
 type Transactions struct {
 	ID            uuid.UUID         `json:"id"`
 	ReferenceID   string            `json:"referenceId"`
-	Amount        float64           `json:"amount"`
+	Amount        moneyx.Money      `json:"amount"`
 	Title         string            `json:"title"`
-	ChargedAmount float64           `json:"chargedAmount"`
+	ChargedAmount moneyx.Money      `json:"chargedAmount"`
 	ChargeType    TransactionType   `json:"chargeType"`
 	Type          TransactionType   `json:"type"`
 	Status        TransactionStatus `json:"status"`
@@ -538,9 +542,9 @@ type WithdrawRequest struct {
 type TransactionResponse struct {
 	ID            uuid.UUID         `json:"id"`
 	ReferenceID   string            `json:"referenceId"`
-	Amount        float64           `json:"amount"`
+	Amount        moneyx.Money      `json:"amount"`
 	Title         string            `json:"title"`
-	ChargedAmount float64           `json:"chargedAmount"`
+	ChargedAmount moneyx.Money      `json:"chargedAmount"`
 	ChargeType    TransactionType   `json:"chargeType"` // Adjust type based on your actual model
 	Type          TransactionType   `json:"type"`       // Adjust type based on your actual model
 	Status        TransactionStatus `json:"status"`     // Adjust type based on your actual model
@@ -631,8 +635,8 @@ type CreateVoucherForNonUserRequest struct {
 }
 type VoucherResponse struct {
 	ID                        uuid.UUID       `json:"id"`
-	Amount                    float64         `json:"amount"`
-	Balance                   float64         `json:"balance"`
+	Amount                    moneyx.Money    `json:"amount"`
+	Balance                   moneyx.Money    `json:"balance"`
 	GeneratedCurrency         CurrencyDetails `json:"generatedCurrency"`
 	Code                      string          `json:"code"`
 	WasPaidFor                bool            `json:"wasPaidFor"`
@@ -646,8 +650,8 @@ type VoucherResponse struct {
 
 type FetchVoucherByIDResponse struct {
 	ID                        uuid.UUID                `json:"id"`
-	Amount                    float64                  `json:"amount"`
-	Balance                   float64                  `json:"balance"`
+	Amount                    moneyx.Money             `json:"amount"`
+	Balance                   moneyx.Money             `json:"balance"`
 	GeneratedCurrency         CurrencyDetails          `json:"generatedCurrency"`
 	Code                      string                   `json:"code"`
 	WasPaidFor                bool                     `json:"wasPaidFor"`
@@ -662,20 +666,20 @@ type FetchVoucherByIDResponse struct {
 }
 
 type RedeemedUserDetails struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	Surname      string    `json:"surname"`
-	OtherNames   string    `json:"otherNames"`
-	Amount       float64   `json:"amount"`
-	Avatar       string    `json:"avatar"`
-	RedeemedDate time.Time `json:"redeemedDate"`
+	ID           uuid.UUID    `json:"id"`
+	Username     string       `json:"username"`
+	Surname      string       `json:"surname"`
+	OtherNames   string       `json:"otherNames"`
+	Amount       moneyx.Money `json:"amount"`
+	Avatar       string       `json:"avatar"`
+	RedeemedDate time.Time    `json:"redeemedDate"`
 }
 
 type RedeemdBusinessDetails struct {
 	ID           uuid.UUID           `json:"id"`
 	BusinessName string              `json:"businessName"`
 	TradingName  string              `json:"tradingName"`
-	Amount       float64             `json:"amount"`
+	Amount       moneyx.Money        `json:"amount"`
 	Logo         string              `json:"logo"`
 	RedeemedDate time.Time           `json:"redeemedDate"`
 	RedeemedBy   RedeemedUserDetails `json:"redeemedBy"`
@@ -794,9 +798,9 @@ type HomeFeed struct {
 type FeedTransactionResponse struct {
 	ID            uuid.UUID         `json:"id"`
 	ReferenceID   string            `json:"referenceId"`
-	Amount        float64           `json:"amount"`
+	Amount        moneyx.Money      `json:"amount"`
 	Title         string            `json:"title"`
-	ChargedAmount float64           `json:"chargedAmount"`
+	ChargedAmount moneyx.Money      `json:"chargedAmount"`
 	ChargeType    TransactionType   `json:"chargeType"` // Adjust type based on your actual model
 	Type          TransactionType   `json:"type"`       // Adjust type based on your actual model
 	Status        TransactionStatus `json:"status"`     // Adjust type based on your actual model
@@ -0,0 +1,83 @@
+package moneyx
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSON writes the amount as a number in major units, for example
+// 1500.50, so payloads keep the shape they had when amounts were float64. The
+// currency stays in the sibling field those payloads already carry. An amount
+// decoded without a currency is written back with the digits it was read with.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.currency == "" && m.exact != "" {
+		return []byte(m.exact), nil
+	}
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON reads a number or numeric string in major units without going
+// through float64. The currency is kept if m already has one; otherwise the
+// digits are kept so that WithCurrency can apply the exponent of the currency
+// once it is known.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	amount := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+	} else if !json.Valid(data) {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	return m.setAmount(amount)
+}
+
+// setAmount reads amount in major units into m, keeping its currency.
+func (m *Money) setAmount(amount string) error {
+	minor, err := parseMinor(amount, m.Exponent())
+	if err != nil {
+		return err
+	}
+	m.amount = minor
+	m.exact = ""
+	if m.currency == "" {
+		m.exact = strings.TrimSpace(amount)
+	}
+	return nil
+}
+
+// Value stores the amount in major units so existing numeric columns keep working.
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan reads a numeric column in major units.
+func (m *Money) Scan(src interface{}) error {
+	var amount string
+	switch v := src.(type) {
+	case nil:
+		*m = Money{currency: m.currency}
+		return nil
+	case int64:
+		amount = strconv.FormatInt(v, 10)
+	case float64:
+		amount = strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		amount = string(v)
+	case string:
+		amount = v
+	default:
+		return fmt.Errorf("cannot scan %T into moneyx.Money", src)
+	}
+
+	return m.setAmount(amount)
+}
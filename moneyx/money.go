@@ -0,0 +1,383 @@
+// Package moneyx represents amounts of money as an integer number of minor
+// units (kobo, pesewas, cents) together with an ISO 4217 currency code, so
+// amounts can be added, split and allocated without float rounding.
+package moneyx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflows int64 minor units")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// DefaultExponent is the number of minor-unit digits used for currencies that
+// are not registered, and for amounts decoded before their currency is known.
+const DefaultExponent = 2
+
+var (
+	exponentsMu sync.RWMutex
+	exponents   = map[string]int{
+		"NGN": 2, "GHS": 2, "KES": 2, "ZAR": 2, "UGX": 0, "TZS": 2, "RWF": 0,
+		"XOF": 0, "XAF": 0, "EGP": 2, "USD": 2, "EUR": 2, "GBP": 2, "CAD": 2,
+		"JPY": 0, "KWD": 3, "BHD": 3,
+	}
+)
+
+// RegisterCurrency sets the number of minor-unit digits for a currency code.
+func RegisterCurrency(code string, exponent int) {
+	exponentsMu.Lock()
+	defer exponentsMu.Unlock()
+	exponents[strings.ToUpper(code)] = exponent
+}
+
+// Exponent returns the number of minor-unit digits for a currency code.
+func Exponent(code string) int {
+	exponentsMu.RLock()
+	defer exponentsMu.RUnlock()
+	if exponent, ok := exponents[strings.ToUpper(code)]; ok {
+		return exponent
+	}
+	return DefaultExponent
+}
+
+// Money is an amount in the minor unit of its currency. The zero value is
+// zero in no currency; it can be added to or compared with any currency.
+type Money struct {
+	amount   int64
+	currency string
+	// exact is the decimal an amount without a currency was read from, so
+	// WithCurrency can apply the currency's exponent without losing digits.
+	exact string
+}
+
+// New returns minor units of currency, for example New(150050, "NGN") is ₦1,500.50.
+func New(minor int64, currency string) Money {
+	return Money{amount: minor, currency: strings.ToUpper(currency)}
+}
+
+// Zero returns zero in currency.
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount in major units such as "1500.50". Digits
+// beyond the currency's exponent are rounded half away from zero.
+func Parse(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	minor, err := parseMinor(amount, Exponent(currency))
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: minor, currency: currency}, nil
+}
+
+// FromMajor converts a float amount in major units, rounding to the nearest
+// minor unit. Use it only at the boundary with code that still uses floats.
+func FromMajor(major float64, currency string) (Money, error) {
+	if math.IsNaN(major) || math.IsInf(major, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	return Parse(strconv.FormatFloat(major, 'f', -1, 64), currency)
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants and tests.
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func parseMinor(amount string, exponent int) (int64, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)))
+	minor := roundHalfAwayFromZero(value)
+	if !minor.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return minor.Int64(), nil
+}
+
+func roundHalfAwayFromZero(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	// Round away from zero when the remainder is at least half the denominator.
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		if value.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.amount
+}
+
+// Currency returns the ISO 4217 code, or "" if the currency is not known yet.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Exponent returns the number of minor-unit digits of the currency.
+func (m Money) Exponent() int {
+	return Exponent(m.currency)
+}
+
+// WithCurrency sets the currency of an amount decoded without one. Amounts
+// that already have a different currency are not converted.
+func (m Money) WithCurrency(currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if m.currency == currency {
+		return m, nil
+	}
+	if m.currency != "" {
+		return Money{}, fmt.Errorf("%w: %s is not %s", ErrCurrencyMismatch, m.currency, currency)
+	}
+	if m.exact != "" {
+		minor, err := parseMinor(m.exact, Exponent(currency))
+		if err != nil {
+			return Money{}, err
+		}
+		return Money{amount: minor, currency: currency}, nil
+	}
+	// Amounts without a currency use DefaultExponent; rescale to the currency's.
+	if exponent := Exponent(currency); exponent != DefaultExponent {
+		minor, err := parseMinor(formatMinor(m.amount, DefaultExponent), exponent)
+		if err != nil {
+			return Money{}, err
+		}
+		return Money{amount: minor, currency: currency}, nil
+	}
+	return Money{amount: m.amount, currency: currency}, nil
+}
+
+// Float64 returns the amount in major units. It is lossy and meant only for
+// code that still uses floats.
+func (m Money) Float64() float64 {
+	value, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return value
+}
+
+// Decimal formats the amount in major units with the currency's exponent,
+// for example "1500.50".
+func (m Money) Decimal() string {
+	return formatMinor(m.amount, m.Exponent())
+}
+
+func formatMinor(minor int64, exponent int) string {
+	digits := new(big.Int).Abs(big.NewInt(minor)).String()
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String formats the amount with its currency, for example "1500.50 NGN".
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.currency
+}
+
+func (m Money) IsZero() bool     { return m.amount == 0 }
+func (m Money) IsPositive() bool { return m.amount > 0 }
+func (m Money) IsNegative() bool { return m.amount < 0 }
+
+// sameCurrency returns the currency of the result of combining m and other.
+func (m Money) sameCurrency(other Money) (string, error) {
+	switch {
+	case m.currency == other.currency:
+		return m.currency, nil
+	case m.currency == "" && m.amount == 0:
+		return other.currency, nil
+	case other.currency == "" && other.amount == 0:
+		return m.currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.sameCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.amount + other.amount
+	if (other.amount > 0 && sum < m.amount) || (other.amount < 0 && sum > m.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: sum, currency: currency}, nil
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(other.Negate())
+}
+
+// Multiply returns m * n.
+func (m Money) Multiply(n int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(n))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: product.Int64(), currency: m.currency}, nil
+}
+
+// Percent returns percent% of m, rounded half away from zero, for example a
+// 1.5% fee. The percentage is parsed as a decimal string to avoid float error.
+func (m Money) Percent(percent string) (Money, error) {
+	rate, ok := new(big.Rat).SetString(percent)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: percentage %q", ErrInvalidAmount, percent)
+	}
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.amount), rate)
+	value.Quo(value, big.NewRat(100, 1))
+	result := roundHalfAwayFromZero(value)
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: result.Int64(), currency: m.currency}, nil
+}
+
+// Negate returns -m.
+func (m Money) Negate() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Abs returns |m|.
+func (m Money) Abs() Money {
+	if m.amount < 0 {
+		return m.Negate()
+	}
+	return m
+}
+
+// Compare returns -1, 0 or 1 as m is less than, equal to or greater than other.
+func (m Money) Compare(other Money) (int, error) {
+	if _, err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal reports whether m and other are the same amount in the same currency.
+func (m Money) Equal(other Money) bool {
+	compare, err := m.Compare(other)
+	return err == nil && compare == 0
+}
+
+// Allocate splits m in proportion to ratios. The parts always add up to m:
+// minor units left over from rounding go one each to the parts with the
+// largest remainders, earliest first.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidAmount)
+	}
+
+	total := new(big.Int)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidAmount, ratio)
+		}
+		total.Add(total, big.NewInt(ratio))
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("%w: ratios add up to zero", ErrInvalidAmount)
+	}
+
+	sign := int64(1)
+	amount := big.NewInt(m.amount)
+	if m.amount < 0 {
+		sign = -1
+		amount.Neg(amount)
+	}
+
+	parts := make([]Money, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	allocated := new(big.Int)
+	for i, ratio := range ratios {
+		share, remainder := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(ratio)), total, new(big.Int))
+		parts[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainders[i] = remainder
+		allocated.Add(allocated, share)
+	}
+
+	leftover := new(big.Int).Sub(amount, allocated).Int64()
+	for ; leftover > 0; leftover-- {
+		best := -1
+		for i, remainder := range remainders {
+			if ratios[i] == 0 {
+				continue
+			}
+			if best == -1 || remainder.Cmp(remainders[best]) > 0 {
+				best = i
+			}
+		}
+		parts[best].amount++
+		remainders[best] = new(big.Int)
+	}
+
+	for i := range parts {
+		parts[i].amount *= sign
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit and add
+// up to m.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split into %d parts", ErrInvalidAmount, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Sum adds amounts of the same currency.
+func Sum(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
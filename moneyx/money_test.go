@@ -0,0 +1,177 @@
+package moneyx
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndFormat(t *testing.T) {
+	m, err := Parse("1500.5", "ngn")
+	require.NoError(t, err)
+	require.Equal(t, int64(150050), m.Minor())
+	require.Equal(t, "NGN", m.Currency())
+	require.Equal(t, "1500.50 NGN", m.String())
+
+	m, err = Parse("1.005", "NGN")
+	require.NoError(t, err)
+	require.Equal(t, int64(101), m.Minor())
+
+	m, err = Parse("-0.125", "KWD")
+	require.NoError(t, err)
+	require.Equal(t, "-0.125 KWD", m.String())
+
+	m, err = FromMajor(0.1+0.2, "USD")
+	require.NoError(t, err)
+	require.Equal(t, int64(30), m.Minor())
+
+	require.Equal(t, "500", New(500, "UGX").Decimal())
+	require.Equal(t, "0.05", New(5, "NGN").Decimal())
+
+	_, err = Parse("abc", "NGN")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = Parse("100000000000000000000", "NGN")
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestArithmetic(t *testing.T) {
+	a := New(1050, "NGN")
+	b := New(250, "NGN")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, New(1300, "NGN"), sum)
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	require.True(t, diff.IsNegative())
+	require.Equal(t, int64(800), diff.Abs().Minor())
+
+	product, err := a.Multiply(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(3150), product.Minor())
+
+	fee, err := New(1000000, "NGN").Percent("1.5")
+	require.NoError(t, err)
+	require.Equal(t, int64(15000), fee.Minor())
+
+	_, err = a.Add(New(1, "GHS"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	var zero Money
+	sum, err = zero.Add(a)
+	require.NoError(t, err)
+	require.Equal(t, a, sum)
+
+	_, err = New(9223372036854775807, "NGN").Add(New(1, "NGN"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	cmp, err := a.Compare(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+	require.False(t, a.Equal(New(1050, "GHS")))
+
+	total, err := Sum(a, b, New(1, "NGN"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1301), total.Minor())
+}
+
+func TestAllocateNeverLosesMinorUnits(t *testing.T) {
+	parts, err := New(100, "NGN").Split(3)
+	require.NoError(t, err)
+	require.Equal(t, []Money{New(34, "NGN"), New(33, "NGN"), New(33, "NGN")}, parts)
+
+	parts, err = New(-100, "NGN").Split(3)
+	require.NoError(t, err)
+	require.Equal(t, []Money{New(-34, "NGN"), New(-33, "NGN"), New(-33, "NGN")}, parts)
+
+	// A 70/20/10 revenue split of ₦0.05.
+	parts, err = New(5, "NGN").Allocate(70, 20, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Money{New(4, "NGN"), New(1, "NGN"), New(0, "NGN")}, parts)
+
+	parts, err = New(1001, "NGN").Allocate(1, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), parts[1].Minor())
+
+	for _, amount := range []int64{1, 7, 99, 100, 12345, 9999999} {
+		for _, ratios := range [][]int64{{1, 1, 1}, {3, 7}, {1, 2, 3, 4, 5, 6}, {97, 2, 1}} {
+			parts, err := New(amount, "NGN").Allocate(ratios...)
+			require.NoError(t, err)
+			total, err := Sum(parts...)
+			require.NoError(t, err)
+			require.Equal(t, amount, total.Minor())
+		}
+	}
+
+	_, err = New(100, "NGN").Allocate()
+	require.Error(t, err)
+	_, err = New(100, "NGN").Allocate(0, 0)
+	require.Error(t, err)
+	_, err = New(100, "NGN").Split(0)
+	require.Error(t, err)
+}
+
+type payload struct {
+	Amount  Money  `json:"amount"`
+	Balance *Money `json:"balance,omitempty"`
+}
+
+func TestJSONMatchesFloatPayloads(t *testing.T) {
+	data, err := json.Marshal(payload{Amount: New(150050, "NGN")})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":1500.50}`, string(data))
+
+	var legacy struct {
+		Amount float64 `json:"amount"`
+	}
+	require.NoError(t, json.Unmarshal(data, &legacy))
+	require.Equal(t, 1500.5, legacy.Amount)
+
+	data, err = json.Marshal(New(1234, "KWD"))
+	require.NoError(t, err)
+	require.Equal(t, "1.234", string(data))
+
+	var decoded payload
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":0.3,"balance":"12.345"}`), &decoded))
+	require.Equal(t, int64(30), decoded.Amount.Minor())
+	require.Equal(t, int64(1235), decoded.Balance.Minor())
+
+	withCurrency, err := decoded.Amount.WithCurrency("NGN")
+	require.NoError(t, err)
+	require.Equal(t, New(30, "NGN"), withCurrency)
+
+	// Bare amounts keep their digits until the currency is known.
+	kwdBalance, err := decoded.Balance.WithCurrency("KWD")
+	require.NoError(t, err)
+	require.Equal(t, New(12345, "KWD"), kwdBalance)
+	data, err = json.Marshal(decoded.Balance)
+	require.NoError(t, err)
+	require.Equal(t, "12.345", string(data))
+
+	rescaled, err := New(150000, "").WithCurrency("UGX")
+	require.NoError(t, err)
+	require.Equal(t, New(1500, "UGX"), rescaled)
+
+	_, err = withCurrency.WithCurrency("GHS")
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	preset := Zero("KWD")
+	require.NoError(t, json.Unmarshal([]byte(`1.2345`), &preset))
+	require.Equal(t, int64(1235), preset.Minor())
+
+	require.Error(t, json.Unmarshal([]byte(`{"amount":true}`), &decoded))
+}
+
+func TestScan(t *testing.T) {
+	var m Money
+	require.NoError(t, m.Scan([]byte("1500.50")))
+	require.Equal(t, int64(150050), m.Minor())
+	require.NoError(t, m.Scan(int64(3)))
+	require.Equal(t, int64(300), m.Minor())
+
+	value, err := New(150050, "NGN").Value()
+	require.NoError(t, err)
+	require.Equal(t, "1500.50", value)
+}
@@ -2,60 +2,338 @@ package paystackx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
-
-	"github.com/sirupsen/logrus"
 )
 
-type PaystackService interface {
-	CreateVirtualAccount(customerID *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error)
+// CustomerService creates and updates Paystack customers.
+type CustomerService interface {
 	CreateUser(data PaystackCreateUserRequest) (*CreateUserResponse, error)
+	CreateUserWithContext(ctx context.Context, data PaystackCreateUserRequest) (*CreateUserResponse, error)
 	UpdateUser(data PaystackUpdateUserRequest, pastackCode string) error
+	UpdateUserWithContext(ctx context.Context, data PaystackUpdateUserRequest, pastackCode string) error
+	ValidateCustomer(customerCode string, data *ValidateCustomerRequest) (*MessageResponse, error)
+	ValidateCustomerWithContext(ctx context.Context, customerCode string, data *ValidateCustomerRequest) (*MessageResponse, error)
+}
+
+// DedicatedAccountService manages dedicated virtual accounts.
+type DedicatedAccountService interface {
+	CreateVirtualAccount(customerID *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error)
+	CreateVirtualAccountWithContext(ctx context.Context, customerID *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error)
+	AssignDedicatedAccount(data *AssignDedicatedAccountRequest) (*MessageResponse, error)
+	AssignDedicatedAccountWithContext(ctx context.Context, data *AssignDedicatedAccountRequest) (*MessageResponse, error)
+	ListDedicatedAccounts(filter *ListDedicatedAccountsRequest) (*ListDedicatedAccountsResponse, error)
+	ListDedicatedAccountsWithContext(ctx context.Context, filter *ListDedicatedAccountsRequest) (*ListDedicatedAccountsResponse, error)
+	FetchDedicatedAccount(id int) (*VirtualAccountResponse, error)
+	FetchDedicatedAccountWithContext(ctx context.Context, id int) (*VirtualAccountResponse, error)
+	RequeryDedicatedAccount(data *RequeryDedicatedAccountRequest) (*MessageResponse, error)
+	RequeryDedicatedAccountWithContext(ctx context.Context, data *RequeryDedicatedAccountRequest) (*MessageResponse, error)
+	DeactivateDedicatedAccount(id int) (*VirtualAccountResponse, error)
+	DeactivateDedicatedAccountWithContext(ctx context.Context, id int) (*VirtualAccountResponse, error)
+	SplitDedicatedAccount(data *SplitDedicatedAccountRequest) (*VirtualAccountResponse, error)
+	SplitDedicatedAccountWithContext(ctx context.Context, data *SplitDedicatedAccountRequest) (*VirtualAccountResponse, error)
+	RemoveDedicatedAccountSplit(accountNumber string) (*VirtualAccountResponse, error)
+	RemoveDedicatedAccountSplitWithContext(ctx context.Context, accountNumber string) (*VirtualAccountResponse, error)
+	ListDedicatedAccountProviders() (*DedicatedAccountProvidersResponse, error)
+	ListDedicatedAccountProvidersWithContext(ctx context.Context) (*DedicatedAccountProvidersResponse, error)
+}
+
+// TransactionService initializes, charges and looks up transactions.
+type TransactionService interface {
+	InitializeTransaction(data *InitializeTransactionRequest) (*InitializeTransactionResponse, error)
+	InitializeTransactionWithContext(ctx context.Context, data *InitializeTransactionRequest) (*InitializeTransactionResponse, error)
+	VerifyTransaction(reference string) (*TransactionResponse, error)
+	VerifyTransactionWithContext(ctx context.Context, reference string) (*TransactionResponse, error)
+	ChargeAuthorization(data *ChargeAuthorizationRequest) (*TransactionResponse, error)
+	ChargeAuthorizationWithContext(ctx context.Context, data *ChargeAuthorizationRequest) (*TransactionResponse, error)
+	ListTransactions(filter *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	ListTransactionsWithContext(ctx context.Context, filter *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	FetchTransaction(id int) (*TransactionResponse, error)
+	FetchTransactionWithContext(ctx context.Context, id int) (*TransactionResponse, error)
+}
+
+// RecipientService manages transfer recipients.
+type RecipientService interface {
 	CreateTransferRecipient(data *PaystackCreateTransferRecipientRequest) (*CreateTransferRecipientResponse, error)
+	CreateTransferRecipientWithContext(ctx context.Context, data *PaystackCreateTransferRecipientRequest) (*CreateTransferRecipientResponse, error)
+	CreateBulkTransferRecipients(data *BulkTransferRecipientRequest) (*BulkTransferRecipientResponse, error)
+	CreateBulkTransferRecipientsWithContext(ctx context.Context, data *BulkTransferRecipientRequest) (*BulkTransferRecipientResponse, error)
+	ListTransferRecipients(filter *ListTransferRecipientsRequest) (*ListTransferRecipientsResponse, error)
+	ListTransferRecipientsWithContext(ctx context.Context, filter *ListTransferRecipientsRequest) (*ListTransferRecipientsResponse, error)
+	FetchTransferRecipient(idOrCode string) (*TransferRecipientResponse, error)
+	FetchTransferRecipientWithContext(ctx context.Context, idOrCode string) (*TransferRecipientResponse, error)
+	UpdateTransferRecipient(idOrCode string, data *UpdateTransferRecipientRequest) (*MessageResponse, error)
+	UpdateTransferRecipientWithContext(ctx context.Context, idOrCode string, data *UpdateTransferRecipientRequest) (*MessageResponse, error)
+	DeleteTransferRecipient(idOrCode string) (*MessageResponse, error)
+	DeleteTransferRecipientWithContext(ctx context.Context, idOrCode string) (*MessageResponse, error)
+	CreateNairaRecipient(accountNumber, bankCode string) (*interfacesx.NairaRecipient, error)
+	CreateNairaRecipientWithContext(ctx context.Context, accountNumber, bankCode string) (*interfacesx.NairaRecipient, error)
+}
+
+// TransferService sends transfers and manages the transfer OTP setting.
+type TransferService interface {
 	InitiateTransfer(data *TransferFundsRequest) (*TransferOTPResponse, error)
+	InitiateTransferWithContext(ctx context.Context, data *TransferFundsRequest) (*TransferOTPResponse, error)
+	FinalizeTransfer(data *FinalizeTransferRequest) (*TransferOTPResponse, error)
+	FinalizeTransferWithContext(ctx context.Context, data *FinalizeTransferRequest) (*TransferOTPResponse, error)
+	ResendTransferOTP(data *ResendTransferOTPRequest) (*MessageResponse, error)
+	ResendTransferOTPWithContext(ctx context.Context, data *ResendTransferOTPRequest) (*MessageResponse, error)
+	DisableTransferOTP() (*MessageResponse, error)
+	DisableTransferOTPWithContext(ctx context.Context) (*MessageResponse, error)
+	FinalizeDisableTransferOTP(otp string) (*MessageResponse, error)
+	FinalizeDisableTransferOTPWithContext(ctx context.Context, otp string) (*MessageResponse, error)
+	EnableTransferOTP() (*MessageResponse, error)
+	EnableTransferOTPWithContext(ctx context.Context) (*MessageResponse, error)
+	VerifyTransfer(reference string) (*TransferResponse, error)
+	VerifyTransferWithContext(ctx context.Context, reference string) (*TransferResponse, error)
+	FetchTransfer(idOrCode string) (*TransferResponse, error)
+	FetchTransferWithContext(ctx context.Context, idOrCode string) (*TransferResponse, error)
+	ListTransfers(filter *ListTransfersRequest) (*ListTransfersResponse, error)
+	ListTransfersWithContext(ctx context.Context, filter *ListTransfersRequest) (*ListTransfersResponse, error)
+	InitiateBulkTransfer(data *BulkTransferRequest) (*BulkTransferResponse, error)
+	InitiateBulkTransferWithContext(ctx context.Context, data *BulkTransferRequest) (*BulkTransferResponse, error)
+}
+
+// BalanceService reads the integration balance and its ledger.
+type BalanceService interface {
 	FetchBalance() (*BalanceResponse, error)
+	FetchBalanceWithContext(ctx context.Context) (*BalanceResponse, error)
+	ListBalanceLedger(filter *ListBalanceLedgerRequest) (*ListBalanceLedgerResponse, error)
+	ListBalanceLedgerWithContext(ctx context.Context, filter *ListBalanceLedgerRequest) (*ListBalanceLedgerResponse, error)
+}
+
+// SettlementService lists settlements and the transactions in them.
+type SettlementService interface {
+	ListSettlements(filter *ListSettlementsRequest) (*ListSettlementsResponse, error)
+	ListSettlementsWithContext(ctx context.Context, filter *ListSettlementsRequest) (*ListSettlementsResponse, error)
+	ListSettlementTransactions(settlementID int, filter *ListSettlementTransactionsRequest) (*ListTransactionsResponse, error)
+	ListSettlementTransactionsWithContext(ctx context.Context, settlementID int, filter *ListSettlementTransactionsRequest) (*ListTransactionsResponse, error)
+}
+
+// BankService lists banks and resolves account numbers.
+type BankService interface {
 	FetchBanks() (*BanksResponse, error)
+	FetchBanksWithContext(ctx context.Context) (*BanksResponse, error)
+	ListBanks(query BankQuery) (*BanksResponse, error)
+	ListBanksWithContext(ctx context.Context, query BankQuery) (*BanksResponse, error)
 	GetBankByPrefix(prefix string) (*BanksResponse, error)
+	GetBankByPrefixWithContext(ctx context.Context, prefix string) (*BanksResponse, error)
 	GetBankNameByCode(bankCode string) (string, error)
+	GetBankNameByCodeWithContext(ctx context.Context, bankCode string) (string, error)
+	GetBankByPrefixForCurrency(currency, prefix string) (*BanksResponse, error)
+	GetBankByPrefixForCurrencyWithContext(ctx context.Context, currency, prefix string) (*BanksResponse, error)
+	GetBankNameByCodeForCurrency(currency, bankCode string) (string, error)
+	GetBankNameByCodeForCurrencyWithContext(ctx context.Context, currency, bankCode string) (string, error)
 	ResolveAccountNumber(account *interfacesx.ResolveBankAccountRequest) (*AccountResponse, error)
+	ResolveAccountNumberWithContext(ctx context.Context, account *interfacesx.ResolveBankAccountRequest) (*AccountResponse, error)
 }
 
+// SubscriptionService manages plans and subscriptions.
+type SubscriptionService interface {
+	CreatePlan(data *CreatePlanRequest) (*PlanResponse, error)
+	CreatePlanWithContext(ctx context.Context, data *CreatePlanRequest) (*PlanResponse, error)
+	ListPlans(filter *ListPlansRequest) (*ListPlansResponse, error)
+	ListPlansWithContext(ctx context.Context, filter *ListPlansRequest) (*ListPlansResponse, error)
+	FetchPlan(idOrCode string) (*PlanResponse, error)
+	FetchPlanWithContext(ctx context.Context, idOrCode string) (*PlanResponse, error)
+	UpdatePlan(idOrCode string, data *UpdatePlanRequest) (*MessageResponse, error)
+	UpdatePlanWithContext(ctx context.Context, idOrCode string, data *UpdatePlanRequest) (*MessageResponse, error)
+	CreateSubscription(data *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error)
+	CreateSubscriptionWithContext(ctx context.Context, data *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error)
+	ListSubscriptions(filter *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error)
+	ListSubscriptionsWithContext(ctx context.Context, filter *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error)
+	FetchSubscription(idOrCode string) (*SubscriptionResponse, error)
+	FetchSubscriptionWithContext(ctx context.Context, idOrCode string) (*SubscriptionResponse, error)
+	EnableSubscription(code, token string) (*MessageResponse, error)
+	EnableSubscriptionWithContext(ctx context.Context, code, token string) (*MessageResponse, error)
+	DisableSubscription(code, token string) (*MessageResponse, error)
+	DisableSubscriptionWithContext(ctx context.Context, code, token string) (*MessageResponse, error)
+}
+
+// RefundService creates and looks up refunds.
+type RefundService interface {
+	CreateRefund(data *CreateRefundRequest) (*RefundResponse, error)
+	CreateRefundWithContext(ctx context.Context, data *CreateRefundRequest) (*RefundResponse, error)
+	ListRefunds(filter *ListRefundsRequest) (*ListRefundsResponse, error)
+	ListRefundsWithContext(ctx context.Context, filter *ListRefundsRequest) (*ListRefundsResponse, error)
+	FetchRefund(id int) (*RefundResponse, error)
+	FetchRefundWithContext(ctx context.Context, id int) (*RefundResponse, error)
+}
+
+// DisputeService handles chargeback disputes.
+type DisputeService interface {
+	ListDisputes(filter *ListDisputesRequest) (*ListDisputesResponse, error)
+	ListDisputesWithContext(ctx context.Context, filter *ListDisputesRequest) (*ListDisputesResponse, error)
+	FetchDispute(id int) (*DisputeResponse, error)
+	FetchDisputeWithContext(ctx context.Context, id int) (*DisputeResponse, error)
+	UploadEvidence(id int, data *UploadEvidenceRequest) (*DisputeEvidenceResponse, error)
+	UploadEvidenceWithContext(ctx context.Context, id int, data *UploadEvidenceRequest) (*DisputeEvidenceResponse, error)
+	DisputeUploadURL(id int, filename string) (*DisputeUploadURLResponse, error)
+	DisputeUploadURLWithContext(ctx context.Context, id int, filename string) (*DisputeUploadURLResponse, error)
+	ResolveDispute(id int, data *ResolveDisputeRequest) (*DisputeResponse, error)
+	ResolveDisputeWithContext(ctx context.Context, id int, data *ResolveDisputeRequest) (*DisputeResponse, error)
+}
+
+// SubaccountService manages subaccounts and transaction splits.
+type SubaccountService interface {
+	CreateSubaccount(data *CreateSubaccountRequest) (*SubaccountResponse, error)
+	CreateSubaccountWithContext(ctx context.Context, data *CreateSubaccountRequest) (*SubaccountResponse, error)
+	ListSubaccounts(filter *ListSubaccountsRequest) (*ListSubaccountsResponse, error)
+	ListSubaccountsWithContext(ctx context.Context, filter *ListSubaccountsRequest) (*ListSubaccountsResponse, error)
+	FetchSubaccount(idOrCode string) (*SubaccountResponse, error)
+	FetchSubaccountWithContext(ctx context.Context, idOrCode string) (*SubaccountResponse, error)
+	UpdateSubaccount(idOrCode string, data *UpdateSubaccountRequest) (*SubaccountResponse, error)
+	UpdateSubaccountWithContext(ctx context.Context, idOrCode string, data *UpdateSubaccountRequest) (*SubaccountResponse, error)
+	CreateSplit(data *CreateSplitRequest) (*SplitResponse, error)
+	CreateSplitWithContext(ctx context.Context, data *CreateSplitRequest) (*SplitResponse, error)
+	ListSplits(filter *ListSplitsRequest) (*ListSplitsResponse, error)
+	ListSplitsWithContext(ctx context.Context, filter *ListSplitsRequest) (*ListSplitsResponse, error)
+	FetchSplit(id int) (*SplitResponse, error)
+	FetchSplitWithContext(ctx context.Context, id int) (*SplitResponse, error)
+	UpdateSplit(id int, data *UpdateSplitRequest) (*SplitResponse, error)
+	UpdateSplitWithContext(ctx context.Context, id int, data *UpdateSplitRequest) (*SplitResponse, error)
+	AddSplitSubaccount(id int, data *SplitSubaccount) (*SplitResponse, error)
+	AddSplitSubaccountWithContext(ctx context.Context, id int, data *SplitSubaccount) (*SplitResponse, error)
+	RemoveSplitSubaccount(id int, subaccountCode string) (*MessageResponse, error)
+	RemoveSplitSubaccountWithContext(ctx context.Context, id int, subaccountCode string) (*MessageResponse, error)
+}
+
+// PaystackService is the full Paystack client. Every method has a
+// WithContext variant; the plain method calls it with context.Background().
+// Code that needs only part of the API should accept the narrower interface.
+type PaystackService interface {
+	CustomerService
+	DedicatedAccountService
+	TransactionService
+	RecipientService
+	TransferService
+	BalanceService
+	SettlementService
+	BankService
+	SubscriptionService
+	RefundService
+	DisputeService
+	SubaccountService
+}
+
+// DefaultTimeout bounds every request unless WithTimeout or WithHTTPClient is used.
+const DefaultTimeout = 30 * time.Second
+
 type paystackClient struct {
-	baseURL   string
-	secretKey string
-	client    *http.Client
+	baseURL      string
+	secretKey    string
+	client       *http.Client
+	timeout      time.Duration
+	retryPolicy  RetryPolicy
+	bankCacheTTL time.Duration
+	bankQuery    BankQuery
+	banks        map[BankQuery]*BankDirectory
+	banksMu      sync.Mutex
+	limiter      *RateLimiter
 }
 
-func NewPaystackClient(baseUrl, secretKey string) PaystackService {
+// ClientOption configures the client returned by NewPaystackClient.
+type ClientOption func(*paystackClient)
 
-	return &paystackClient{
-		baseURL:   baseUrl,
-		secretKey: secretKey,
-		client:    &http.Client{},
+// WithTimeout sets the timeout for each request. It applies to a copy of the
+// client given to WithHTTPClient, whatever the order of the options, so a
+// shared client is left as it was.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(p *paystackClient) {
+		p.timeout = timeout
 	}
 }
 
-func (p *paystackClient) CreateVirtualAccount(customer *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error) {
-	response, err := p.makeRequest("POST", "dedicated_account", customer)
+// WithBankCacheTTL sets how long the bank lookups reuse a bank list. It
+// defaults to DefaultBankCacheTTL.
+func WithBankCacheTTL(ttl time.Duration) ClientOption {
+	return func(p *paystackClient) {
+		p.bankCacheTTL = ttl
+	}
+}
+
+// WithBankQuery sets the bank list GetBankByPrefix and GetBankNameByCode
+// search. It defaults to the Nigerian list; the ForCurrency variants pick the
+// list by currency instead.
+func WithBankQuery(query BankQuery) ClientOption {
+	return func(p *paystackClient) {
+		p.bankQuery = query
+	}
+}
+
+// WithHTTPClient replaces the HTTP client, for example to add a transport.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(p *paystackClient) {
+		p.client = client
+	}
+}
+
+func NewPaystackClient(baseUrl, secretKey string, opts ...ClientOption) PaystackService {
+	p := &paystackClient{
+		baseURL:      strings.TrimSuffix(baseUrl, "/"),
+		secretKey:    secretKey,
+		client:       &http.Client{Timeout: DefaultTimeout},
+		retryPolicy:  DefaultRetryPolicy(),
+		bankCacheTTL: DefaultBankCacheTTL,
+		bankQuery:    BankQuery{Country: CountryNigeria},
+		banks:        make(map[BankQuery]*BankDirectory),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.timeout > 0 {
+		client := *p.client
+		client.Timeout = p.timeout
+		p.client = &client
+	}
+	return p
+}
+
+// bankDirectory returns the cached directory for query, creating it on first use.
+func (p *paystackClient) bankDirectory(query BankQuery) *BankDirectory {
+	p.banksMu.Lock()
+	defer p.banksMu.Unlock()
+	directory, ok := p.banks[query]
+	if !ok {
+		directory = NewBankDirectory(p, query, p.bankCacheTTL)
+		p.banks[query] = directory
+	}
+	return directory
+}
+
+// bankDirectoryForCurrency returns the directory of the country that uses currency.
+func (p *paystackClient) bankDirectoryForCurrency(currency string) (*BankDirectory, error) {
+	query, err := BankQueryForCurrency(currency)
 	if err != nil {
 		return nil, err
 	}
+	return p.bankDirectory(query), nil
+}
 
-	var virtualAccountResponse VirtualAccountResponse
+func (p *paystackClient) CreateVirtualAccount(customer *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error) {
+	return p.CreateVirtualAccountWithContext(context.Background(), customer)
+}
 
-	if err := json.NewDecoder(response.Body).Decode(&virtualAccountResponse); err != nil {
+func (p *paystackClient) CreateVirtualAccountWithContext(ctx context.Context, customer *interfacesx.CreatePaystackVirtualAccountRequest) (*VirtualAccountResponse, error) {
+	var virtualAccountResponse VirtualAccountResponse
+	if err := p.doJSON(ctx, "POST", "dedicated_account", customer, &virtualAccountResponse); err != nil {
 		return nil, err
 	}
 
 	return &virtualAccountResponse, nil
 }
 
-func (p *paystackClient) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
+func (p *paystackClient) makeRequest(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
 	url := fmt.Sprintf("%s/%s", p.baseURL, endpoint)
 	var requestBody []byte
 	var err error
@@ -67,7 +345,7 @@ func (p *paystackClient) makeRequest(method, endpoint string, body interface{})
 		}
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, err
 	}
@@ -83,14 +361,72 @@ func (p *paystackClient) makeRequest(method, endpoint string, body interface{})
 	return resp, nil
 }
 
-func (p *paystackClient) CreateUser(data PaystackCreateUserRequest) (*CreateUserResponse, error) {
-	res, err := p.makeRequest("POST", "customer", data)
+// doJSON makes the request, always closes the body and decodes it into
+// response. A non-2xx status or a false status field becomes a *PaystackError.
+// GET requests are retried according to the retry policy.
+func (p *paystackClient) doJSON(ctx context.Context, method, endpoint string, body, response interface{}) error {
+	if method != "GET" {
+		return p.doJSONOnce(ctx, method, endpoint, body, response)
+	}
+	return p.withRetry(ctx, func() error {
+		return p.doJSONOnce(ctx, method, endpoint, body, response)
+	})
+}
+
+func (p *paystackClient) doJSONOnce(ctx context.Context, method, endpoint string, body, response interface{}) error {
+	if p.limiter != nil {
+		if err := p.limiter.Wait(ctx, endpoint); err != nil {
+			return err
+		}
+	}
+
+	res, err := p.makeRequest(ctx, method, endpoint, body)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Status bool `json:"status"`
+		PaystackError
+	}
+	decodeErr := json.Unmarshal(raw, &envelope)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices || decodeErr == nil && !envelope.Status {
+		paystackErr := envelope.PaystackError
+		paystackErr.StatusCode = res.StatusCode
+		paystackErr.Method = method
+		paystackErr.Endpoint = endpoint
+		if paystackErr.Message == "" {
+			paystackErr.Message = http.StatusText(res.StatusCode)
+		}
+		if res.StatusCode == http.StatusTooManyRequests {
+			paystackErr.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+			if p.limiter != nil {
+				p.limiter.reject(endpoint, paystackErr.RetryAfter)
+			}
+		}
+		return &paystackErr
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("paystack %s %s returned an invalid response: %v", method, endpoint, decodeErr)
 	}
 
+	return json.Unmarshal(raw, response)
+}
+
+func (p *paystackClient) CreateUser(data PaystackCreateUserRequest) (*CreateUserResponse, error) {
+	return p.CreateUserWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateUserWithContext(ctx context.Context, data PaystackCreateUserRequest) (*CreateUserResponse, error) {
 	var response CreateUserResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	if err := p.doJSON(ctx, "POST", "customer", data, &response); err != nil {
 		return nil, err
 	}
 
@@ -98,29 +434,27 @@ func (p *paystackClient) CreateUser(data PaystackCreateUserRequest) (*CreateUser
 }
 
 func (p *paystackClient) UpdateUser(data PaystackUpdateUserRequest, pastackCode string) error {
-	url := fmt.Sprintf("customer/%s", pastackCode)
-
-	res, err := p.makeRequest("PUT", url, data)
-	if err != nil {
-		return err
-	}
+	return p.UpdateUserWithContext(context.Background(), data, pastackCode)
+}
 
-	var response CreateUserResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return err
-	}
+func (p *paystackClient) UpdateUserWithContext(ctx context.Context, data PaystackUpdateUserRequest, pastackCode string) error {
+	url := fmt.Sprintf("customer/%s", pastackCode)
 
-	return nil
+	var response UpdateUserResponse
+	return p.doJSON(ctx, "PUT", url, data, &response)
 }
 
 func (p *paystackClient) CreateTransferRecipient(data *PaystackCreateTransferRecipientRequest) (*CreateTransferRecipientResponse, error) {
-	res, err := p.makeRequest("POST", "transferrecipient", data)
-	if err != nil {
+	return p.CreateTransferRecipientWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateTransferRecipientWithContext(ctx context.Context, data *PaystackCreateTransferRecipientRequest) (*CreateTransferRecipientResponse, error) {
+	if err := data.validate(); err != nil {
 		return nil, err
 	}
 
 	var response CreateTransferRecipientResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	if err := p.doJSON(ctx, "POST", "transferrecipient", data, &response); err != nil {
 		return nil, err
 	}
 
@@ -128,31 +462,48 @@ func (p *paystackClient) CreateTransferRecipient(data *PaystackCreateTransferRec
 }
 
 func (p *paystackClient) InitiateTransfer(data *TransferFundsRequest) (*TransferOTPResponse, error) {
-	res, err := p.makeRequest("POST", "transfer", data)
-	if err != nil {
-		return nil, err
+	return p.InitiateTransferWithContext(context.Background(), data)
+}
+
+// InitiateTransferWithContext requires data.Reference. If the request fails in
+// a way that may be temporary, the transfer is verified by reference and only
+// sent again when Paystack has no record of it.
+func (p *paystackClient) InitiateTransferWithContext(ctx context.Context, data *TransferFundsRequest) (*TransferOTPResponse, error) {
+	if data.Reference == "" {
+		return nil, fmt.Errorf("reference is required to initiate a transfer")
+	}
+	if data.AmountMinor <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
 	}
 
 	var response TransferOTPResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	err := p.withVerifiedRetry(ctx, func() error {
+		return p.doJSONOnce(ctx, "POST", "transfer", data, &response)
+	}, func() (bool, error) {
+		verified, err := p.VerifyTransferWithContext(ctx, data.Reference)
+		if isNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		response = verified.otpResponse()
+		return true, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Message)
-	}
-
 	return &response, nil
 }
 
 func (p *paystackClient) FetchBalance() (*BalanceResponse, error) {
-	res, err := p.makeRequest("GET", "balance", nil)
-	if err != nil {
-		return nil, err
-	}
+	return p.FetchBalanceWithContext(context.Background())
+}
 
+func (p *paystackClient) FetchBalanceWithContext(ctx context.Context) (*BalanceResponse, error) {
 	var response BalanceResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	if err := p.doJSON(ctx, "GET", "balance", nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -160,34 +511,41 @@ func (p *paystackClient) FetchBalance() (*BalanceResponse, error) {
 }
 
 func (p *paystackClient) FetchBanks() (*BanksResponse, error) {
-	res, err := p.makeRequest("GET", "bank?country=nigeria", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var response BanksResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, err
-	}
+	return p.FetchBanksWithContext(context.Background())
+}
 
-	return &response, nil
+func (p *paystackClient) FetchBanksWithContext(ctx context.Context) (*BanksResponse, error) {
+	return p.ListBanksWithContext(ctx, BankQuery{Country: CountryNigeria})
 }
 
 func (p *paystackClient) GetBankByPrefix(prefix string) (*BanksResponse, error) {
-	banksResponse, err := p.FetchBanks()
+	return p.GetBankByPrefixWithContext(context.Background(), prefix)
+}
+
+func (p *paystackClient) GetBankByPrefixWithContext(ctx context.Context, prefix string) (*BanksResponse, error) {
+	return bankByPrefix(ctx, p.bankDirectory(p.bankQuery), prefix)
+}
+
+func (p *paystackClient) GetBankByPrefixForCurrency(currency, prefix string) (*BanksResponse, error) {
+	return p.GetBankByPrefixForCurrencyWithContext(context.Background(), currency, prefix)
+}
+
+// GetBankByPrefixForCurrencyWithContext searches the bank list of the country
+// that uses currency, for example GHS for Ghana.
+func (p *paystackClient) GetBankByPrefixForCurrencyWithContext(ctx context.Context, currency, prefix string) (*BanksResponse, error) {
+	directory, err := p.bankDirectoryForCurrency(currency)
 	if err != nil {
 		return nil, err
 	}
+	return bankByPrefix(ctx, directory, prefix)
+}
 
-	lowerPrefix := strings.ToLower(prefix)
-	var bank []Banks
-	for _, banks := range banksResponse.Data {
-		if strings.HasPrefix(strings.ToLower(banks.Name), lowerPrefix) {
-			bank = append(bank, banks)
-		}
+func bankByPrefix(ctx context.Context, directory *BankDirectory, prefix string) (*BanksResponse, error) {
+	bank, err := directory.ByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, err
 	}
 
-	logrus.Info(bank)
 	return &BanksResponse{
 		Message: "Banks fetched successfully",
 		Status:  true,
@@ -196,28 +554,57 @@ func (p *paystackClient) GetBankByPrefix(prefix string) (*BanksResponse, error)
 }
 
 func (p *paystackClient) GetBankNameByCode(bankCode string) (string, error) {
-	banksResponse, err := p.FetchBanks()
+	return p.GetBankNameByCodeWithContext(context.Background(), bankCode)
+}
+
+func (p *paystackClient) GetBankNameByCodeWithContext(ctx context.Context, bankCode string) (string, error) {
+	return bankNameByCode(ctx, p.bankDirectory(p.bankQuery), bankCode)
+}
+
+func (p *paystackClient) GetBankNameByCodeForCurrency(currency, bankCode string) (string, error) {
+	return p.GetBankNameByCodeForCurrencyWithContext(context.Background(), currency, bankCode)
+}
+
+// GetBankNameByCodeForCurrencyWithContext looks bankCode up in the bank list
+// of the country that uses currency.
+func (p *paystackClient) GetBankNameByCodeForCurrencyWithContext(ctx context.Context, currency, bankCode string) (string, error) {
+	directory, err := p.bankDirectoryForCurrency(currency)
 	if err != nil {
 		return "", err
 	}
+	return bankNameByCode(ctx, directory, bankCode)
+}
 
-	for _, bank := range banksResponse.Data {
-		if bank.Code == bankCode {
-			return bank.Name, nil
-		}
+func bankNameByCode(ctx context.Context, directory *BankDirectory, bankCode string) (string, error) {
+	bank, err := directory.ByCode(ctx, bankCode)
+	if errors.Is(err, ErrBankNotFound) {
+		return "", fmt.Errorf("bank with code %s not found", bankCode)
+	}
+	if err != nil {
+		return "", err
 	}
 
-	return "", fmt.Errorf("bank with code %s not found", bankCode)
+	return bank.Name, nil
 }
 
+// ResolveAccountNumber looks up the name on a bank account or, when BankCode
+// is a mobile money provider, on a mobile money wallet. Mobile numbers may be
+// given in international format.
 func (p *paystackClient) ResolveAccountNumber(account *interfacesx.ResolveBankAccountRequest) (*AccountResponse, error) {
-	res, err := p.makeRequest("GET", fmt.Sprintf("bank/resolve?account_number=%s&bank_code=%s", account.AccountNumber, account.BankCode), nil)
-	if err != nil {
-		return nil, err
+	return p.ResolveAccountNumberWithContext(context.Background(), account)
+}
+
+func (p *paystackClient) ResolveAccountNumberWithContext(ctx context.Context, account *interfacesx.ResolveBankAccountRequest) (*AccountResponse, error) {
+	accountNumber := account.AccountNumber
+	if currency, ok := mobileMoneyProviders[strings.ToUpper(account.BankCode)]; ok {
+		accountNumber = localMobileNumber(accountNumber, currency)
 	}
+	query := url.Values{}
+	query.Set("account_number", accountNumber)
+	query.Set("bank_code", account.BankCode)
 
 	var response AccountResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	if err := p.doJSON(ctx, "GET", "bank/resolve?"+query.Encode(), nil, &response); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,419 @@
+package paystackx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Countries supported by the Paystack bank list.
+const (
+	CountryNigeria     = "nigeria"
+	CountryGhana       = "ghana"
+	CountryKenya       = "kenya"
+	CountrySouthAfrica = "south africa"
+)
+
+var countryCurrencies = map[string]string{
+	CountryNigeria:     "NGN",
+	CountryGhana:       "GHS",
+	CountryKenya:       "KES",
+	CountrySouthAfrica: "ZAR",
+}
+
+// DefaultBankCacheTTL is how long a BankDirectory keeps the bank list.
+const DefaultBankCacheTTL = 24 * time.Hour
+
+var ErrBankNotFound = errors.New("bank not found")
+
+// BankQuery selects which banks ListBanks returns. Type narrows the list to a
+// channel such as "nuban", "mobile_money" or "ghipss".
+type BankQuery struct {
+	Country  string
+	Currency string
+	Type     string
+}
+
+// BankQueryForCurrency returns the query for the country that uses currency.
+func BankQueryForCurrency(currency string) (BankQuery, error) {
+	currency = strings.ToUpper(currency)
+	for country, countryCurrency := range countryCurrencies {
+		if countryCurrency == currency {
+			return BankQuery{Country: country, Currency: currency}, nil
+		}
+	}
+	return BankQuery{}, fmt.Errorf("no bank list for currency %s", currency)
+}
+
+func (q BankQuery) values() url.Values {
+	query := url.Values{}
+	country := q.Country
+	if country == "" {
+		country = CountryNigeria
+	}
+	query.Set("country", country)
+	if q.Currency != "" {
+		query.Set("currency", q.Currency)
+	}
+	if q.Type != "" {
+		query.Set("type", q.Type)
+	}
+	query.Set("use_cursor", "true")
+	query.Set("perPage", "100")
+	return query
+}
+
+func (p *paystackClient) ListBanks(query BankQuery) (*BanksResponse, error) {
+	return p.ListBanksWithContext(context.Background(), query)
+}
+
+// ListBanksWithContext follows the cursor until every bank has been fetched.
+// It stops early if Paystack hands back a cursor it has already followed.
+func (p *paystackClient) ListBanksWithContext(ctx context.Context, query BankQuery) (*BanksResponse, error) {
+	values := query.values()
+	all := &BanksResponse{}
+	followed := map[string]bool{}
+	for {
+		var page struct {
+			BanksResponse
+			Meta Meta `json:"meta"`
+		}
+		if err := p.doJSON(ctx, "GET", "bank?"+values.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		all.Status = page.Status
+		all.Message = page.Message
+		all.Data = append(all.Data, page.Data...)
+
+		if page.Meta.Next == "" || len(page.Data) == 0 || followed[page.Meta.Next] {
+			return all, nil
+		}
+		followed[page.Meta.Next] = true
+		values.Set("next", page.Meta.Next)
+	}
+}
+
+// BankDirectory caches a bank list and indexes it by code, slug and name. It
+// is safe for concurrent use.
+type BankDirectory struct {
+	client BankService
+	query  BankQuery
+	ttl    time.Duration
+	now    func() time.Time
+
+	refreshMu sync.Mutex
+	mu        sync.RWMutex
+	banks     []Banks
+	byCode    map[string]int
+	bySlug    map[string]int
+	fetchedAt time.Time
+}
+
+// NewBankDirectory creates a directory for query that refetches the list once
+// it is older than ttl.
+func NewBankDirectory(client BankService, query BankQuery, ttl time.Duration) *BankDirectory {
+	if ttl <= 0 {
+		ttl = DefaultBankCacheTTL
+	}
+	return &BankDirectory{
+		client: client,
+		query:  query,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Refresh fetches the bank list and rebuilds the indexes.
+func (d *BankDirectory) Refresh(ctx context.Context) error {
+	d.refreshMu.Lock()
+	defer d.refreshMu.Unlock()
+	return d.refresh(ctx)
+}
+
+func (d *BankDirectory) refresh(ctx context.Context) error {
+	response, err := d.client.ListBanksWithContext(ctx, d.query)
+	if err != nil {
+		return err
+	}
+
+	byCode := make(map[string]int, len(response.Data))
+	bySlug := make(map[string]int, len(response.Data))
+	for i, bank := range response.Data {
+		if bank.Code != "" {
+			byCode[bank.Code] = i
+		}
+		if bank.Slug != "" {
+			bySlug[strings.ToLower(bank.Slug)] = i
+		}
+	}
+
+	d.mu.Lock()
+	d.banks = response.Data
+	d.byCode = byCode
+	d.bySlug = bySlug
+	d.fetchedAt = d.now()
+	d.mu.Unlock()
+	return nil
+}
+
+// StartRefresh refreshes the list every interval until ctx is cancelled, so
+// lookups never wait on the network once the first fetch is done.
+func (d *BankDirectory) StartRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.Refresh(ctx); err != nil && ctx.Err() == nil {
+					logrus.WithField("country", d.query.Country).Warn("failed to refresh bank list: ", err)
+				}
+			}
+		}
+	}()
+}
+
+// ensure fetches the list when it is missing or older than the TTL. A stale
+// list is kept if the refetch fails.
+func (d *BankDirectory) ensure(ctx context.Context) error {
+	d.mu.RLock()
+	fresh := !d.fetchedAt.IsZero() && d.now().Sub(d.fetchedAt) < d.ttl
+	d.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	d.refreshMu.Lock()
+	defer d.refreshMu.Unlock()
+
+	// Another caller may have refreshed while we waited for the lock.
+	d.mu.RLock()
+	fresh = !d.fetchedAt.IsZero() && d.now().Sub(d.fetchedAt) < d.ttl
+	stale := !d.fetchedAt.IsZero()
+	d.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	if err := d.refresh(ctx); err != nil {
+		if stale {
+			logrus.WithField("country", d.query.Country).Warn("using stale bank list: ", err)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Banks returns every bank in the directory.
+func (d *BankDirectory) Banks(ctx context.Context) ([]Banks, error) {
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Banks(nil), d.banks...), nil
+}
+
+// ByCode returns the bank with the given code.
+func (d *BankDirectory) ByCode(ctx context.Context, code string) (*Banks, error) {
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	i, ok := d.byCode[code]
+	if !ok {
+		return nil, fmt.Errorf("bank with code %s: %w", code, ErrBankNotFound)
+	}
+	bank := d.banks[i]
+	return &bank, nil
+}
+
+// BySlug returns the bank with the given slug.
+func (d *BankDirectory) BySlug(ctx context.Context, slug string) (*Banks, error) {
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	i, ok := d.bySlug[strings.ToLower(slug)]
+	if !ok {
+		return nil, fmt.Errorf("bank with slug %s: %w", slug, ErrBankNotFound)
+	}
+	bank := d.banks[i]
+	return &bank, nil
+}
+
+// ByPrefix returns the banks whose name starts with prefix, ignoring case.
+func (d *BankDirectory) ByPrefix(ctx context.Context, prefix string) ([]Banks, error) {
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	lowerPrefix := strings.ToLower(prefix)
+	var banks []Banks
+	for _, bank := range d.banks {
+		if strings.HasPrefix(strings.ToLower(bank.Name), lowerPrefix) {
+			banks = append(banks, bank)
+		}
+	}
+	return banks, nil
+}
+
+// Search returns up to limit banks whose name loosely matches name, best match
+// first. It tolerates case, punctuation, words such as "bank" or "plc",
+// initials ("gtb") and small typos.
+func (d *BankDirectory) Search(ctx context.Context, name string, limit int) ([]Banks, error) {
+	if err := d.ensure(ctx); err != nil {
+		return nil, err
+	}
+
+	query := normalizeBankName(name)
+	if query == "" {
+		return nil, nil
+	}
+
+	type match struct {
+		bank  Banks
+		score float64
+	}
+	var matches []match
+
+	d.mu.RLock()
+	for _, bank := range d.banks {
+		if score := bankNameScore(query, bank); score > 0 {
+			matches = append(matches, match{bank: bank, score: score})
+		}
+	}
+	d.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	banks := make([]Banks, len(matches))
+	for i, m := range matches {
+		banks[i] = m.bank
+	}
+	return banks, nil
+}
+
+var bankNameStopWords = map[string]bool{
+	"bank": true, "plc": true, "limited": true, "ltd": true, "the": true, "of": true,
+}
+
+func normalizeBankName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+
+	words := fields[:0]
+	for _, field := range fields {
+		if !bankNameStopWords[field] {
+			words = append(words, field)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func bankNameScore(query string, bank Banks) float64 {
+	name := normalizeBankName(bank.Name)
+	if name == "" {
+		return 0
+	}
+
+	switch {
+	case name == query || strings.EqualFold(bank.Slug, query) || bank.Code == query:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 0.9
+	case bankInitials(bank.Name) == strings.ReplaceAll(query, " ", ""):
+		return 0.85
+	case allWordsPrefixed(query, name):
+		return 0.8
+	case strings.Contains(name, query):
+		return 0.7
+	}
+
+	if similarity := stringSimilarity(query, name); similarity >= 0.75 {
+		return similarity * 0.6
+	}
+	return 0
+}
+
+func bankInitials(name string) string {
+	var initials strings.Builder
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		if word[0] >= 'a' && word[0] <= 'z' && word != "plc" && word != "limited" {
+			initials.WriteByte(word[0])
+		}
+	}
+	return initials.String()
+}
+
+// allWordsPrefixed reports whether every word in query starts a word in name.
+func allWordsPrefixed(query, name string) bool {
+	nameWords := strings.Fields(name)
+	for _, word := range strings.Fields(query) {
+		found := false
+		for _, nameWord := range nameWords {
+			if strings.HasPrefix(nameWord, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// stringSimilarity is one minus the Levenshtein distance over the longer length.
+func stringSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(b)])/float64(longest)
+}
@@ -0,0 +1,234 @@
+package paystackx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const nigerianBanksPage1 = `{"status":true,"message":"Banks retrieved","data":[
+{"id":1,"name":"Access Bank","slug":"access-bank","code":"044","country":"Nigeria","currency":"NGN","type":"nuban","active":true},
+{"id":3,"name":"Access Bank (Diamond)","slug":"access-bank-diamond","code":"063","country":"Nigeria","currency":"NGN","type":"nuban","active":true},
+{"id":7,"name":"First Bank of Nigeria","slug":"first-bank-of-nigeria","code":"011","country":"Nigeria","currency":"NGN","type":"nuban","active":true}
+],"meta":{"next":"YmFuazoxNjk=","previous":null,"perPage":3}}`
+
+const nigerianBanksPage2 = `{"status":true,"message":"Banks retrieved","data":[
+{"id":9,"name":"Guaranty Trust Bank","slug":"guaranty-trust-bank","code":"058","country":"Nigeria","currency":"NGN","type":"nuban","active":true},
+{"id":20,"name":"United Bank For Africa","slug":"united-bank-for-africa","code":"033","country":"Nigeria","currency":"NGN","type":"nuban","active":true}
+],"meta":{"next":null,"previous":"YmFuazoxNjk=","perPage":3}}`
+
+func newBankServer(t *testing.T, failing *int32) (*httptest.Server, *int32) {
+	var listings int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing != nil && atomic.LoadInt32(failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		query := r.URL.Query()
+		switch query.Get("country") {
+		case CountryNigeria:
+			if query.Get("next") == "" {
+				atomic.AddInt32(&listings, 1)
+				w.Write([]byte(nigerianBanksPage1))
+				return
+			}
+			require.Equal(t, "YmFuazoxNjk=", query.Get("next"))
+			w.Write([]byte(nigerianBanksPage2))
+		case CountryGhana:
+			require.Equal(t, "GHS", query.Get("currency"))
+			w.Write([]byte(`{"status":true,"message":"Banks retrieved","data":[{"id":28,"name":"GCB Bank Limited","slug":"gcb-bank","code":"040100","country":"Ghana","currency":"GHS","type":"ghipss"}],"meta":{"next":null}}`))
+		default:
+			t.Fatalf("unexpected country %q", query.Get("country"))
+		}
+	}))
+	return server, &listings
+}
+
+func TestBankLookupsUseCache(t *testing.T) {
+	server, listings := newBankServer(t, nil)
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	name, err := client.GetBankNameByCode("058")
+	require.NoError(t, err)
+	require.Equal(t, "Guaranty Trust Bank", name)
+
+	banks, err := client.GetBankByPrefix("access")
+	require.NoError(t, err)
+	require.Len(t, banks.Data, 2)
+
+	_, err = client.GetBankNameByCode("999")
+	require.EqualError(t, err, "bank with code 999 not found")
+
+	require.Equal(t, int32(1), atomic.LoadInt32(listings))
+}
+
+func TestBankLookupsByCurrency(t *testing.T) {
+	server, listings := newBankServer(t, nil)
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	name, err := client.GetBankNameByCodeForCurrency("ghs", "040100")
+	require.NoError(t, err)
+	require.Equal(t, "GCB Bank Limited", name)
+
+	banks, err := client.GetBankByPrefixForCurrency("GHS", "gcb")
+	require.NoError(t, err)
+	require.Len(t, banks.Data, 1)
+
+	_, err = client.GetBankNameByCodeForCurrency("GHS", "058")
+	require.EqualError(t, err, "bank with code 058 not found")
+	_, err = client.GetBankNameByCodeForCurrency("USD", "058")
+	require.EqualError(t, err, "no bank list for currency USD")
+
+	// The default list is still Nigeria's, and was not fetched for Ghana.
+	require.Equal(t, int32(0), atomic.LoadInt32(listings))
+	name, err = client.GetBankNameByCode("058")
+	require.NoError(t, err)
+	require.Equal(t, "Guaranty Trust Bank", name)
+
+	ghana := NewPaystackClient(server.URL, testSecretKey, WithBankQuery(BankQuery{Country: CountryGhana, Currency: "GHS"}))
+	name, err = ghana.GetBankNameByCode("040100")
+	require.NoError(t, err)
+	require.Equal(t, "GCB Bank Limited", name)
+}
+
+func TestBankDirectoryTTLAndStaleFallback(t *testing.T) {
+	var failing int32
+	server, listings := newBankServer(t, &failing)
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	directory := NewBankDirectory(NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(NoRetry())), BankQuery{Country: CountryNigeria}, time.Hour)
+	directory.now = func() time.Time { return now }
+
+	bank, err := directory.BySlug(context.Background(), "first-bank-of-nigeria")
+	require.NoError(t, err)
+	require.Equal(t, "011", bank.Code)
+
+	now = now.Add(2 * time.Hour)
+	_, err = directory.ByCode(context.Background(), "011")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(listings))
+
+	atomic.StoreInt32(&failing, 1)
+	now = now.Add(2 * time.Hour)
+	bank, err = directory.ByCode(context.Background(), "033")
+	require.NoError(t, err)
+	require.Equal(t, "United Bank For Africa", bank.Name)
+
+	_, err = directory.ByCode(context.Background(), "000")
+	require.ErrorIs(t, err, ErrBankNotFound)
+}
+
+func TestListBanksStopsOnRepeatedCursor(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"status":true,"message":"Banks retrieved","data":[{"id":1,"name":"Access Bank","slug":"access-bank","code":"044"}],"meta":{"next":"YmFuazox"}}`))
+	}))
+	defer server.Close()
+
+	banks, err := NewPaystackClient(server.URL, testSecretKey).ListBanks(BankQuery{Country: CountryNigeria})
+	require.NoError(t, err)
+	require.Len(t, banks.Data, 2)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestBankDirectoryCachesEmptyList(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"status":true,"message":"Banks retrieved","data":[],"meta":{"next":null}}`))
+	}))
+	defer server.Close()
+
+	directory := NewBankDirectory(NewPaystackClient(server.URL, testSecretKey), BankQuery{Country: CountryKenya}, time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err := directory.ByCode(context.Background(), "044")
+		require.ErrorIs(t, err, ErrBankNotFound)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestBankDirectorySearch(t *testing.T) {
+	server, _ := newBankServer(t, nil)
+	defer server.Close()
+
+	directory := NewBankDirectory(NewPaystackClient(server.URL, testSecretKey), BankQuery{Country: CountryNigeria}, time.Hour)
+	ctx := context.Background()
+
+	banks, err := directory.Search(ctx, "GTB", 1)
+	require.NoError(t, err)
+	require.Equal(t, "058", banks[0].Code)
+
+	banks, err = directory.Search(ctx, "frist bank nigeria", 1)
+	require.NoError(t, err)
+	require.Equal(t, "011", banks[0].Code)
+
+	banks, err = directory.Search(ctx, "access", 0)
+	require.NoError(t, err)
+	require.Len(t, banks, 2)
+	require.Equal(t, "044", banks[0].Code)
+
+	banks, err = directory.Search(ctx, "united africa", 0)
+	require.NoError(t, err)
+	require.Equal(t, "033", banks[0].Code)
+
+	banks, err = directory.Search(ctx, "zenith", 0)
+	require.NoError(t, err)
+	require.Empty(t, banks)
+}
+
+func TestBankDirectoryCountries(t *testing.T) {
+	server, _ := newBankServer(t, nil)
+	defer server.Close()
+
+	query, err := BankQueryForCurrency("ghs")
+	require.NoError(t, err)
+	require.Equal(t, CountryGhana, query.Country)
+
+	directory := NewBankDirectory(NewPaystackClient(server.URL, testSecretKey), query, time.Hour)
+	bank, err := directory.ByCode(context.Background(), "040100")
+	require.NoError(t, err)
+	require.Equal(t, "GHS", bank.Currency)
+
+	_, err = BankQueryForCurrency("EUR")
+	require.Error(t, err)
+}
+
+func TestBankDirectoryConcurrentUse(t *testing.T) {
+	server, listings := newBankServer(t, nil)
+	defer server.Close()
+
+	directory := NewBankDirectory(NewPaystackClient(server.URL, testSecretKey), BankQuery{Country: CountryNigeria}, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	directory.StartRefresh(ctx, 5*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_, err := directory.ByCode(ctx, "044")
+				require.NoError(t, err)
+				_, err = directory.Search(ctx, "guaranty", 1)
+				require.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(listings) > 1 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,288 @@
+package paystackx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AssignDedicatedAccountRequest creates a customer, validates them and
+// assigns a dedicated account in one step. Paystack responds immediately and
+// reports the result with the dedicatedaccount.assign.* webhooks.
+type AssignDedicatedAccountRequest struct {
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Phone         string `json:"phone"`
+	PreferredBank string `json:"preferred_bank"`
+	Country       string `json:"country"`
+	AccountNumber string `json:"account_number,omitempty"`
+	BVN           string `json:"bvn,omitempty"`
+	BankCode      string `json:"bank_code,omitempty"`
+	Subaccount    string `json:"subaccount,omitempty"`
+	SplitCode     string `json:"split_code,omitempty"`
+}
+
+// ListDedicatedAccountsRequest filters ListDedicatedAccounts. Zero values are
+// omitted; Active is a pointer so inactive accounts can be requested.
+type ListDedicatedAccountsRequest struct {
+	Active       *bool
+	Currency     string
+	ProviderSlug string
+	BankID       string
+	Customer     string
+}
+
+func (r *ListDedicatedAccountsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.Active != nil {
+		query.Set("active", strconv.FormatBool(*r.Active))
+	}
+	if r.Currency != "" {
+		query.Set("currency", r.Currency)
+	}
+	if r.ProviderSlug != "" {
+		query.Set("provider_slug", r.ProviderSlug)
+	}
+	if r.BankID != "" {
+		query.Set("bank_id", r.BankID)
+	}
+	if r.Customer != "" {
+		query.Set("customer", r.Customer)
+	}
+	return query
+}
+
+type ListDedicatedAccountsResponse struct {
+	Status  bool                  `json:"status"`
+	Message string                `json:"message"`
+	Data    []VirtaualAccountData `json:"data"`
+	Meta    Meta                  `json:"meta"`
+}
+
+// RequeryDedicatedAccountRequest asks Paystack to check an account for
+// transfers it has not notified yet. Date limits the check to one day.
+type RequeryDedicatedAccountRequest struct {
+	AccountNumber string
+	ProviderSlug  string
+	Date          time.Time
+}
+
+// SplitDedicatedAccountRequest adds a subaccount or split to a customer's
+// dedicated account, creating the account if the customer has none.
+type SplitDedicatedAccountRequest struct {
+	Customer      string `json:"customer"`
+	Subaccount    string `json:"subaccount,omitempty"`
+	SplitCode     string `json:"split_code,omitempty"`
+	PreferredBank string `json:"preferred_bank,omitempty"`
+}
+
+type DedicatedAccountProvider struct {
+	ID           int    `json:"id"`
+	ProviderSlug string `json:"provider_slug"`
+	BankID       int    `json:"bank_id"`
+	BankName     string `json:"bank_name"`
+}
+
+type DedicatedAccountProvidersResponse struct {
+	Status  bool                       `json:"status"`
+	Message string                     `json:"message"`
+	Data    []DedicatedAccountProvider `json:"data"`
+}
+
+// UserVirtualAccount returns the fields of the account shown to users.
+func (d *VirtaualAccountData) UserVirtualAccount() UserVirtualAccountData {
+	return UserVirtualAccountData{
+		BankName:      d.Bank.Name,
+		AccountName:   d.AccountName,
+		AccountNumber: d.AccountNumber,
+		Currency:      d.Currency,
+	}
+}
+
+// NewUserVirtualAccountResponse wraps a dedicated account in the response
+// returned to users.
+func NewUserVirtualAccountResponse(account *VirtaualAccountData) *UserVirtualAccountResponse {
+	return &UserVirtualAccountResponse{
+		Status:  "success",
+		Message: "Virtual account retrieved successfully",
+		Code:    http.StatusOK,
+		Data:    account.UserVirtualAccount(),
+	}
+}
+
+// UserVirtualAccountResponse maps the result of an assignment into the
+// response returned to users. It fails if the assignment failed.
+func (d *DedicatedAccountEventData) UserVirtualAccountResponse() (*UserVirtualAccountResponse, error) {
+	if d.DedicatedAccount == nil {
+		reason := d.Identification.Status
+		if reason == "" {
+			reason = "no dedicated account"
+		}
+		return nil, fmt.Errorf("dedicated account for %s was not assigned: %s", d.Customer.Email, reason)
+	}
+	return NewUserVirtualAccountResponse(d.DedicatedAccount), nil
+}
+
+func (p *paystackClient) AssignDedicatedAccount(data *AssignDedicatedAccountRequest) (*MessageResponse, error) {
+	return p.AssignDedicatedAccountWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) AssignDedicatedAccountWithContext(ctx context.Context, data *AssignDedicatedAccountRequest) (*MessageResponse, error) {
+	if data.Email == "" || data.FirstName == "" || data.LastName == "" || data.Phone == "" {
+		return nil, fmt.Errorf("email, first name, last name and phone are required")
+	}
+	if data.PreferredBank == "" {
+		return nil, fmt.Errorf("preferred bank is required")
+	}
+	if data.Country == "" {
+		data.Country = "NG"
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "dedicated_account/assign", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListDedicatedAccounts(filter *ListDedicatedAccountsRequest) (*ListDedicatedAccountsResponse, error) {
+	return p.ListDedicatedAccountsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListDedicatedAccountsWithContext(ctx context.Context, filter *ListDedicatedAccountsRequest) (*ListDedicatedAccountsResponse, error) {
+	endpoint := "dedicated_account"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListDedicatedAccountsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchDedicatedAccount(id int) (*VirtualAccountResponse, error) {
+	return p.FetchDedicatedAccountWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) FetchDedicatedAccountWithContext(ctx context.Context, id int) (*VirtualAccountResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("dedicated account id is required")
+	}
+
+	var response VirtualAccountResponse
+	if err := p.doJSON(ctx, "GET", "dedicated_account/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// RequeryDedicatedAccount asks Paystack to look for missed deposits. Any it
+// finds are delivered as charge.success webhooks.
+func (p *paystackClient) RequeryDedicatedAccount(data *RequeryDedicatedAccountRequest) (*MessageResponse, error) {
+	return p.RequeryDedicatedAccountWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) RequeryDedicatedAccountWithContext(ctx context.Context, data *RequeryDedicatedAccountRequest) (*MessageResponse, error) {
+	if data.AccountNumber == "" || data.ProviderSlug == "" {
+		return nil, fmt.Errorf("account number and provider slug are required")
+	}
+
+	query := url.Values{}
+	query.Set("account_number", data.AccountNumber)
+	query.Set("provider_slug", data.ProviderSlug)
+	if !data.Date.IsZero() {
+		query.Set("date", data.Date.Format("2006-01-02"))
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "GET", "dedicated_account/requery?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DeactivateDedicatedAccount deactivates an account, for example when a user
+// closes their wallet. It returns the deactivated account.
+func (p *paystackClient) DeactivateDedicatedAccount(id int) (*VirtualAccountResponse, error) {
+	return p.DeactivateDedicatedAccountWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) DeactivateDedicatedAccountWithContext(ctx context.Context, id int) (*VirtualAccountResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("dedicated account id is required")
+	}
+
+	var response VirtualAccountResponse
+	if err := p.doJSON(ctx, "DELETE", "dedicated_account/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) SplitDedicatedAccount(data *SplitDedicatedAccountRequest) (*VirtualAccountResponse, error) {
+	return p.SplitDedicatedAccountWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) SplitDedicatedAccountWithContext(ctx context.Context, data *SplitDedicatedAccountRequest) (*VirtualAccountResponse, error) {
+	if data.Customer == "" {
+		return nil, fmt.Errorf("customer is required")
+	}
+	if data.Subaccount == "" && data.SplitCode == "" {
+		return nil, fmt.Errorf("subaccount or split code is required")
+	}
+
+	var response VirtualAccountResponse
+	if err := p.doJSON(ctx, "POST", "dedicated_account/split", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// RemoveDedicatedAccountSplit removes the split from an account so deposits
+// settle to the main balance again.
+func (p *paystackClient) RemoveDedicatedAccountSplit(accountNumber string) (*VirtualAccountResponse, error) {
+	return p.RemoveDedicatedAccountSplitWithContext(context.Background(), accountNumber)
+}
+
+func (p *paystackClient) RemoveDedicatedAccountSplitWithContext(ctx context.Context, accountNumber string) (*VirtualAccountResponse, error) {
+	if accountNumber == "" {
+		return nil, fmt.Errorf("account number is required")
+	}
+
+	var response VirtualAccountResponse
+	if err := p.doJSON(ctx, "DELETE", "dedicated_account/split", map[string]string{"account_number": accountNumber}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListDedicatedAccountProviders lists the banks that can be used as
+// PreferredBank.
+func (p *paystackClient) ListDedicatedAccountProviders() (*DedicatedAccountProvidersResponse, error) {
+	return p.ListDedicatedAccountProvidersWithContext(context.Background())
+}
+
+func (p *paystackClient) ListDedicatedAccountProvidersWithContext(ctx context.Context) (*DedicatedAccountProvidersResponse, error) {
+	var response DedicatedAccountProvidersResponse
+	if err := p.doJSON(ctx, "GET", "dedicated_account/available_providers", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,92 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedicatedAccountRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /dedicated_account/assign":
+			var body AssignDedicatedAccountRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "NG", body.Country)
+			w.Write([]byte(`{"status":true,"message":"Assign dedicated account in progress"}`))
+		case "GET /dedicated_account":
+			require.Equal(t, "false", r.URL.Query().Get("active"))
+			w.Write([]byte(`{"status":true,"message":"Managed Accounts Successfully Retrieved","data":[{"bank":{"name":"Wema Bank","id":20,"slug":"wema-bank"},"account_name":"PAYSTACK/Ada Obi","account_number":"9930000737","assigned":false,"currency":"NGN","active":false,"id":253}],"meta":{"total":1,"skipped":0,"perPage":50,"page":1,"pageCount":1}}`))
+		case "GET /dedicated_account/requery":
+			query := r.URL.Query()
+			require.Equal(t, "9930000737", query.Get("account_number"))
+			require.Equal(t, "wema-bank", query.Get("provider_slug"))
+			require.Equal(t, "2024-03-01", query.Get("date"))
+			w.Write([]byte(`{"status":true,"message":"We are checking the status of your transfer. We will send you a notification once it has been confirmed"}`))
+		case "DELETE /dedicated_account/253":
+			w.Write([]byte(`{"status":true,"message":"Managed Account Successfully Unassigned","data":{"bank":{"name":"Wema Bank","id":20,"slug":"wema-bank"},"account_number":"9930000737","assigned":false,"active":false,"id":253}}`))
+		case "DELETE /dedicated_account/split":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "9930000737", body["account_number"])
+			w.Write([]byte(`{"status":true,"message":"Subaccount unassigned","data":{"account_number":"9930000737","split_config":null,"id":253}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	_, err := client.AssignDedicatedAccount(&AssignDedicatedAccountRequest{Email: "ada@example.com", FirstName: "Ada", LastName: "Obi", Phone: "+2348100000000", PreferredBank: "wema-bank"})
+	require.NoError(t, err)
+
+	inactive := false
+	accounts, err := client.ListDedicatedAccounts(&ListDedicatedAccountsRequest{Active: &inactive})
+	require.NoError(t, err)
+	require.Len(t, accounts.Data, 1)
+
+	_, err = client.RequeryDedicatedAccount(&RequeryDedicatedAccountRequest{AccountNumber: "9930000737", ProviderSlug: "wema-bank", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	deactivated, err := client.DeactivateDedicatedAccount(253)
+	require.NoError(t, err)
+	require.False(t, deactivated.Data.Active)
+
+	_, err = client.RemoveDedicatedAccountSplit("9930000737")
+	require.NoError(t, err)
+
+	_, err = client.AssignDedicatedAccount(&AssignDedicatedAccountRequest{Email: "ada@example.com", FirstName: "Ada", LastName: "Obi", Phone: "+2348100000000"})
+	require.EqualError(t, err, "preferred bank is required")
+	_, err = client.SplitDedicatedAccount(&SplitDedicatedAccountRequest{Customer: "CUS_1"})
+	require.Error(t, err)
+	_, err = client.RequeryDedicatedAccount(&RequeryDedicatedAccountRequest{AccountNumber: "9930000737"})
+	require.Error(t, err)
+}
+
+func TestUserVirtualAccountResponse(t *testing.T) {
+	assigned := &DedicatedAccountEventData{
+		Customer: PaystackEventCustomer{Email: "ada@example.com"},
+		DedicatedAccount: &VirtaualAccountData{
+			Bank:          Bank{Name: "Wema Bank", Slug: "wema-bank"},
+			AccountName:   "PAYSTACK/Ada Obi",
+			AccountNumber: "9930000737",
+			Currency:      "NGN",
+		},
+	}
+	response, err := assigned.UserVirtualAccountResponse()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, UserVirtualAccountData{BankName: "Wema Bank", AccountName: "PAYSTACK/Ada Obi", AccountNumber: "9930000737", Currency: "NGN"}, response.Data)
+
+	failed := &DedicatedAccountEventData{
+		Customer:       PaystackEventCustomer{Email: "ada@example.com"},
+		Identification: DedicatedAccountIdentification{Status: "failed"},
+	}
+	_, err = failed.UserVirtualAccountResponse()
+	require.EqualError(t, err, "dedicated account for ada@example.com was not assigned: failed")
+}
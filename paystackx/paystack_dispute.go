@@ -0,0 +1,305 @@
+package paystackx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// Paystack dispute statuses.
+const (
+	DisputeStatusAwaitingMerchantFeedback = "awaiting-merchant-feedback"
+	DisputeStatusAwaitingBankFeedback     = "awaiting-bank-feedback"
+	DisputeStatusPending                  = "pending"
+	DisputeStatusResolved                 = "resolved"
+	DisputeStatusArchived                 = "archived"
+)
+
+// Dispute resolutions. Accepting a dispute refunds the customer; declining
+// it keeps the charge.
+const (
+	DisputeResolutionMerchantAccepted = "merchant-accepted"
+	DisputeResolutionDeclined         = "declined"
+)
+
+// MapDisputeStatus converts a dispute status and resolution to the
+// TransactionStatus of the disputed charge. Open disputes put the charge on
+// Hold; resolved ones are Refunded if the merchant accepted and Completed if
+// the dispute was declined or archived.
+func MapDisputeStatus(status, resolution string) interfacesx.TransactionStatus {
+	switch strings.ToLower(status) {
+	case DisputeStatusResolved:
+		if strings.EqualFold(resolution, DisputeResolutionMerchantAccepted) {
+			return interfacesx.Refunded
+		}
+		return interfacesx.Completed
+	case DisputeStatusArchived:
+		return interfacesx.Completed
+	}
+	return interfacesx.Hold
+}
+
+type DisputeHistory struct {
+	Status    string `json:"status"`
+	By        string `json:"by"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type DisputeMessage struct {
+	Sender    string `json:"sender"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// DisputeEvidence is the evidence attached to a dispute.
+type DisputeEvidence struct {
+	ID              int     `json:"id"`
+	Dispute         int     `json:"dispute"`
+	CustomerEmail   string  `json:"customer_email"`
+	CustomerName    string  `json:"customer_name"`
+	CustomerPhone   string  `json:"customer_phone"`
+	ServiceDetails  string  `json:"service_details"`
+	DeliveryAddress *string `json:"delivery_address"`
+	DeliveryDate    *string `json:"delivery_date"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+// Dispute is a chargeback or fraud claim against a charge. It is also the
+// data of the charge.dispute.* webhooks.
+type Dispute struct {
+	ID                   int                   `json:"id"`
+	RefundAmount         *int64                `json:"refund_amount"`
+	Currency             *string               `json:"currency"`
+	Status               string                `json:"status"`
+	Resolution           *string               `json:"resolution"`
+	Domain               string                `json:"domain"`
+	Transaction          PaystackEventData     `json:"transaction"`
+	TransactionReference *string               `json:"transaction_reference"`
+	Category             string                `json:"category"`
+	Customer             PaystackEventCustomer `json:"customer"`
+	Bin                  *string               `json:"bin"`
+	Last4                *string               `json:"last4"`
+	DueAt                *string               `json:"dueAt"`
+	ResolvedAt           *string               `json:"resolvedAt"`
+	Evidence             *DisputeEvidence      `json:"evidence"`
+	Attachments          *string               `json:"attachments"`
+	Note                 *string               `json:"note"`
+	History              []DisputeHistory      `json:"history"`
+	Messages             []DisputeMessage      `json:"messages"`
+	CreatedAt            string                `json:"createdAt"`
+	UpdatedAt            string                `json:"updatedAt"`
+}
+
+// TransactionStatus maps the dispute onto the status of the disputed charge.
+func (d *Dispute) TransactionStatus() interfacesx.TransactionStatus {
+	resolution := ""
+	if d.Resolution != nil {
+		resolution = *d.Resolution
+	}
+	return MapDisputeStatus(d.Status, resolution)
+}
+
+// RefundMoney returns the amount to refund, or zero when none is set yet.
+func (d *Dispute) RefundMoney() moneyx.Money {
+	currency := d.Transaction.Currency
+	if d.Currency != nil {
+		currency = *d.Currency
+	}
+	if d.RefundAmount == nil {
+		return moneyx.Zero(currency)
+	}
+	return moneyx.New(*d.RefundAmount, currency)
+}
+
+type DisputeResponse struct {
+	Status  bool    `json:"status"`
+	Message string  `json:"message"`
+	Data    Dispute `json:"data"`
+}
+
+// ListDisputesRequest filters ListDisputes. Zero values are omitted.
+type ListDisputesRequest struct {
+	PerPage     int
+	Page        int
+	Transaction string
+	Status      string
+	From        time.Time
+	To          time.Time
+}
+
+func (r *ListDisputesRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Transaction != "" {
+		query.Set("transaction", r.Transaction)
+	}
+	if r.Status != "" {
+		query.Set("status", r.Status)
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListDisputesResponse struct {
+	Status  bool      `json:"status"`
+	Message string    `json:"message"`
+	Data    []Dispute `json:"data"`
+	Meta    Meta      `json:"meta"`
+}
+
+// UploadEvidenceRequest provides evidence for a dispute. DeliveryDate uses
+// the YYYY-MM-DD format.
+type UploadEvidenceRequest struct {
+	CustomerEmail   string `json:"customer_email"`
+	CustomerName    string `json:"customer_name"`
+	CustomerPhone   string `json:"customer_phone"`
+	ServiceDetails  string `json:"service_details"`
+	DeliveryAddress string `json:"delivery_address,omitempty"`
+	DeliveryDate    string `json:"delivery_date,omitempty"`
+}
+
+type DisputeEvidenceResponse struct {
+	Status  bool            `json:"status"`
+	Message string          `json:"message"`
+	Data    DisputeEvidence `json:"data"`
+}
+
+// ResolveDisputeRequest resolves a dispute. RefundAmount is in the minor unit
+// of the currency and UploadedFilename comes from DisputeUploadURL.
+type ResolveDisputeRequest struct {
+	Resolution       string `json:"resolution"`
+	Message          string `json:"message"`
+	RefundAmount     int64  `json:"refund_amount"`
+	UploadedFilename string `json:"uploaded_filename"`
+	Evidence         int    `json:"evidence,omitempty"`
+}
+
+type DisputeUploadURLResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		SignedURL string `json:"signedUrl"`
+		FileName  string `json:"fileName"`
+	} `json:"data"`
+}
+
+func (p *paystackClient) ListDisputes(filter *ListDisputesRequest) (*ListDisputesResponse, error) {
+	return p.ListDisputesWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListDisputesWithContext(ctx context.Context, filter *ListDisputesRequest) (*ListDisputesResponse, error) {
+	endpoint := "dispute"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListDisputesResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchDispute(id int) (*DisputeResponse, error) {
+	return p.FetchDisputeWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) FetchDisputeWithContext(ctx context.Context, id int) (*DisputeResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("dispute id is required")
+	}
+
+	var response DisputeResponse
+	if err := p.doJSON(ctx, "GET", "dispute/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) UploadEvidence(id int, data *UploadEvidenceRequest) (*DisputeEvidenceResponse, error) {
+	return p.UploadEvidenceWithContext(context.Background(), id, data)
+}
+
+func (p *paystackClient) UploadEvidenceWithContext(ctx context.Context, id int, data *UploadEvidenceRequest) (*DisputeEvidenceResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("dispute id is required")
+	}
+	if data.CustomerEmail == "" || data.CustomerName == "" || data.CustomerPhone == "" || data.ServiceDetails == "" {
+		return nil, fmt.Errorf("customer email, name, phone and service details are required")
+	}
+
+	var response DisputeEvidenceResponse
+	if err := p.doJSON(ctx, "POST", "dispute/"+strconv.Itoa(id)+"/evidence", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DisputeUploadURL returns a signed URL to upload a file, such as a receipt,
+// before resolving a dispute.
+func (p *paystackClient) DisputeUploadURL(id int, filename string) (*DisputeUploadURLResponse, error) {
+	return p.DisputeUploadURLWithContext(context.Background(), id, filename)
+}
+
+func (p *paystackClient) DisputeUploadURLWithContext(ctx context.Context, id int, filename string) (*DisputeUploadURLResponse, error) {
+	if id <= 0 || filename == "" {
+		return nil, fmt.Errorf("dispute id and filename are required")
+	}
+
+	endpoint := "dispute/" + strconv.Itoa(id) + "/upload_url?" + url.Values{"upload_filename": {filename}}.Encode()
+	var response DisputeUploadURLResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ResolveDispute(id int, data *ResolveDisputeRequest) (*DisputeResponse, error) {
+	return p.ResolveDisputeWithContext(context.Background(), id, data)
+}
+
+func (p *paystackClient) ResolveDisputeWithContext(ctx context.Context, id int, data *ResolveDisputeRequest) (*DisputeResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("dispute id is required")
+	}
+	if data.Resolution != DisputeResolutionMerchantAccepted && data.Resolution != DisputeResolutionDeclined {
+		return nil, fmt.Errorf("invalid dispute resolution %q", data.Resolution)
+	}
+	if data.Message == "" || data.UploadedFilename == "" {
+		return nil, fmt.Errorf("message and uploaded filename are required")
+	}
+	if data.RefundAmount < 0 {
+		return nil, fmt.Errorf("refund amount cannot be negative")
+	}
+
+	var response DisputeResponse
+	if err := p.doJSON(ctx, "PUT", "dispute/"+strconv.Itoa(id)+"/resolve", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,82 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisputeRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /dispute":
+			require.Equal(t, DisputeStatusAwaitingMerchantFeedback, r.URL.Query().Get("status"))
+			w.Write([]byte(`{"status":true,"message":"Disputes retrieved","data":[{"id":2867,"refund_amount":null,"currency":null,"status":"awaiting-merchant-feedback","resolution":null,"domain":"test","transaction":{"id":5991760,"reference":"dep-1","amount":39100,"currency":"NGN"},"category":"chargeback"}],"meta":{"total":1,"skipped":0,"perPage":50,"page":1,"pageCount":1}}`))
+		case "GET /dispute/2867":
+			w.Write([]byte(`{"status":true,"message":"Dispute retrieved","data":{"id":2867,"refund_amount":null,"currency":null,"status":"awaiting-merchant-feedback","resolution":null,"transaction":{"id":5991760,"reference":"dep-1","amount":39100,"currency":"NGN"},"category":"chargeback"}}`))
+		case "POST /dispute/2867/evidence":
+			var body UploadEvidenceRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "ada@example.com", body.CustomerEmail)
+			w.Write([]byte(`{"status":true,"message":"Evidence created","data":{"customer_email":"ada@example.com","customer_name":"Ada Obi","customer_phone":"08023456789","service_details":"Game credits","dispute":2867,"id":21,"createdAt":"2017-06-05T10:21:48.000Z"}}`))
+		case "GET /dispute/2867/upload_url":
+			require.Equal(t, "receipt.pdf", r.URL.Query().Get("upload_filename"))
+			w.Write([]byte(`{"status":true,"message":"Upload url generated","data":{"signedUrl":"https://files.example.com/upload","fileName":"qesp8a4df1xejihd9x5q"}}`))
+		case "PUT /dispute/2867/resolve":
+			var body ResolveDisputeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, int64(39100), body.RefundAmount)
+			require.Equal(t, 21, body.Evidence)
+			w.Write([]byte(`{"status":true,"message":"Dispute resolved","data":{"id":2867,"refund_amount":39100,"currency":"NGN","status":"resolved","resolution":"merchant-accepted","transaction":{"id":5991760,"reference":"dep-1","amount":39100,"currency":"NGN"},"category":"chargeback"}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	disputes, err := client.ListDisputes(&ListDisputesRequest{Status: DisputeStatusAwaitingMerchantFeedback})
+	require.NoError(t, err)
+	require.Len(t, disputes.Data, 1)
+
+	dispute, err := client.FetchDispute(2867)
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Hold, dispute.Data.TransactionStatus())
+	require.True(t, dispute.Data.RefundMoney().IsZero())
+
+	evidence, err := client.UploadEvidence(2867, &UploadEvidenceRequest{CustomerEmail: "ada@example.com", CustomerName: "Ada Obi", CustomerPhone: "08023456789", ServiceDetails: "Game credits"})
+	require.NoError(t, err)
+
+	upload, err := client.DisputeUploadURL(2867, "receipt.pdf")
+	require.NoError(t, err)
+
+	resolved, err := client.ResolveDispute(2867, &ResolveDisputeRequest{
+		Resolution:       DisputeResolutionMerchantAccepted,
+		Message:          "Refunding the customer",
+		RefundAmount:     dispute.Data.Transaction.Amount,
+		UploadedFilename: upload.Data.FileName,
+		Evidence:         evidence.Data.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Refunded, resolved.Data.TransactionStatus())
+	require.Equal(t, "391.00 NGN", resolved.Data.RefundMoney().String())
+
+	_, err = client.ResolveDispute(2867, &ResolveDisputeRequest{Resolution: "maybe", Message: "x", UploadedFilename: "y"})
+	require.EqualError(t, err, `invalid dispute resolution "maybe"`)
+	_, err = client.UploadEvidence(2867, &UploadEvidenceRequest{CustomerEmail: "ada@example.com"})
+	require.Error(t, err)
+}
+
+func TestMapDisputeStatus(t *testing.T) {
+	require.Equal(t, interfacesx.Hold, MapDisputeStatus(DisputeStatusAwaitingMerchantFeedback, ""))
+	require.Equal(t, interfacesx.Hold, MapDisputeStatus(DisputeStatusAwaitingBankFeedback, ""))
+	require.Equal(t, interfacesx.Hold, MapDisputeStatus(DisputeStatusPending, ""))
+	require.Equal(t, interfacesx.Refunded, MapDisputeStatus(DisputeStatusResolved, DisputeResolutionMerchantAccepted))
+	require.Equal(t, interfacesx.Completed, MapDisputeStatus(DisputeStatusResolved, DisputeResolutionDeclined))
+	require.Equal(t, interfacesx.Completed, MapDisputeStatus(DisputeStatusArchived, ""))
+}
@@ -0,0 +1,39 @@
+package paystackx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PaystackError is returned when Paystack responds with a non-2xx status or a
+// false status field.
+type PaystackError struct {
+	StatusCode int    `json:"-"`
+	Method     string `json:"-"`
+	Endpoint   string `json:"-"`
+	Message    string `json:"message"`
+	Code       string `json:"code"`
+	Type       string `json:"type"`
+	// RetryAfter is the back-off Paystack asked for in a Retry-After header.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *PaystackError) Error() string {
+	return fmt.Sprintf("paystack %s %s failed: %s", e.Method, e.Endpoint, e.Message)
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *PaystackError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// AsPaystackError returns the PaystackError wrapped in err, if any.
+func AsPaystackError(err error) (*PaystackError, bool) {
+	var paystackErr *PaystackError
+	if errors.As(err, &paystackErr) {
+		return paystackErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,272 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Event is a decoded Paystack webhook event. Use a type switch on the
+// concrete *...Event types to handle the events you care about; events
+// without a registered decoder come back as *UnknownEvent.
+type Event interface {
+	EventType() string
+}
+
+type ChargeSuccessEvent struct {
+	Data PaystackEventData `json:"data"`
+}
+
+func (e *ChargeSuccessEvent) EventType() string { return EventChargeSuccess }
+
+type TransferSuccessEvent struct {
+	Data TransferEventData `json:"data"`
+}
+
+func (e *TransferSuccessEvent) EventType() string { return EventTransferSuccess }
+
+type TransferFailedEvent struct {
+	Data TransferEventData `json:"data"`
+}
+
+func (e *TransferFailedEvent) EventType() string { return EventTransferFailed }
+
+type TransferReversedEvent struct {
+	Data TransferEventData `json:"data"`
+}
+
+func (e *TransferReversedEvent) EventType() string { return EventTransferReversed }
+
+type DedicatedAccountAssignSuccessEvent struct {
+	Data DedicatedAccountEventData `json:"data"`
+}
+
+func (e *DedicatedAccountAssignSuccessEvent) EventType() string {
+	return EventDedicatedAccountAssignSuccess
+}
+
+type DedicatedAccountAssignFailedEvent struct {
+	Data DedicatedAccountEventData `json:"data"`
+}
+
+func (e *DedicatedAccountAssignFailedEvent) EventType() string {
+	return EventDedicatedAccountAssignFailed
+}
+
+type SubscriptionCreateEvent struct {
+	Data Subscription `json:"data"`
+}
+
+func (e *SubscriptionCreateEvent) EventType() string { return EventSubscriptionCreate }
+
+type SubscriptionDisableEvent struct {
+	Data Subscription `json:"data"`
+}
+
+func (e *SubscriptionDisableEvent) EventType() string { return EventSubscriptionDisable }
+
+type SubscriptionNotRenewEvent struct {
+	Data Subscription `json:"data"`
+}
+
+func (e *SubscriptionNotRenewEvent) EventType() string { return EventSubscriptionNotRenew }
+
+type InvoiceCreateEvent struct {
+	Data InvoiceEventData `json:"data"`
+}
+
+func (e *InvoiceCreateEvent) EventType() string { return EventInvoiceCreate }
+
+type InvoiceUpdateEvent struct {
+	Data InvoiceEventData `json:"data"`
+}
+
+func (e *InvoiceUpdateEvent) EventType() string { return EventInvoiceUpdate }
+
+type InvoicePaymentFailedEvent struct {
+	Data InvoiceEventData `json:"data"`
+}
+
+func (e *InvoicePaymentFailedEvent) EventType() string { return EventInvoicePaymentFailed }
+
+type RefundPendingEvent struct {
+	Data RefundEventData `json:"data"`
+}
+
+func (e *RefundPendingEvent) EventType() string { return EventRefundPending }
+
+type RefundProcessingEvent struct {
+	Data RefundEventData `json:"data"`
+}
+
+func (e *RefundProcessingEvent) EventType() string { return EventRefundProcessing }
+
+type RefundProcessedEvent struct {
+	Data RefundEventData `json:"data"`
+}
+
+func (e *RefundProcessedEvent) EventType() string { return EventRefundProcessed }
+
+type RefundFailedEvent struct {
+	Data RefundEventData `json:"data"`
+}
+
+func (e *RefundFailedEvent) EventType() string { return EventRefundFailed }
+
+type ChargeDisputeCreateEvent struct {
+	Data Dispute `json:"data"`
+}
+
+func (e *ChargeDisputeCreateEvent) EventType() string { return EventChargeDisputeCreate }
+
+type ChargeDisputeRemindEvent struct {
+	Data Dispute `json:"data"`
+}
+
+func (e *ChargeDisputeRemindEvent) EventType() string { return EventChargeDisputeRemind }
+
+type ChargeDisputeResolveEvent struct {
+	Data Dispute `json:"data"`
+}
+
+func (e *ChargeDisputeResolveEvent) EventType() string { return EventChargeDisputeResolve }
+
+type CustomerIdentificationSuccessEvent struct {
+	Data CustomerIdentificationEventData `json:"data"`
+}
+
+func (e *CustomerIdentificationSuccessEvent) EventType() string {
+	return EventCustomerIdentificationSuccess
+}
+
+type CustomerIdentificationFailedEvent struct {
+	Data CustomerIdentificationEventData `json:"data"`
+}
+
+func (e *CustomerIdentificationFailedEvent) EventType() string {
+	return EventCustomerIdentificationFailed
+}
+
+// UnknownEvent is returned for events without a typed decoder so they can
+// still be logged or handled from the raw data.
+type UnknownEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (e *UnknownEvent) EventType() string { return e.Event }
+
+type eventDecoder func(data json.RawMessage) (Event, error)
+
+var eventDecoders = map[string]eventDecoder{
+	EventChargeSuccess: func(data json.RawMessage) (Event, error) {
+		event := &ChargeSuccessEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventTransferSuccess: func(data json.RawMessage) (Event, error) {
+		event := &TransferSuccessEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventTransferFailed: func(data json.RawMessage) (Event, error) {
+		event := &TransferFailedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventTransferReversed: func(data json.RawMessage) (Event, error) {
+		event := &TransferReversedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventDedicatedAccountAssignSuccess: func(data json.RawMessage) (Event, error) {
+		event := &DedicatedAccountAssignSuccessEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventDedicatedAccountAssignFailed: func(data json.RawMessage) (Event, error) {
+		event := &DedicatedAccountAssignFailedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventSubscriptionCreate: func(data json.RawMessage) (Event, error) {
+		event := &SubscriptionCreateEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventSubscriptionDisable: func(data json.RawMessage) (Event, error) {
+		event := &SubscriptionDisableEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventSubscriptionNotRenew: func(data json.RawMessage) (Event, error) {
+		event := &SubscriptionNotRenewEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventInvoiceCreate: func(data json.RawMessage) (Event, error) {
+		event := &InvoiceCreateEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventInvoiceUpdate: func(data json.RawMessage) (Event, error) {
+		event := &InvoiceUpdateEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventInvoicePaymentFailed: func(data json.RawMessage) (Event, error) {
+		event := &InvoicePaymentFailedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventRefundPending: func(data json.RawMessage) (Event, error) {
+		event := &RefundPendingEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventRefundProcessing: func(data json.RawMessage) (Event, error) {
+		event := &RefundProcessingEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventRefundProcessed: func(data json.RawMessage) (Event, error) {
+		event := &RefundProcessedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventRefundFailed: func(data json.RawMessage) (Event, error) {
+		event := &RefundFailedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventChargeDisputeCreate: func(data json.RawMessage) (Event, error) {
+		event := &ChargeDisputeCreateEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventChargeDisputeRemind: func(data json.RawMessage) (Event, error) {
+		event := &ChargeDisputeRemindEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventChargeDisputeResolve: func(data json.RawMessage) (Event, error) {
+		event := &ChargeDisputeResolveEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventCustomerIdentificationSuccess: func(data json.RawMessage) (Event, error) {
+		event := &CustomerIdentificationSuccessEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+	EventCustomerIdentificationFailed: func(data json.RawMessage) (Event, error) {
+		event := &CustomerIdentificationFailedEvent{}
+		return event, json.Unmarshal(data, &event.Data)
+	},
+}
+
+// DecodeEvent decodes a raw webhook body into the concrete struct for its event type.
+func DecodeEvent(body []byte) (Event, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+	return event.Decode()
+}
+
+// Decode returns the concrete struct for the event type.
+func (e *WebhookEvent) Decode() (Event, error) {
+	if e.Event == "" {
+		return nil, fmt.Errorf("invalid webhook payload: missing event")
+	}
+
+	decode, ok := eventDecoders[e.Event]
+	if !ok {
+		return &UnknownEvent{Event: e.Event, Data: e.Data}, nil
+	}
+
+	event, err := decode(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", e.Event, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,84 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestDecodeEventGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "events", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		input := input
+		t.Run(strings.TrimSuffix(filepath.Base(input), ".json"), func(t *testing.T) {
+			body, err := os.ReadFile(input)
+			require.NoError(t, err)
+
+			event, err := DecodeEvent(body)
+			require.NoError(t, err)
+
+			got, err := json.MarshalIndent(struct {
+				Type  string `json:"type"`
+				Event Event  `json:"event"`
+			}{fmt.Sprintf("%T", event), event}, "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(input, ".json") + ".golden"
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestDecodeEventTypes(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "events", "charge_success_dedicated_nuban.json"))
+	require.NoError(t, err)
+
+	event, err := DecodeEvent(body)
+	require.NoError(t, err)
+	charge, ok := event.(*ChargeSuccessEvent)
+	require.True(t, ok)
+	require.Equal(t, EventChargeSuccess, charge.EventType())
+	require.Equal(t, int64(500000), charge.Data.Amount)
+	require.Equal(t, int64(5000), *charge.Data.Fees)
+	require.Equal(t, "1234567890", charge.Data.Authorization.ReceiverBankAccountNumber)
+
+	var metadata struct {
+		ReceiverBank string `json:"receiver_bank"`
+	}
+	require.NoError(t, charge.Data.DecodeMetadata(&metadata))
+	require.Equal(t, "Test Bank", metadata.ReceiverBank)
+
+	event, err = DecodeEvent([]byte(transferSuccessBody))
+	require.NoError(t, err)
+	transfer, ok := event.(*TransferSuccessEvent)
+	require.True(t, ok)
+	require.Equal(t, int64(30000), transfer.Data.Amount)
+}
+
+func TestDecodeEventErrors(t *testing.T) {
+	_, err := DecodeEvent([]byte(`not json`))
+	require.Error(t, err)
+
+	_, err = DecodeEvent([]byte(`{"data":{}}`))
+	require.Error(t, err)
+
+	_, err = DecodeEvent([]byte(`{"event":"charge.success","data":{"amount":"ten"}}`))
+	require.Error(t, err)
+}
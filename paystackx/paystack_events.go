@@ -1,6 +1,9 @@
 package paystackx
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type UserVirtualAccountResponse struct {
 	Status  string                 `json:"status"`
@@ -35,6 +38,8 @@ type VirtaualAccountData struct {
 	UpdatedAt     string     `json:"updated_at"`
 	Assignment    Assignment `json:"assignment"`
 	Customer      Customer   `json:"customer"`
+	// SplitConfig is set when deposits to the account are split.
+	SplitConfig json.RawMessage `json:"split_config,omitempty"`
 }
 
 type Bank struct {
@@ -139,6 +144,7 @@ type LogEntry struct {
 }
 
 type PaystackEventLog struct {
+	StartTime      int64      `json:"start_time"`
 	TimeSpent      int        `json:"time_spent"`
 	Attempts       int        `json:"attempts"`
 	Authentication string     `json:"authentication"`
@@ -151,35 +157,61 @@ type PaystackEventLog struct {
 }
 
 type PaystackEventCustomer struct {
-	ID           int    `json:"id"`
-	FirstName    string `json:"first_name"`
-	LastName     string `json:"last_name"`
-	Email        string `json:"email"`
-	CustomerCode string `json:"customer_code"`
-	Phone        string `json:"phone"`
-	Metadata     string `json:"metadata"`
-	RiskAction   string `json:"risk_action"`
+	ID                       int             `json:"id"`
+	FirstName                string          `json:"first_name"`
+	LastName                 string          `json:"last_name"`
+	Email                    string          `json:"email"`
+	CustomerCode             string          `json:"customer_code"`
+	Phone                    string          `json:"phone"`
+	Metadata                 json.RawMessage `json:"metadata"`
+	RiskAction               string          `json:"risk_action"`
+	InternationalFormatPhone *string         `json:"international_format_phone"`
 }
 
 type PaystackEventAuthorization struct {
-	AuthorizationCode string `json:"authorization_code"`
-	Bin               string `json:"bin"`
-	Last4             string `json:"last4"`
-	ExpMonth          string `json:"exp_month"`
-	ExpYear           string `json:"exp_year"`
-	CardType          string `json:"card_type"`
-	Bank              string `json:"bank"`
-	CountryCode       string `json:"country_code"`
-	Brand             string `json:"brand"`
-	AccountName       string `json:"account_name"`
+	AuthorizationCode         string `json:"authorization_code"`
+	Bin                       string `json:"bin"`
+	Last4                     string `json:"last4"`
+	ExpMonth                  string `json:"exp_month"`
+	ExpYear                   string `json:"exp_year"`
+	Channel                   string `json:"channel"`
+	CardType                  string `json:"card_type"`
+	Bank                      string `json:"bank"`
+	CountryCode               string `json:"country_code"`
+	Brand                     string `json:"brand"`
+	Reusable                  bool   `json:"reusable"`
+	Signature                 string `json:"signature"`
+	AccountName               string `json:"account_name"`
+	SenderBank                string `json:"sender_bank"`
+	SenderBankAccountNumber   string `json:"sender_bank_account_number"`
+	SenderCountry             string `json:"sender_country"`
+	SenderName                string `json:"sender_name"`
+	Narration                 string `json:"narration"`
+	ReceiverBankAccountNumber string `json:"receiver_bank_account_number"`
+	ReceiverBank              string `json:"receiver_bank"`
 }
 
+type PaystackEventPlan struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	PlanCode    string `json:"plan_code"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+	Interval    string `json:"interval"`
+	SendInvoice bool   `json:"send_invoice"`
+	SendSMS     bool   `json:"send_sms"`
+	Currency    string `json:"currency"`
+}
+
+// PaystackEventData is the data of a charge event. Amounts are in the minor
+// unit of the currency (kobo for NGN).
 type PaystackEventData struct {
 	ID              int                        `json:"id"`
 	Domain          string                     `json:"domain"`
 	Status          string                     `json:"status"`
 	Reference       string                     `json:"reference"`
-	Amount          float64                    `json:"amount"`
+	Amount          int64                      `json:"amount"`
+	RequestedAmount int64                      `json:"requested_amount"`
 	Message         string                     `json:"message"`
 	GatewayResponse string                     `json:"gateway_response"`
 	PaidAt          string                     `json:"paid_at"`
@@ -187,12 +219,20 @@ type PaystackEventData struct {
 	Channel         string                     `json:"channel"`
 	Currency        string                     `json:"currency"`
 	IPAddress       string                     `json:"ip_address"`
-	Metadata        interface{}                `json:"metadata"`
-	Log             PaystackEventLog           `json:"log"`
-	Fees            interface{}                `json:"fees"`
-	Customer        Customer                   `json:"customer"`
+	Metadata        json.RawMessage            `json:"metadata"`
+	Log             *PaystackEventLog          `json:"log"`
+	Fees            *int64                     `json:"fees"`
+	Customer        PaystackEventCustomer      `json:"customer"`
 	Authorization   PaystackEventAuthorization `json:"authorization"`
-	Plan            interface{}                `json:"plan"`
+	Plan            *PaystackEventPlan         `json:"plan"`
+}
+
+// DecodeMetadata unmarshals the free-form charge metadata into v.
+func (d *PaystackEventData) DecodeMetadata(v interface{}) error {
+	if len(d.Metadata) == 0 {
+		return nil
+	}
+	return json.Unmarshal(d.Metadata, v)
 }
 
 type PaystackEventPayload struct {
@@ -200,13 +240,19 @@ type PaystackEventPayload struct {
 	Data  PaystackEventData `json:"data"`
 }
 
+// PaystackCreateTransferRecipientRequest creates a transfer recipient. Bank
+// and mobile money recipients need AccountNumber and BankCode; authorization
+// recipients, paid to a card, need AuthorizationCode instead.
 type PaystackCreateTransferRecipientRequest struct {
-	Type          string   `json:"type"`
-	Name          string   `json:"name"`
-	AccountNumber string   `json:"account_number"`
-	BankCode      string   `json:"bank_code"`
-	Currency      string   `json:"currency"`
-	Metadata      Metadata `json:"metadata"`
+	Type              string   `json:"type"`
+	Name              string   `json:"name"`
+	AccountNumber     string   `json:"account_number"`
+	BankCode          string   `json:"bank_code"`
+	Currency          string   `json:"currency"`
+	Description       string   `json:"description,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	AuthorizationCode string   `json:"authorization_code,omitempty"`
+	Metadata          Metadata `json:"metadata"`
 }
 
 type CreateTransferRecipientResponse struct {
@@ -234,13 +280,16 @@ type CreateTransferRecipientResponse struct {
 	} `json:"data"`
 }
 
+// TransferFundsRequest starts a transfer. AmountMinor is in the minor unit of
+// the currency, kobo for NGN; NewTransferFundsRequest builds one from a
+// moneyx.Money.
 type TransferFundsRequest struct {
-	Source    string  `json:"source"`
-	Reason    string  `json:"reason"`
-	Amount    float64 `json:"amount"`
-	Recipient string  `json:"recipient"`
-	Reference string  `json:"reference"`
-	Currency  string  `json:"currency"`
+	Source      string `json:"source"`
+	Reason      string `json:"reason"`
+	AmountMinor int64  `json:"amount"`
+	Recipient   string `json:"recipient"`
+	Reference   string `json:"reference"`
+	Currency    string `json:"currency"`
 }
 
 type TransferOTPResponse struct {
@@ -249,7 +298,7 @@ type TransferOTPResponse struct {
 	Data    struct {
 		Integration  int    `json:"integration"`
 		Domain       string `json:"domain"`
-		Amount       int    `json:"amount"`
+		Amount       int64  `json:"amount"`
 		Currency     string `json:"currency"`
 		Source       string `json:"source"`
 		Reason       string `json:"reason"`
@@ -262,12 +311,13 @@ type TransferOTPResponse struct {
 	} `json:"data"`
 }
 
+// BalanceResponse lists the balance per currency in minor units.
 type BalanceResponse struct {
 	Status  bool   `json:"status"`
 	Message string `json:"message"`
 	Data    []struct {
-		Currency string  `json:"currency"`
-		Balance  float64 `json:"balance"`
+		Currency string `json:"currency"`
+		Balance  int64  `json:"balance"`
 	} `json:"data"`
 }
 
@@ -289,9 +339,18 @@ type Banks struct {
 }
 
 type Meta struct {
-	Next     string  `json:"next"`
-	Previous *string `json:"previous"`
-	PerPage  int     `json:"perPage"`
+	Next      string  `json:"next"`
+	Previous  *string `json:"previous"`
+	PerPage   int     `json:"perPage"`
+	Total     int     `json:"total"`
+	Skipped   int     `json:"skipped"`
+	Page      int     `json:"page"`
+	PageCount int     `json:"pageCount"`
+}
+
+// HasNextPage reports whether a page-numbered list has more pages after this one.
+func (m Meta) HasNextPage() bool {
+	return m.Page > 0 && m.Page < m.PageCount
 }
 
 type BanksResponse struct {
@@ -312,3 +371,74 @@ type AccountResponse struct {
 	Message string      `json:"message"`
 	Data    AccountData `json:"data"`
 }
+
+type PaystackIntegration struct {
+	ID           int    `json:"id"`
+	IsLive       bool   `json:"is_live"`
+	BusinessName string `json:"business_name"`
+}
+
+type TransferRecipientDetails struct {
+	AuthorizationCode *string `json:"authorization_code"`
+	AccountNumber     string  `json:"account_number"`
+	AccountName       *string `json:"account_name"`
+	BankCode          string  `json:"bank_code"`
+	BankName          string  `json:"bank_name"`
+}
+
+type TransferRecipient struct {
+	Active        bool                     `json:"active"`
+	Currency      string                   `json:"currency"`
+	Description   string                   `json:"description"`
+	Domain        string                   `json:"domain"`
+	Email         *string                  `json:"email"`
+	ID            int                      `json:"id"`
+	Integration   int                      `json:"integration"`
+	Metadata      json.RawMessage          `json:"metadata"`
+	Name          string                   `json:"name"`
+	RecipientCode string                   `json:"recipient_code"`
+	Type          string                   `json:"type"`
+	IsDeleted     bool                     `json:"is_deleted"`
+	Details       TransferRecipientDetails `json:"details"`
+	CreatedAt     string                   `json:"created_at"`
+	UpdatedAt     string                   `json:"updated_at"`
+}
+
+type TransferSession struct {
+	Provider *string `json:"provider"`
+	ID       *string `json:"id"`
+}
+
+type TransferEventData struct {
+	Amount        int64               `json:"amount"`
+	Currency      string              `json:"currency"`
+	Domain        string              `json:"domain"`
+	Failures      json.RawMessage     `json:"failures"`
+	ID            int                 `json:"id"`
+	Integration   PaystackIntegration `json:"integration"`
+	Reason        string              `json:"reason"`
+	Reference     string              `json:"reference"`
+	Source        string              `json:"source"`
+	Status        string              `json:"status"`
+	TransferCode  string              `json:"transfer_code"`
+	TransferredAt *string             `json:"transferred_at"`
+	Recipient     TransferRecipient   `json:"recipient"`
+	Session       TransferSession     `json:"session"`
+	CreatedAt     string              `json:"created_at"`
+	UpdatedAt     string              `json:"updated_at"`
+}
+
+type DedicatedAccountIdentification struct {
+	Status        string `json:"status"`
+	Country       string `json:"country"`
+	Type          string `json:"type"`
+	BVN           string `json:"bvn"`
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+}
+
+type DedicatedAccountEventData struct {
+	Customer         PaystackEventCustomer          `json:"customer"`
+	DedicatedAccount *VirtaualAccountData           `json:"dedicated_account"`
+	Identification   DedicatedAccountIdentification `json:"identification"`
+}
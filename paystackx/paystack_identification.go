@@ -0,0 +1,102 @@
+package paystackx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+)
+
+// IdentificationTypeBankAccount validates a customer by matching their BVN
+// against a bank account they own.
+const IdentificationTypeBankAccount = "bank_account"
+
+// ValidateCustomerRequest identifies a customer. Country defaults to "NG" and
+// Type to IdentificationTypeBankAccount, which requires BVN, BankCode and
+// AccountNumber. The names must match the ones on the BVN.
+type ValidateCustomerRequest struct {
+	Country       string `json:"country"`
+	Type          string `json:"type"`
+	AccountNumber string `json:"account_number,omitempty"`
+	BVN           string `json:"bvn"`
+	BankCode      string `json:"bank_code,omitempty"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	MiddleName    string `json:"middle_name,omitempty"`
+}
+
+// CustomerIdentificationEventData is the data of the customeridentification.*
+// webhooks. Reason is only set when identification failed.
+type CustomerIdentificationEventData struct {
+	CustomerID     string                         `json:"customer_id"`
+	CustomerCode   string                         `json:"customer_code"`
+	Email          string                         `json:"email"`
+	Identification DedicatedAccountIdentification `json:"identification"`
+	Reason         string                         `json:"reason,omitempty"`
+}
+
+// MapIdentificationEvent converts a customeridentification event to the
+// customer's KycStatus. Any other event leaves the customer Processing.
+func MapIdentificationEvent(event string) interfacesx.KycStatus {
+	switch event {
+	case EventCustomerIdentificationSuccess:
+		return interfacesx.ApprovedKyc
+	case EventCustomerIdentificationFailed:
+		return interfacesx.RejectedKyc
+	}
+	return interfacesx.ProcessingKyc
+}
+
+// KycStatus is ApprovedKyc for identified customers and PendingKyc otherwise.
+func (d *Data) KycStatus() interfacesx.KycStatus {
+	if d.Identified {
+		return interfacesx.ApprovedKyc
+	}
+	return interfacesx.PendingKyc
+}
+
+// KycStatus is ApprovedKyc for identified customers and PendingKyc otherwise.
+func (d *UpdateUserData) KycStatus() interfacesx.KycStatus {
+	if d.Identified {
+		return interfacesx.ApprovedKyc
+	}
+	return interfacesx.PendingKyc
+}
+
+// ValidateCustomer starts identification of a customer. The customer is
+// ProcessingKyc until the customeridentification.success or
+// customeridentification.failed webhook arrives.
+func (p *paystackClient) ValidateCustomer(customerCode string, data *ValidateCustomerRequest) (*MessageResponse, error) {
+	return p.ValidateCustomerWithContext(context.Background(), customerCode, data)
+}
+
+func (p *paystackClient) ValidateCustomerWithContext(ctx context.Context, customerCode string, data *ValidateCustomerRequest) (*MessageResponse, error) {
+	if customerCode == "" {
+		return nil, fmt.Errorf("customer code is required")
+	}
+	// Defaults go on a copy so the caller's request is left as it was.
+	request := *data
+	if request.Country == "" {
+		request.Country = "NG"
+	}
+	if request.Type == "" {
+		request.Type = IdentificationTypeBankAccount
+	}
+	if request.Type != IdentificationTypeBankAccount {
+		return nil, fmt.Errorf("unsupported identification type %q", request.Type)
+	}
+	if request.BVN == "" || request.BankCode == "" || request.AccountNumber == "" {
+		return nil, fmt.Errorf("BVN, bank code and account number are required")
+	}
+	if strings.TrimSpace(request.FirstName) == "" || strings.TrimSpace(request.LastName) == "" {
+		return nil, fmt.Errorf("first name and last name are required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "customer/"+customerCode+"/identification", request, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,73 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCustomer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/customer/CUS_xnxdt6s1zg1f4nx/identification", r.URL.Path)
+		var body ValidateCustomerRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, ValidateCustomerRequest{Country: "NG", Type: IdentificationTypeBankAccount, AccountNumber: "0123456789", BVN: "20012345677", BankCode: "007", FirstName: "Asta", LastName: "Lavista"}, body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":true,"message":"Customer Identification in progress"}`))
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	request := &ValidateCustomerRequest{AccountNumber: "0123456789", BVN: "20012345677", BankCode: "007", FirstName: "Asta", LastName: "Lavista"}
+	response, err := client.ValidateCustomer("CUS_xnxdt6s1zg1f4nx", request)
+	require.NoError(t, err)
+	require.Equal(t, "Customer Identification in progress", response.Message)
+	require.Empty(t, request.Country)
+	require.Empty(t, request.Type)
+
+	_, err = client.ValidateCustomer("", &ValidateCustomerRequest{})
+	require.EqualError(t, err, "customer code is required")
+	_, err = client.ValidateCustomer("CUS_xnxdt6s1zg1f4nx", &ValidateCustomerRequest{Type: "passport"})
+	require.EqualError(t, err, `unsupported identification type "passport"`)
+	_, err = client.ValidateCustomer("CUS_xnxdt6s1zg1f4nx", &ValidateCustomerRequest{BVN: "20012345677"})
+	require.EqualError(t, err, "BVN, bank code and account number are required")
+	_, err = client.ValidateCustomer("CUS_xnxdt6s1zg1f4nx", &ValidateCustomerRequest{AccountNumber: "0123456789", BVN: "20012345677", BankCode: "007"})
+	require.EqualError(t, err, "first name and last name are required")
+}
+
+func TestMapIdentificationEvent(t *testing.T) {
+	require.Equal(t, interfacesx.ApprovedKyc, MapIdentificationEvent(EventCustomerIdentificationSuccess))
+	require.Equal(t, interfacesx.RejectedKyc, MapIdentificationEvent(EventCustomerIdentificationFailed))
+	require.Equal(t, interfacesx.ProcessingKyc, MapIdentificationEvent(EventChargeSuccess))
+
+	require.Equal(t, interfacesx.ApprovedKyc, (&Data{Identified: true}).KycStatus())
+	require.Equal(t, interfacesx.PendingKyc, (&UpdateUserData{}).KycStatus())
+}
+
+func TestWebhookUpdatesUsersKYC(t *testing.T) {
+	kyc := interfacesx.UsersKYC{Status: interfacesx.ProcessingKyc}
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+	handler.OnCustomerIdentification(func(c *gin.Context, event string, data *CustomerIdentificationEventData) error {
+		require.Equal(t, "CUS_XXXXXXXXXXXXXXX", data.CustomerCode)
+		require.Equal(t, "Account number or BVN is incorrect", data.Reason)
+		kyc.Status = MapIdentificationEvent(event)
+		return nil
+	})
+	router := newWebhookRouter(handler)
+
+	body, err := os.ReadFile(filepath.Join("testdata", "events", "customeridentification_failed.json"))
+	require.NoError(t, err)
+	recorder := deliverWebhook(router, string(body), SignPayload(testSecretKey, body), "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, interfacesx.RejectedKyc, kyc.Status)
+}
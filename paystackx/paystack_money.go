@@ -0,0 +1,62 @@
+package paystackx
+
+import (
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// NewTransferFundsRequest builds a transfer of amount from the balance.
+func NewTransferFundsRequest(amount moneyx.Money, recipient, reference, reason string) *TransferFundsRequest {
+	return &TransferFundsRequest{
+		Source:      "balance",
+		Reason:      reason,
+		AmountMinor: amount.Minor(),
+		Recipient:   recipient,
+		Reference:   reference,
+		Currency:    amount.Currency(),
+	}
+}
+
+// Money returns the charged amount.
+func (d *PaystackEventData) Money() moneyx.Money {
+	return moneyx.New(d.Amount, d.Currency)
+}
+
+// FeesMoney returns the Paystack fees, or zero when they are not reported.
+func (d *PaystackEventData) FeesMoney() moneyx.Money {
+	if d.Fees == nil {
+		return moneyx.Zero(d.Currency)
+	}
+	return moneyx.New(*d.Fees, d.Currency)
+}
+
+// Money returns the plan amount.
+func (p *PaystackEventPlan) Money() moneyx.Money {
+	return moneyx.New(p.Amount, p.Currency)
+}
+
+// Money returns the transferred amount.
+func (d *TransferEventData) Money() moneyx.Money {
+	return moneyx.New(d.Amount, d.Currency)
+}
+
+// Money returns the transferred amount.
+func (t *Transfer) Money() moneyx.Money {
+	return moneyx.New(t.Amount, t.Currency)
+}
+
+// Money returns the transferred amount.
+func (r *BulkTransferResult) Money() moneyx.Money {
+	return moneyx.New(r.Amount, r.Currency)
+}
+
+// Balance returns the balance in currency and whether Paystack reported one.
+func (r *BalanceResponse) Balance(currency string) (moneyx.Money, bool) {
+	for _, balance := range r.Data {
+		if strings.EqualFold(balance.Currency, currency) {
+			return moneyx.New(balance.Balance, balance.Currency), true
+		}
+	}
+	return moneyx.Zero(currency), false
+}
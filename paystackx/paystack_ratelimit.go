@@ -0,0 +1,257 @@
+package paystackx
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket: Rate requests per second on average, with
+// bursts of up to Burst requests. A zero Rate disables limiting.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitStats holds the counters collected for one limit.
+type RateLimitStats struct {
+	// Requests counts every request that went through the limiter.
+	Requests int64 `json:"requests"`
+	// Throttled counts requests the limiter held back before sending.
+	Throttled int64 `json:"throttled"`
+	// Rejected counts requests Paystack answered with 429 Too Many Requests.
+	Rejected int64 `json:"rejected"`
+	// WaitTime is the total time requests spent held back.
+	WaitTime time.Duration `json:"waitTime"`
+}
+
+type rateBucket struct {
+	limit  RateLimit
+	tokens float64
+	// last is when tokens was last refilled. It is in the future while the
+	// bucket is paused by a Retry-After header.
+	last  time.Time
+	stats RateLimitStats
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it.
+func (b *rateBucket) reserve(now time.Time) time.Duration {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+		if burst := float64(b.burst()); b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+
+	b.tokens--
+	var delay time.Duration
+	if b.last.After(now) {
+		delay = b.last.Sub(now)
+	}
+	if b.tokens < 0 {
+		delay += time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+	}
+	return delay
+}
+
+func (b *rateBucket) burst() int {
+	if b.limit.Burst < 1 {
+		return 1
+	}
+	return b.limit.Burst
+}
+
+// RateLimiter throttles requests to Paystack with a token bucket per
+// endpoint. Endpoints without their own limit share the default bucket. One
+// limiter may be shared by several clients and is safe for concurrent use.
+type RateLimiter struct {
+	defaultLimit RateLimit
+	limits       map[string]RateLimit
+	buckets      map[string]*rateBucket
+	now          func() time.Time
+	mu           sync.Mutex
+}
+
+// NewRateLimiter creates a limiter that applies defaultLimit to every endpoint.
+func NewRateLimiter(defaultLimit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		defaultLimit: defaultLimit,
+		limits:       make(map[string]RateLimit),
+		buckets:      make(map[string]*rateBucket),
+		now:          time.Now,
+	}
+}
+
+// DefaultRateLimiter allows 10 requests per second with bursts of 20, and
+// holds the endpoints bulk jobs hit hardest, account resolution and recipient
+// creation, to 5 per second.
+func DefaultRateLimiter() *RateLimiter {
+	limiter := NewRateLimiter(RateLimit{Rate: 10, Burst: 20})
+	limiter.SetLimit("bank/resolve", RateLimit{Rate: 5, Burst: 5})
+	limiter.SetLimit("transferrecipient", RateLimit{Rate: 5, Burst: 10})
+	return limiter
+}
+
+// WithRateLimiter throttles the client's requests with limiter. Clients have
+// no limiter by default.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(p *paystackClient) {
+		p.limiter = limiter
+	}
+}
+
+// SetLimit gives endpoints under prefix, for example "bank/resolve", their own
+// bucket. The longest matching prefix wins.
+func (l *RateLimiter) SetLimit(prefix string, limit RateLimit) {
+	prefix = strings.Trim(prefix, "/")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[prefix] = limit
+	if bucket, ok := l.buckets[prefix]; ok {
+		bucket.limit = limit
+	}
+}
+
+// Stats returns the counters for a prefix passed to SetLimit. The empty
+// prefix returns the counters of the default bucket.
+func (l *RateLimiter) Stats(prefix string) RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[strings.Trim(prefix, "/")]; ok {
+		return bucket.stats
+	}
+	return RateLimitStats{}
+}
+
+// Totals returns the counters of every bucket added together.
+func (l *RateLimiter) Totals() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var totals RateLimitStats
+	for _, bucket := range l.buckets {
+		totals.Requests += bucket.stats.Requests
+		totals.Throttled += bucket.stats.Throttled
+		totals.Rejected += bucket.stats.Rejected
+		totals.WaitTime += bucket.stats.WaitTime
+	}
+	return totals
+}
+
+// Prefixes returns the prefixes that have seen requests so far, sorted. The
+// default bucket is listed as the empty prefix.
+func (l *RateLimiter) Prefixes() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	prefixes := make([]string, 0, len(l.buckets))
+	for prefix := range l.buckets {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// Wait blocks until a request to endpoint may be sent, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	l.mu.Lock()
+	bucket := l.bucket(endpoint)
+	bucket.stats.Requests++
+	if bucket.limit.Rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := bucket.reserve(l.now())
+	if delay > 0 {
+		bucket.stats.Throttled++
+		bucket.stats.WaitTime += delay
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		bucket.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reject records a 429 response for endpoint and, when Paystack said how long
+// to back off, pauses the endpoint's bucket for that long.
+func (l *RateLimiter) reject(endpoint string, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket := l.bucket(endpoint)
+	bucket.stats.Rejected++
+	if retryAfter <= 0 {
+		return
+	}
+	// One token is left so a single request goes out as soon as the pause
+	// ends; the rest wait for the bucket to refill.
+	if until := l.now().Add(retryAfter); until.After(bucket.last) {
+		bucket.last = until
+		bucket.tokens = 1
+	}
+}
+
+// bucket must be called with l.mu held.
+func (l *RateLimiter) bucket(endpoint string) *rateBucket {
+	prefix := l.prefix(endpoint)
+	bucket, ok := l.buckets[prefix]
+	if !ok {
+		limit, ok := l.limits[prefix]
+		if !ok {
+			limit = l.defaultLimit
+		}
+		bucket = &rateBucket{limit: limit, last: l.now()}
+		bucket.tokens = float64(bucket.burst())
+		l.buckets[prefix] = bucket
+	}
+	return bucket
+}
+
+// prefix returns the longest configured prefix matching endpoint, or "" for
+// the default bucket. It must be called with l.mu held.
+func (l *RateLimiter) prefix(endpoint string) string {
+	path := strings.Trim(strings.SplitN(endpoint, "?", 2)[0], "/")
+	best := ""
+	for prefix := range l.limits {
+		if len(prefix) > len(best) && (path == prefix || strings.HasPrefix(path, prefix+"/")) {
+			best = prefix
+		}
+	}
+	return best
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
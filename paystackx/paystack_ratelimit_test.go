@@ -0,0 +1,140 @@
+package paystackx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterBuckets(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(RateLimit{Rate: 10, Burst: 2})
+	limiter.SetLimit("bank", RateLimit{Rate: 1, Burst: 1})
+	limiter.SetLimit("/bank/resolve/", RateLimit{Rate: 2, Burst: 1})
+	limiter.now = func() time.Time { return now }
+
+	require.Equal(t, "bank/resolve", limiter.prefix("bank/resolve?account_number=0123456789&bank_code=058"))
+	require.Equal(t, "bank", limiter.prefix("bank?country=nigeria"))
+	require.Equal(t, "", limiter.prefix("banking"))
+
+	// The burst goes out straight away; the next request waits for a refill.
+	require.NoError(t, limiter.Wait(context.Background(), "balance"))
+	require.NoError(t, limiter.Wait(context.Background(), "transfer"))
+	require.Equal(t, 100*time.Millisecond, limiter.bucket("balance").reserve(now))
+
+	// A caller that gives up hands its token back.
+	resolve := limiter.bucket("bank/resolve")
+	require.NoError(t, limiter.Wait(context.Background(), "bank/resolve"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, limiter.Wait(ctx, "bank/resolve"), context.Canceled)
+	require.Equal(t, float64(0), resolve.tokens)
+
+	// Retry-After pauses the bucket and lets one request out when it ends.
+	limiter.reject("bank/resolve?account_number=1", 3*time.Second)
+	require.Equal(t, 3*time.Second, resolve.reserve(now))
+	require.Equal(t, 3500*time.Millisecond, resolve.reserve(now))
+	now = now.Add(4 * time.Second)
+	require.Equal(t, time.Duration(0), resolve.reserve(now))
+
+	stats := limiter.Stats("bank/resolve")
+	require.Equal(t, int64(2), stats.Requests)
+	require.Equal(t, int64(1), stats.Throttled)
+	require.Equal(t, int64(1), stats.Rejected)
+	require.Equal(t, 500*time.Millisecond, stats.WaitTime)
+	require.Equal(t, []string{"", "bank/resolve"}, limiter.Prefixes())
+	require.Equal(t, int64(4), limiter.Totals().Requests)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	require.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	require.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":false,"message":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"status":true,"message":"Balances retrieved","data":[{"currency":"NGN","balance":1500000}]}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimit{Rate: 100, Burst: 10})
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry), WithRateLimiter(limiter))
+
+	start := time.Now()
+	_, err := client.FetchBalance()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	stats := limiter.Stats("")
+	require.Equal(t, int64(2), stats.Requests)
+	require.Equal(t, int64(1), stats.Rejected)
+}
+
+func TestRateLimitedErrorCarriesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "20")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(NoRetry())).FetchBalance()
+	paystackErr, ok := AsPaystackError(err)
+	require.True(t, ok)
+	require.True(t, paystackErr.Temporary())
+	require.Equal(t, 20*time.Second, paystackErr.RetryAfter)
+}
+
+func TestRateLimiterSharedAcrossGoroutines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":true,"message":"Account number resolved","data":{"account_number":"0123456789","account_name":"ADA OBI","bank_id":9}}`))
+	}))
+	defer server.Close()
+
+	limiter := DefaultRateLimiter()
+	limiter.SetLimit("bank/resolve", RateLimit{Rate: 200, Burst: 2})
+	first := NewPaystackClient(server.URL, testSecretKey, WithRateLimiter(limiter))
+	second := NewPaystackClient(server.URL, testSecretKey, WithRateLimiter(limiter))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		client := first
+		if i%2 == 1 {
+			client = second
+		}
+		wg.Add(1)
+		go func(client PaystackService) {
+			defer wg.Done()
+			_, err := client.ResolveAccountNumber(&interfacesx.ResolveBankAccountRequest{AccountNumber: "0123456789", BankCode: "058"})
+			require.NoError(t, err)
+		}(client)
+	}
+	wg.Wait()
+
+	// Two go out at once; the other ten are spaced 5ms apart.
+	require.GreaterOrEqual(t, time.Since(start), 45*time.Millisecond)
+	stats := limiter.Stats("bank/resolve")
+	require.Equal(t, int64(12), stats.Requests)
+	require.Equal(t, int64(10), stats.Throttled)
+	require.Equal(t, RateLimitStats{}, limiter.Stats(""))
+}
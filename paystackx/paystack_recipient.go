@@ -0,0 +1,413 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/helperfuncx"
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+)
+
+// Transfer recipient types: Nigerian bank accounts (nuban), Ghanaian bank
+// accounts (ghipss), mobile money wallets in Ghana and Kenya (mobile_money)
+// and South African bank accounts (basa).
+const (
+	RecipientTypeNuban       = "nuban"
+	RecipientTypeGhipss      = "ghipss"
+	RecipientTypeMobileMoney = "mobile_money"
+	RecipientTypeBasa        = "basa"
+)
+
+// recipientCurrencies lists the currencies the recipient types checked by
+// validate can be paid in. Other types, such as nuban and authorization, are
+// left for Paystack to check.
+var recipientCurrencies = map[string][]string{
+	RecipientTypeGhipss:      {"GHS"},
+	RecipientTypeMobileMoney: {"GHS", "KES"},
+	RecipientTypeBasa:        {"ZAR"},
+}
+
+// bankRecipientTypes is the bank account recipient type of each currency.
+var bankRecipientTypes = map[string]string{
+	"NGN": RecipientTypeNuban,
+	"GHS": RecipientTypeGhipss,
+	"ZAR": RecipientTypeBasa,
+}
+
+// mobileMoneyProviders maps the bank codes of well-known mobile money
+// providers to the currency of their wallets. It is not exhaustive: Paystack
+// lists the providers of a country with ListBanks and type mobile_money, and
+// codes missing here are passed through.
+var mobileMoneyProviders = map[string]string{
+	"MTN":   "GHS",
+	"VOD":   "GHS",
+	"ATL":   "GHS",
+	"MPESA": "KES",
+}
+
+// mobileDialingCodes are the country codes stripped from mobile money numbers.
+var mobileDialingCodes = map[string]string{
+	"GHS": "233",
+	"KES": "254",
+}
+
+// IsMobileMoneyProvider reports whether bankCode is a mobile money provider,
+// such as "MTN" in Ghana or "MPESA" in Kenya.
+func IsMobileMoneyProvider(bankCode string) bool {
+	_, ok := mobileMoneyProviders[strings.ToUpper(bankCode)]
+	return ok
+}
+
+// RecipientTypeFor returns the recipient type for paying an account at
+// bankCode in currency: mobile_money for mobile money providers, otherwise the
+// bank account type of the currency.
+func RecipientTypeFor(currency, bankCode string) (string, error) {
+	currency = strings.ToUpper(currency)
+	if walletCurrency, ok := mobileMoneyProviders[strings.ToUpper(bankCode)]; ok {
+		if walletCurrency != currency {
+			return "", fmt.Errorf("%s wallets hold %s, not %s", strings.ToUpper(bankCode), walletCurrency, currency)
+		}
+		return RecipientTypeMobileMoney, nil
+	}
+	if recipientType, ok := bankRecipientTypes[currency]; ok {
+		return recipientType, nil
+	}
+	return "", fmt.Errorf("no Paystack bank recipient type for %s", currency)
+}
+
+// NewNubanRecipient builds a recipient for a Nigerian bank account.
+func NewNubanRecipient(name, accountNumber, bankCode string) *PaystackCreateTransferRecipientRequest {
+	return &PaystackCreateTransferRecipientRequest{Type: RecipientTypeNuban, Name: name, AccountNumber: accountNumber, BankCode: bankCode, Currency: "NGN"}
+}
+
+// NewGhipssRecipient builds a recipient for a Ghanaian bank account.
+func NewGhipssRecipient(name, accountNumber, bankCode string) *PaystackCreateTransferRecipientRequest {
+	return &PaystackCreateTransferRecipientRequest{Type: RecipientTypeGhipss, Name: name, AccountNumber: accountNumber, BankCode: bankCode, Currency: "GHS"}
+}
+
+// NewBasaRecipient builds a recipient for a South African bank account.
+func NewBasaRecipient(name, accountNumber, bankCode string) *PaystackCreateTransferRecipientRequest {
+	return &PaystackCreateTransferRecipientRequest{Type: RecipientTypeBasa, Name: name, AccountNumber: accountNumber, BankCode: bankCode, Currency: "ZAR"}
+}
+
+// NewMobileMoneyRecipient builds a recipient for a mobile money wallet. The
+// currency follows from the provider when it is a well-known one; set it on
+// the result for other providers. phoneNumber may be given in international
+// format; it is sent in the local format Paystack expects.
+func NewMobileMoneyRecipient(name, phoneNumber, providerCode string) *PaystackCreateTransferRecipientRequest {
+	providerCode = strings.ToUpper(providerCode)
+	currency := mobileMoneyProviders[providerCode]
+	return &PaystackCreateTransferRecipientRequest{
+		Type:          RecipientTypeMobileMoney,
+		Name:          name,
+		AccountNumber: localMobileNumber(phoneNumber, currency),
+		BankCode:      providerCode,
+		Currency:      currency,
+	}
+}
+
+// localMobileNumber turns an international mobile number such as
+// "+233 55 123 4987" into the local "0551234987".
+func localMobileNumber(number, currency string) string {
+	number = helperfuncx.NormalizePhoneNumber(number)
+	if code, ok := mobileDialingCodes[currency]; ok && strings.HasPrefix(number, code) && len(number) == len(code)+9 {
+		return "0" + number[len(code):]
+	}
+	return number
+}
+
+// validMobileNumber reports whether number is made of digits only. Lengths
+// differ between countries and providers, so they are left for Paystack to
+// check.
+func validMobileNumber(number string) bool {
+	if number == "" {
+		return false
+	}
+	for _, digit := range number {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validate checks that a ghipss, mobile_money or basa recipient has account
+// details and can be paid in its currency. Other recipient types are sent as
+// they are: authorization recipients carry an authorization code instead of
+// account details, and Paystack checks nuban recipients itself.
+func (r *PaystackCreateTransferRecipientRequest) validate() error {
+	currencies, ok := recipientCurrencies[r.Type]
+	if !ok {
+		return nil
+	}
+	if r.AccountNumber == "" || r.BankCode == "" {
+		return fmt.Errorf("account number and bank code are required")
+	}
+
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		return fmt.Errorf("currency is required for %s recipients", r.Type)
+	}
+	supported := false
+	for _, c := range currencies {
+		supported = supported || c == currency
+	}
+	if !supported {
+		return fmt.Errorf("%s recipients cannot be paid in %s", r.Type, currency)
+	}
+
+	if r.Type != RecipientTypeMobileMoney {
+		return nil
+	}
+	if walletCurrency, ok := mobileMoneyProviders[strings.ToUpper(r.BankCode)]; ok && walletCurrency != currency {
+		return fmt.Errorf("%s wallets hold %s, not %s", strings.ToUpper(r.BankCode), walletCurrency, currency)
+	}
+	if !validMobileNumber(r.AccountNumber) {
+		return fmt.Errorf("invalid mobile money number %s", r.AccountNumber)
+	}
+	return nil
+}
+
+type TransferRecipientResponse struct {
+	Status  bool              `json:"status"`
+	Message string            `json:"message"`
+	Data    TransferRecipient `json:"data"`
+}
+
+// ListTransferRecipientsRequest filters ListTransferRecipients. Zero values
+// are omitted.
+type ListTransferRecipientsRequest struct {
+	PerPage int
+	Page    int
+	From    time.Time
+	To      time.Time
+}
+
+func (r *ListTransferRecipientsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListTransferRecipientsResponse struct {
+	Status  bool                `json:"status"`
+	Message string              `json:"message"`
+	Data    []TransferRecipient `json:"data"`
+	Meta    Meta                `json:"meta"`
+}
+
+type UpdateTransferRecipientRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type BulkTransferRecipientRequest struct {
+	Batch []PaystackCreateTransferRecipientRequest `json:"batch"`
+}
+
+// BulkRecipientError is a batch entry Paystack could not create.
+type BulkRecipientError struct {
+	Error   string                                 `json:"error"`
+	Payload PaystackCreateTransferRecipientRequest `json:"payload"`
+}
+
+// BulkTransferRecipientResponse lists the created recipients and the entries
+// that failed. A batch can partly succeed.
+type BulkTransferRecipientResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		Success []TransferRecipient  `json:"success"`
+		Errors  []BulkRecipientError `json:"errors"`
+	} `json:"data"`
+}
+
+// UnmarshalJSON accepts both timestamp spellings Paystack uses: webhooks send
+// created_at and updated_at, the recipient and transfer endpoints createdAt
+// and updatedAt.
+func (r *TransferRecipient) UnmarshalJSON(data []byte) error {
+	type plain TransferRecipient
+	var decoded struct {
+		plain
+		CamelCreatedAt string `json:"createdAt"`
+		CamelUpdatedAt string `json:"updatedAt"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = TransferRecipient(decoded.plain)
+	if r.CreatedAt == "" {
+		r.CreatedAt = decoded.CamelCreatedAt
+	}
+	if r.UpdatedAt == "" {
+		r.UpdatedAt = decoded.CamelUpdatedAt
+	}
+	return nil
+}
+
+// NairaRecipient converts a nuban recipient into interfacesx.NairaRecipient.
+// ID is left for the caller to assign.
+func (r *TransferRecipient) NairaRecipient() interfacesx.NairaRecipient {
+	accountName := r.Name
+	if r.Details.AccountName != nil && *r.Details.AccountName != "" {
+		accountName = *r.Details.AccountName
+	}
+	return interfacesx.NairaRecipient{
+		RecipientCode: r.RecipientCode,
+		BankName:      r.Details.BankName,
+		AccountNumber: r.Details.AccountNumber,
+		AccountName:   accountName,
+	}
+}
+
+func (p *paystackClient) ListTransferRecipients(filter *ListTransferRecipientsRequest) (*ListTransferRecipientsResponse, error) {
+	return p.ListTransferRecipientsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListTransferRecipientsWithContext(ctx context.Context, filter *ListTransferRecipientsRequest) (*ListTransferRecipientsResponse, error) {
+	endpoint := "transferrecipient"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListTransferRecipientsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchTransferRecipient(idOrCode string) (*TransferRecipientResponse, error) {
+	return p.FetchTransferRecipientWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) FetchTransferRecipientWithContext(ctx context.Context, idOrCode string) (*TransferRecipientResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("recipient id or code is required")
+	}
+
+	var response TransferRecipientResponse
+	if err := p.doJSON(ctx, "GET", "transferrecipient/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) UpdateTransferRecipient(idOrCode string, data *UpdateTransferRecipientRequest) (*MessageResponse, error) {
+	return p.UpdateTransferRecipientWithContext(context.Background(), idOrCode, data)
+}
+
+func (p *paystackClient) UpdateTransferRecipientWithContext(ctx context.Context, idOrCode string, data *UpdateTransferRecipientRequest) (*MessageResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("recipient id or code is required")
+	}
+	if data.Name == "" {
+		return nil, fmt.Errorf("recipient name is required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "PUT", "transferrecipient/"+url.PathEscape(idOrCode), data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DeleteTransferRecipient deactivates a recipient. Paystack keeps the record
+// but it can no longer receive transfers.
+func (p *paystackClient) DeleteTransferRecipient(idOrCode string) (*MessageResponse, error) {
+	return p.DeleteTransferRecipientWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) DeleteTransferRecipientWithContext(ctx context.Context, idOrCode string) (*MessageResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("recipient id or code is required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "DELETE", "transferrecipient/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) CreateBulkTransferRecipients(data *BulkTransferRecipientRequest) (*BulkTransferRecipientResponse, error) {
+	return p.CreateBulkTransferRecipientsWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateBulkTransferRecipientsWithContext(ctx context.Context, data *BulkTransferRecipientRequest) (*BulkTransferRecipientResponse, error) {
+	if len(data.Batch) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	for i := range data.Batch {
+		if err := data.Batch[i].validate(); err != nil {
+			return nil, fmt.Errorf("recipient %d: %w", i, err)
+		}
+	}
+
+	var response BulkTransferRecipientResponse
+	if err := p.doJSON(ctx, "POST", "transferrecipient/bulk", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// CreateNairaRecipient resolves a Nigerian bank account, creates a nuban
+// recipient under the resolved name and returns it as an
+// interfacesx.NairaRecipient. ID is left for the caller to assign.
+func (p *paystackClient) CreateNairaRecipient(accountNumber, bankCode string) (*interfacesx.NairaRecipient, error) {
+	return p.CreateNairaRecipientWithContext(context.Background(), accountNumber, bankCode)
+}
+
+func (p *paystackClient) CreateNairaRecipientWithContext(ctx context.Context, accountNumber, bankCode string) (*interfacesx.NairaRecipient, error) {
+	if accountNumber == "" || bankCode == "" {
+		return nil, fmt.Errorf("account number and bank code are required")
+	}
+
+	account, err := p.ResolveAccountNumberWithContext(ctx, &interfacesx.ResolveBankAccountRequest{AccountNumber: accountNumber, BankCode: bankCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve account %s: %w", accountNumber, err)
+	}
+
+	recipient, err := p.CreateTransferRecipientWithContext(ctx, NewNubanRecipient(account.Data.AccountName, accountNumber, bankCode))
+	if err != nil {
+		return nil, err
+	}
+
+	naira := interfacesx.NairaRecipient{
+		RecipientCode: recipient.Data.RecipientCode,
+		BankName:      recipient.Data.Details.BankName,
+		AccountNumber: accountNumber,
+		AccountName:   account.Data.AccountName,
+	}
+	if naira.BankName == "" {
+		// Always the Nigerian list, whatever WithBankQuery chose.
+		if naira.BankName, err = bankNameByCode(ctx, p.bankDirectory(BankQuery{Country: CountryNigeria}), bankCode); err != nil {
+			return nil, err
+		}
+	}
+	return &naira, nil
+}
@@ -0,0 +1,153 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferRecipientRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /transferrecipient":
+			query := r.URL.Query()
+			require.Equal(t, "20", query.Get("perPage"))
+			require.Equal(t, "2024-01-01T00:00:00Z", query.Get("from"))
+			w.Write([]byte(`{"status":true,"message":"Recipients retrieved","data":[{"active":true,"currency":"NGN","id":28,"name":"Ada Obi","recipient_code":"RCP_2x5j67tnnw1t98k","type":"nuban","details":{"account_number":"0123456789","account_name":"ADA OBI","bank_code":"058","bank_name":"Guaranty Trust Bank"}}],"meta":{"total":1,"skipped":0,"perPage":20,"page":1,"pageCount":1}}`))
+		case "PUT /transferrecipient/RCP_2x5j67tnnw1t98k":
+			var body UpdateTransferRecipientRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, UpdateTransferRecipientRequest{Name: "Ada Savings"}, body)
+			w.Write([]byte(`{"status":true,"message":"Recipient updated"}`))
+		case "DELETE /transferrecipient/RCP_2x5j67tnnw1t98k":
+			w.Write([]byte(`{"status":true,"message":"Transfer recipient set as inactive"}`))
+		case "POST /transferrecipient/bulk":
+			var body BulkTransferRecipientRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Len(t, body.Batch, 2)
+			w.Write([]byte(`{"status":true,"message":"Recipients added successfully","data":{"success":[{"active":true,"currency":"NGN","id":81,"name":"Habenero Mundane","recipient_code":"RCP_h2idrcfsbvqf1pl","type":"nuban","details":{"account_number":"0123456789","account_name":null,"bank_code":"033","bank_name":"United Bank For Africa"}}],"errors":[{"error":"Account number is invalid","payload":{"type":"nuban","name":"Soft Merry","account_number":"98765432310","bank_code":"033","currency":"NGN"}}]}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	listed, err := client.ListTransferRecipients(&ListTransferRecipientsRequest{PerPage: 20, From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.NairaRecipient{RecipientCode: "RCP_2x5j67tnnw1t98k", BankName: "Guaranty Trust Bank", AccountNumber: "0123456789", AccountName: "ADA OBI"}, listed.Data[0].NairaRecipient())
+
+	_, err = client.UpdateTransferRecipient("RCP_2x5j67tnnw1t98k", &UpdateTransferRecipientRequest{Name: "Ada Savings"})
+	require.NoError(t, err)
+	_, err = client.DeleteTransferRecipient("RCP_2x5j67tnnw1t98k")
+	require.NoError(t, err)
+
+	bulk, err := client.CreateBulkTransferRecipients(&BulkTransferRecipientRequest{Batch: []PaystackCreateTransferRecipientRequest{
+		{Type: RecipientTypeNuban, Name: "Habenero Mundane", AccountNumber: "0123456789", BankCode: "033", Currency: "NGN"},
+		{Type: RecipientTypeNuban, Name: "Soft Merry", AccountNumber: "98765432310", BankCode: "033", Currency: "NGN"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "Habenero Mundane", bulk.Data.Success[0].NairaRecipient().AccountName)
+	require.Equal(t, "Account number is invalid", bulk.Data.Errors[0].Error)
+
+	_, err = client.FetchTransferRecipient("")
+	require.Error(t, err)
+	_, err = client.UpdateTransferRecipient("RCP_2x5j67tnnw1t98k", &UpdateTransferRecipientRequest{})
+	require.EqualError(t, err, "recipient name is required")
+	_, err = client.CreateBulkTransferRecipients(&BulkTransferRecipientRequest{})
+	require.EqualError(t, err, "at least one recipient is required")
+	_, err = client.CreateBulkTransferRecipients(&BulkTransferRecipientRequest{Batch: []PaystackCreateTransferRecipientRequest{
+		{Type: RecipientTypeNuban, Name: "Habenero Mundane", AccountNumber: "0123456789", BankCode: "033", Currency: "NGN"},
+		{Type: RecipientTypeMobileMoney, Name: "Kofi", AccountNumber: "0551234987", BankCode: "MPESA", Currency: "GHS"},
+	}})
+	require.EqualError(t, err, "recipient 1: MPESA wallets hold KES, not GHS")
+	_, err = client.CreateNairaRecipient("", "058")
+	require.EqualError(t, err, "account number and bank code are required")
+}
+
+func TestRecipientBuilders(t *testing.T) {
+	require.Equal(t, &PaystackCreateTransferRecipientRequest{Type: "ghipss", Name: "Kofi Mensah", AccountNumber: "1234567890", BankCode: "040100", Currency: "GHS"}, NewGhipssRecipient("Kofi Mensah", "1234567890", "040100"))
+	require.Equal(t, "ZAR", NewBasaRecipient("Thandi", "62812345678", "250655").Currency)
+	require.Equal(t, "NGN", NewNubanRecipient("Ada", "0123456789", "058").Currency)
+
+	ghana := NewMobileMoneyRecipient("Kofi Mensah", "+233 55 123 4987", "mtn")
+	require.Equal(t, &PaystackCreateTransferRecipientRequest{Type: "mobile_money", Name: "Kofi Mensah", AccountNumber: "0551234987", BankCode: "MTN", Currency: "GHS"}, ghana)
+	require.NoError(t, ghana.validate())
+	kenya := NewMobileMoneyRecipient("Wanjiru", "254712345678", "MPESA")
+	require.Equal(t, "0712345678", kenya.AccountNumber)
+	require.Equal(t, "KES", kenya.Currency)
+	require.NoError(t, kenya.validate())
+
+	cases := []struct {
+		name    string
+		request *PaystackCreateTransferRecipientRequest
+		err     string
+	}{
+		{"nuban left to paystack", &PaystackCreateTransferRecipientRequest{Type: "nuban", AccountNumber: "0123456789", BankCode: "058", Currency: "USD"}, ""},
+		{"authorization", &PaystackCreateTransferRecipientRequest{Type: "authorization", Name: "Ada", Email: "ada@example.com", AuthorizationCode: "AUTH_ncx8hews93"}, ""},
+		{"other type", &PaystackCreateTransferRecipientRequest{Type: "swift", Currency: "USD"}, ""},
+		{"missing account", &PaystackCreateTransferRecipientRequest{Type: "basa", BankCode: "250655", Currency: "ZAR"}, "account number and bank code are required"},
+		{"missing currency", &PaystackCreateTransferRecipientRequest{Type: "ghipss", AccountNumber: "1234567890", BankCode: "040100"}, "currency is required for ghipss recipients"},
+		{"wrong currency", &PaystackCreateTransferRecipientRequest{Type: "basa", AccountNumber: "62812345678", BankCode: "250655", Currency: "NGN"}, "basa recipients cannot be paid in NGN"},
+		{"uganda", &PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "0772123456", BankCode: "MTN", Currency: "UGX"}, "mobile_money recipients cannot be paid in UGX"},
+		{"other provider", &PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "0201234567", BankCode: "AIRTELTIGO", Currency: "GHS"}, ""},
+		{"longer number", &PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "07123456789", BankCode: "MPESA", Currency: "KES"}, ""},
+		{"provider currency", &PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "0551234987", BankCode: "MPESA", Currency: "GHS"}, "MPESA wallets hold KES, not GHS"},
+		{"bad number", &PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "055-123-49x", BankCode: "VOD", Currency: "GHS"}, "invalid mobile money number 055-123-49x"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.request.validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestRecipientTypeFor(t *testing.T) {
+	for _, tc := range []struct{ currency, bankCode, recipientType string }{
+		{"NGN", "058", RecipientTypeNuban},
+		{"ghs", "040100", RecipientTypeGhipss},
+		{"GHS", "ATL", RecipientTypeMobileMoney},
+		{"KES", "mpesa", RecipientTypeMobileMoney},
+		{"ZAR", "250655", RecipientTypeBasa},
+	} {
+		recipientType, err := RecipientTypeFor(tc.currency, tc.bankCode)
+		require.NoError(t, err)
+		require.Equal(t, tc.recipientType, recipientType)
+	}
+
+	_, err := RecipientTypeFor("KES", "01")
+	require.EqualError(t, err, "no Paystack bank recipient type for KES")
+	_, err = RecipientTypeFor("UGX", "MTN")
+	require.EqualError(t, err, "MTN wallets hold GHS, not UGX")
+	require.True(t, IsMobileMoneyProvider("vod"))
+	require.False(t, IsMobileMoneyProvider("058"))
+}
+
+func TestResolveMobileMoneyNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/bank/resolve", r.URL.Path)
+		require.Equal(t, "0551234987", r.URL.Query().Get("account_number"))
+		require.Equal(t, "MTN", r.URL.Query().Get("bank_code"))
+		w.Write([]byte(`{"status":true,"message":"Account number resolved","data":{"account_number":"0551234987","account_name":"KOFI MENSAH","bank_id":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+	resolved, err := client.ResolveAccountNumber(&interfacesx.ResolveBankAccountRequest{AccountNumber: "+233551234987", BankCode: "MTN"})
+	require.NoError(t, err)
+	require.Equal(t, "KOFI MENSAH", resolved.Data.AccountName)
+
+	_, err = client.CreateTransferRecipient(&PaystackCreateTransferRecipientRequest{Type: "mobile_money", AccountNumber: "0551234987", BankCode: "MTN", Currency: "KES"})
+	require.EqualError(t, err, "MTN wallets hold GHS, not KES")
+}
@@ -0,0 +1,229 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// Paystack refund statuses.
+const (
+	RefundStatusPending        = "pending"
+	RefundStatusProcessing     = "processing"
+	RefundStatusProcessed      = "processed"
+	RefundStatusFailed         = "failed"
+	RefundStatusNeedsAttention = "needs-attention"
+)
+
+var refundStatuses = map[string]interfacesx.TransactionStatus{
+	RefundStatusPending:        interfacesx.Pending,
+	RefundStatusProcessing:     interfacesx.Processing,
+	RefundStatusProcessed:      interfacesx.Refunded,
+	RefundStatusFailed:         interfacesx.Failed,
+	RefundStatusNeedsAttention: interfacesx.Hold,
+}
+
+// MapRefundStatus converts a Paystack refund status to a TransactionStatus.
+// Unknown statuses map to Processing so they are never treated as final.
+func MapRefundStatus(status string) interfacesx.TransactionStatus {
+	if mapped, ok := refundStatuses[strings.ToLower(status)]; ok {
+		return mapped
+	}
+	return interfacesx.Processing
+}
+
+// CreateRefundRequest refunds a transaction by ID or reference. Amount is in
+// the minor unit of the currency; zero refunds the full amount.
+type CreateRefundRequest struct {
+	Transaction  string `json:"transaction"`
+	Amount       int64  `json:"amount,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	CustomerNote string `json:"customer_note,omitempty"`
+	MerchantNote string `json:"merchant_note,omitempty"`
+}
+
+// RefundTransaction is the refunded transaction. List responses only carry its ID.
+type RefundTransaction struct {
+	ID        int    `json:"id"`
+	Domain    string `json:"domain"`
+	Reference string `json:"reference"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Channel   string `json:"channel"`
+	PaidAt    string `json:"paid_at"`
+}
+
+func (t *RefundTransaction) UnmarshalJSON(data []byte) error {
+	if id, err := strconv.Atoi(string(data)); err == nil {
+		*t = RefundTransaction{ID: id}
+		return nil
+	}
+	type plain RefundTransaction
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+type Refund struct {
+	ID             int               `json:"id"`
+	Integration    int               `json:"integration"`
+	Domain         string            `json:"domain"`
+	Transaction    RefundTransaction `json:"transaction"`
+	Dispute        *int              `json:"dispute"`
+	Amount         int64             `json:"amount"`
+	DeductedAmount int64             `json:"deducted_amount"`
+	Currency       string            `json:"currency"`
+	Channel        string            `json:"channel"`
+	Status         string            `json:"status"`
+	RefundedBy     string            `json:"refunded_by"`
+	RefundedAt     *string           `json:"refunded_at"`
+	ExpectedAt     string            `json:"expected_at"`
+	CustomerNote   string            `json:"customer_note"`
+	MerchantNote   string            `json:"merchant_note"`
+	CreatedAt      string            `json:"createdAt"`
+	UpdatedAt      string            `json:"updatedAt"`
+}
+
+// Money returns the refunded amount.
+func (r *Refund) Money() moneyx.Money {
+	return moneyx.New(r.Amount, r.Currency)
+}
+
+// TransactionStatus maps the refund status onto interfacesx.TransactionStatus.
+func (r *Refund) TransactionStatus() interfacesx.TransactionStatus {
+	return MapRefundStatus(r.Status)
+}
+
+type RefundResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    Refund `json:"data"`
+}
+
+// ListRefundsRequest filters ListRefunds. Zero values are omitted.
+type ListRefundsRequest struct {
+	PerPage     int
+	Page        int
+	Transaction string
+	Currency    string
+	From        time.Time
+	To          time.Time
+}
+
+func (r *ListRefundsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Transaction != "" {
+		query.Set("transaction", r.Transaction)
+	}
+	if r.Currency != "" {
+		query.Set("currency", r.Currency)
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListRefundsResponse struct {
+	Status  bool     `json:"status"`
+	Message string   `json:"message"`
+	Data    []Refund `json:"data"`
+	Meta    Meta     `json:"meta"`
+}
+
+// RefundEventData is the data of the refund.* webhooks.
+type RefundEventData struct {
+	Status               string                `json:"status"`
+	TransactionReference string                `json:"transaction_reference"`
+	RefundReference      *string               `json:"refund_reference"`
+	Amount               int64                 `json:"amount"`
+	Currency             string                `json:"currency"`
+	Processor            string                `json:"processor"`
+	Customer             PaystackEventCustomer `json:"customer"`
+	Integration          int                   `json:"integration"`
+	Domain               string                `json:"domain"`
+}
+
+// Money returns the refunded amount.
+func (d *RefundEventData) Money() moneyx.Money {
+	return moneyx.New(d.Amount, d.Currency)
+}
+
+// TransactionStatus maps the refund status onto interfacesx.TransactionStatus.
+func (d *RefundEventData) TransactionStatus() interfacesx.TransactionStatus {
+	return MapRefundStatus(d.Status)
+}
+
+// CreateRefund refunds all or part of a transaction. The refund is processed
+// asynchronously; follow it with the refund.* webhooks or FetchRefund.
+func (p *paystackClient) CreateRefund(data *CreateRefundRequest) (*RefundResponse, error) {
+	return p.CreateRefundWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateRefundWithContext(ctx context.Context, data *CreateRefundRequest) (*RefundResponse, error) {
+	if data.Transaction == "" {
+		return nil, fmt.Errorf("transaction id or reference is required")
+	}
+	if data.Amount < 0 {
+		return nil, fmt.Errorf("amount cannot be negative")
+	}
+
+	var response RefundResponse
+	if err := p.doJSON(ctx, "POST", "refund", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListRefunds(filter *ListRefundsRequest) (*ListRefundsResponse, error) {
+	return p.ListRefundsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListRefundsWithContext(ctx context.Context, filter *ListRefundsRequest) (*ListRefundsResponse, error) {
+	endpoint := "refund"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListRefundsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchRefund(id int) (*RefundResponse, error) {
+	return p.FetchRefundWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) FetchRefundWithContext(ctx context.Context, id int) (*RefundResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("refund id is required")
+	}
+
+	var response RefundResponse
+	if err := p.doJSON(ctx, "GET", "refund/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,94 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /refund":
+			var body CreateRefundRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, CreateRefundRequest{Transaction: "dep-1", Amount: 10000, MerchantNote: "Duplicate charge"}, body)
+			w.Write([]byte(`{"status":true,"message":"Refund has been queued for processing","data":{"transaction":{"id":1004723697,"domain":"live","reference":"dep-1","amount":250000,"paid_at":"2021-08-20T18:34:11.000Z","channel":"card","currency":"NGN"},"integration":463433,"deducted_amount":0,"channel":null,"merchant_note":"Duplicate charge","customer_note":"Refund for transaction dep-1","status":"pending","refunded_by":"ops@example.com","expected_at":"2021-12-16T09:21:17.016Z","currency":"NGN","domain":"live","amount":10000,"id":3018284,"createdAt":"2021-12-07T09:21:17.122Z","updatedAt":"2021-12-07T09:21:17.122Z"}}`))
+		case "GET /refund":
+			require.Equal(t, "dep-1", r.URL.Query().Get("transaction"))
+			w.Write([]byte(`{"status":true,"message":"Refunds retrieved","data":[{"integration":428626,"transaction":1004723697,"dispute":null,"amount":10000,"deducted_amount":10000,"currency":"NGN","channel":"card","status":"processed","id":3018284}],"meta":{"total":1,"skipped":0,"perPage":50,"page":1,"pageCount":1}}`))
+		case "GET /refund/3018284":
+			w.Write([]byte(`{"status":true,"message":"Refund retrieved","data":{"integration":428626,"transaction":1004723697,"amount":10000,"currency":"NGN","status":"processed","refunded_at":"2021-12-08T10:00:00.000Z","id":3018284}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	created, err := client.CreateRefund(&CreateRefundRequest{Transaction: "dep-1", Amount: 10000, MerchantNote: "Duplicate charge"})
+	require.NoError(t, err)
+	require.Equal(t, "dep-1", created.Data.Transaction.Reference)
+	require.Equal(t, interfacesx.Pending, created.Data.TransactionStatus())
+
+	listed, err := client.ListRefunds(&ListRefundsRequest{Transaction: "dep-1"})
+	require.NoError(t, err)
+	require.Len(t, listed.Data, 1)
+	require.Equal(t, 1004723697, listed.Data[0].Transaction.ID)
+
+	fetched, err := client.FetchRefund(3018284)
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Refunded, fetched.Data.TransactionStatus())
+	require.Equal(t, "100.00 NGN", fetched.Data.Money().String())
+
+	_, err = client.CreateRefund(&CreateRefundRequest{})
+	require.Error(t, err)
+	_, err = client.FetchRefund(0)
+	require.Error(t, err)
+}
+
+func TestMapRefundStatus(t *testing.T) {
+	require.Equal(t, interfacesx.Pending, MapRefundStatus("pending"))
+	require.Equal(t, interfacesx.Processing, MapRefundStatus("processing"))
+	require.Equal(t, interfacesx.Refunded, MapRefundStatus("Processed"))
+	require.Equal(t, interfacesx.Failed, MapRefundStatus("failed"))
+	require.Equal(t, interfacesx.Hold, MapRefundStatus("needs-attention"))
+	require.Equal(t, interfacesx.Processing, MapRefundStatus("something-new"))
+}
+
+func TestWebhookDispatchesRefundAndDispute(t *testing.T) {
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+
+	var statuses []interfacesx.TransactionStatus
+	handler.OnRefund(func(c *gin.Context, event string, data *RefundEventData) error {
+		require.Equal(t, EventRefundProcessed, event)
+		require.Equal(t, "tvunjbbd_412_14", data.TransactionReference)
+		statuses = append(statuses, data.TransactionStatus())
+		return nil
+	})
+	handler.OnDispute(func(c *gin.Context, event string, data *Dispute) error {
+		require.Equal(t, EventChargeDisputeCreate, event)
+		require.Equal(t, "v3pgxcd3ieqt0uy", data.Transaction.Reference)
+		statuses = append(statuses, data.TransactionStatus())
+		return nil
+	})
+	router := newWebhookRouter(handler)
+
+	for _, name := range []string{"refund_processed.json", "charge_dispute_create.json"} {
+		body, err := os.ReadFile(filepath.Join("testdata", "events", name))
+		require.NoError(t, err)
+		recorder := deliverWebhook(router, string(body), SignPayload(testSecretKey, body), "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	require.Equal(t, []interfacesx.TransactionStatus{interfacesx.Refunded, interfacesx.Hold}, statuses)
+}
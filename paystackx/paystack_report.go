@@ -0,0 +1,226 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// ReportFormat selects how ReportExporter writes a report.
+type ReportFormat string
+
+const (
+	ReportFormatCSV  ReportFormat = "csv"
+	ReportFormatJSON ReportFormat = "json"
+)
+
+// DefaultReportPageSize is the page size ReportExporter requests when none is
+// given.
+const DefaultReportPageSize = 100
+
+// SettlementReport is a settlement with the transactions it paid out.
+type SettlementReport struct {
+	Settlement
+	Transactions []PaystackEventData `json:"transactions"`
+}
+
+// ReportExporter fetches the balance ledger and settlements for a date range,
+// following Meta pagination until the last page, and writes them as CSV or
+// JSON reports. CSV amounts are in major units; JSON keeps Paystack's minor
+// units.
+type ReportExporter struct {
+	client  PaystackService
+	perPage int
+}
+
+func NewReportExporter(client PaystackService, perPage int) *ReportExporter {
+	if perPage <= 0 {
+		perPage = DefaultReportPageSize
+	}
+	return &ReportExporter{client: client, perPage: perPage}
+}
+
+// BalanceLedger returns every ledger entry between from and to. A zero from or
+// to leaves that end of the range open.
+func (e *ReportExporter) BalanceLedger(ctx context.Context, from, to time.Time) ([]BalanceLedgerEntry, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	entries := []BalanceLedgerEntry{}
+	for page := 1; ; page++ {
+		response, err := e.client.ListBalanceLedgerWithContext(ctx, &ListBalanceLedgerRequest{PerPage: e.perPage, Page: page, From: from, To: to})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list balance ledger page %d: %w", page, err)
+		}
+		entries = append(entries, response.Data...)
+		if !response.Meta.HasNextPage() || len(response.Data) == 0 {
+			return entries, nil
+		}
+	}
+}
+
+// Settlements returns every settlement between from and to.
+func (e *ReportExporter) Settlements(ctx context.Context, from, to time.Time) ([]Settlement, error) {
+	if err := validateRange(from, to); err != nil {
+		return nil, err
+	}
+
+	settlements := []Settlement{}
+	for page := 1; ; page++ {
+		response, err := e.client.ListSettlementsWithContext(ctx, &ListSettlementsRequest{PerPage: e.perPage, Page: page, From: from, To: to})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list settlements page %d: %w", page, err)
+		}
+		settlements = append(settlements, response.Data...)
+		if !response.Meta.HasNextPage() || len(response.Data) == 0 {
+			return settlements, nil
+		}
+	}
+}
+
+// SettlementTransactions returns every transaction paid out in a settlement.
+func (e *ReportExporter) SettlementTransactions(ctx context.Context, settlementID int) ([]PaystackEventData, error) {
+	transactions := []PaystackEventData{}
+	for page := 1; ; page++ {
+		response, err := e.client.ListSettlementTransactionsWithContext(ctx, settlementID, &ListSettlementTransactionsRequest{PerPage: e.perPage, Page: page})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list transactions of settlement %d page %d: %w", settlementID, page, err)
+		}
+		transactions = append(transactions, response.Data...)
+		if !response.Meta.HasNextPage() || len(response.Data) == 0 {
+			return transactions, nil
+		}
+	}
+}
+
+// SettlementReports returns every settlement between from and to with its
+// transactions.
+func (e *ReportExporter) SettlementReports(ctx context.Context, from, to time.Time) ([]SettlementReport, error) {
+	settlements, err := e.Settlements(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]SettlementReport, 0, len(settlements))
+	for _, settlement := range settlements {
+		transactions, err := e.SettlementTransactions(ctx, settlement.ID)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, SettlementReport{Settlement: settlement, Transactions: transactions})
+	}
+	return reports, nil
+}
+
+// ExportBalanceLedger writes the ledger between from and to to w.
+func (e *ReportExporter) ExportBalanceLedger(ctx context.Context, w io.Writer, format ReportFormat, from, to time.Time) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+	entries, err := e.BalanceLedger(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	if format == ReportFormatJSON {
+		return writeReportJSON(w, entries)
+	}
+	rows := [][]string{{"id", "created_at", "currency", "difference", "balance", "reason", "model_responsible"}}
+	for i := range entries {
+		entry := &entries[i]
+		rows = append(rows, []string{
+			strconv.Itoa(entry.ID),
+			entry.CreatedAt,
+			entry.Currency,
+			entry.DifferenceMoney().Decimal(),
+			entry.Money().Decimal(),
+			entry.Reason,
+			entry.ModelResponsible,
+		})
+	}
+	return writeReportCSV(w, rows)
+}
+
+// ExportSettlements writes the settlements between from and to, with their
+// transactions, to w. The CSV has one row per transaction, repeating the
+// settlement columns; a settlement without transactions gets a single row.
+func (e *ReportExporter) ExportSettlements(ctx context.Context, w io.Writer, format ReportFormat, from, to time.Time) error {
+	if err := format.validate(); err != nil {
+		return err
+	}
+	reports, err := e.SettlementReports(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	if format == ReportFormatJSON {
+		return writeReportJSON(w, reports)
+	}
+	rows := [][]string{{
+		"settlement_id", "settlement_date", "settlement_status", "currency", "total_amount", "total_fees", "effective_amount",
+		"transaction_id", "reference", "channel", "amount", "fees", "paid_at",
+	}}
+	for i := range reports {
+		report := &reports[i]
+		settlement := []string{
+			strconv.Itoa(report.ID),
+			report.SettlementDate,
+			report.Status,
+			report.Currency,
+			moneyx.New(report.TotalAmount, report.Currency).Decimal(),
+			report.FeesMoney().Decimal(),
+			report.Money().Decimal(),
+		}
+		if len(report.Transactions) == 0 {
+			rows = append(rows, append(settlement, "", "", "", "", "", ""))
+			continue
+		}
+		for j := range report.Transactions {
+			transaction := &report.Transactions[j]
+			rows = append(rows, append(append([]string(nil), settlement...),
+				strconv.Itoa(transaction.ID),
+				transaction.Reference,
+				transaction.Channel,
+				transaction.Money().Decimal(),
+				transaction.FeesMoney().Decimal(),
+				transaction.PaidAt,
+			))
+		}
+	}
+	return writeReportCSV(w, rows)
+}
+
+func (f ReportFormat) validate() error {
+	if f != ReportFormatCSV && f != ReportFormatJSON {
+		return fmt.Errorf("unsupported report format %q", f)
+	}
+	return nil
+}
+
+func validateRange(from, to time.Time) error {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return fmt.Errorf("report range ends before it starts")
+	}
+	return nil
+}
+
+func writeReportJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeReportCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
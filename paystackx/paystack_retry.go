@@ -0,0 +1,171 @@
+package paystackx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrTransferOutcomeUnknown is returned when a money-movement call failed in
+// a way that may have reached Paystack and the verification meant to settle it
+// failed too. The transfer or charge may have gone out, so it must not be sent
+// again, here or to another provider, until it has been looked up. The error
+// wraps the failure of the call itself, not that of the verification.
+var ErrTransferOutcomeUnknown = errors.New("transfer outcome unknown")
+
+// RetryPolicy controls how failed requests are retried. Only safe operations
+// are retried automatically: GET requests, and money-movement calls that carry
+// a reference and can be verified before they are sent again.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values
+	// below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each attempt.
+	Multiplier float64
+	// Jitter randomises each wait by up to this fraction in either direction.
+	Jitter float64
+}
+
+// DefaultRetryPolicy makes three attempts, backing off from 200ms to at most 2s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// NoRetry disables retries.
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(p *paystackClient) {
+		p.retryPolicy = policy
+	}
+}
+
+// Backoff returns the wait before retry number attempt, starting at 1.
+func (r RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(r.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if r.MaxBackoff > 0 && backoff > float64(r.MaxBackoff) {
+		backoff = float64(r.MaxBackoff)
+	}
+	if r.Jitter > 0 {
+		backoff += backoff * r.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// wait sleeps before retry number attempt. A Retry-After sent with err is
+// honoured when it is longer than the backoff.
+func (r RetryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	backoff := r.Backoff(attempt)
+	if paystackErr, ok := AsPaystackError(err); ok && paystackErr.RetryAfter > backoff {
+		backoff = paystackErr.RetryAfter
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryable reports whether err is a temporary Paystack error or a
+// transport failure that left the request unanswered: a timeout, a refused or
+// reset connection, or an error that says it is temporary. Other transport
+// errors, such as a bad certificate or an unknown host, fail the same way on
+// every attempt.
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if paystackErr, ok := AsPaystackError(err); ok {
+		return paystackErr.Temporary()
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	if urlErr.Timeout() {
+		return true
+	}
+	// A server that closes a kept-alive connection without answering shows up
+	// as EOF rather than ECONNRESET.
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	return errors.As(urlErr.Err, &temporary) && temporary.Temporary()
+}
+
+// isNotFound reports whether Paystack has no record of the requested resource.
+func isNotFound(err error) bool {
+	paystackErr, ok := AsPaystackError(err)
+	if !ok {
+		return false
+	}
+	return paystackErr.StatusCode == http.StatusNotFound ||
+		paystackErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(paystackErr.Message), "not found")
+}
+
+// withRetry calls fn until it succeeds, fails with a non-retryable error or
+// the policy runs out of attempts.
+func (p *paystackClient) withRetry(ctx context.Context, fn func() error) error {
+	err := fn()
+	for attempt := 1; attempt < p.retryPolicy.MaxAttempts && isRetryable(ctx, err); attempt++ {
+		if waitErr := p.retryPolicy.wait(ctx, attempt, err); waitErr != nil {
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
+
+// withVerifiedRetry retries a money-movement call. Before each retry it calls
+// verify to find out whether the failed attempt actually reached Paystack;
+// the call is only sent again when verify reports that nothing was recorded.
+func (p *paystackClient) withVerifiedRetry(ctx context.Context, call func() error, verify func() (bool, error)) error {
+	err := call()
+	for attempt := 1; attempt < p.retryPolicy.MaxAttempts && isRetryable(ctx, err); attempt++ {
+		if waitErr := p.retryPolicy.wait(ctx, attempt, err); waitErr != nil {
+			return err
+		}
+
+		found, verifyErr := verify()
+		if verifyErr != nil {
+			return fmt.Errorf("%w (verification failed: %v): %w", ErrTransferOutcomeUnknown, verifyErr, err)
+		}
+		if found {
+			return nil
+		}
+		err = call()
+	}
+	return err
+}
@@ -0,0 +1,203 @@
+package paystackx
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+
+const verifiedTransferBody = `{"status":true,"message":"Transfer retrieved","data":{"id":476948,"amount":1000000,"currency":"NGN","reference":"wd-9","status":"pending","transfer_code":"TRF_v5hy3mwd1b8ahvq","integration":463433,"createdAt":"2024-10-29T13:38:20.000Z","recipient":{"id":9913,"recipient_code":"RCP_gx2wn530m0i3w3m"}}}`
+
+func TestRetryGetOnTemporaryError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":true,"message":"Balances retrieved","data":[{"currency":"NGN","balance":1500000}]}`))
+	}))
+	defer server.Close()
+
+	balance, err := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry)).FetchBalance()
+	require.NoError(t, err)
+	require.Equal(t, int64(1500000), balance.Data[0].Balance)
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetrySkipsClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":false,"message":"Invalid key"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry)).FetchBalance()
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIsRetryable(t *testing.T) {
+	transport := func(err error) error {
+		return &url.Error{Op: "Post", URL: "https://api.paystack.co/transfer", Err: err}
+	}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", transport(os.ErrDeadlineExceeded), true},
+		{"connection refused", transport(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), true},
+		{"connection reset", transport(&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"connection closed", transport(io.EOF), true},
+		{"temporary dns failure", transport(&net.DNSError{Err: "server misbehaving", Name: "api.paystack.co", IsTemporary: true}), true},
+		{"unknown host", transport(&net.DNSError{Err: "no such host", Name: "api.paystack.co", IsNotFound: true}), false},
+		{"bad certificate", transport(x509.UnknownAuthorityError{}), false},
+		{"service unavailable", &PaystackError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"bad request", &PaystackError{StatusCode: http.StatusBadRequest}, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, isRetryable(context.Background(), c.err))
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, isRetryable(ctx, transport(io.EOF)))
+}
+
+func TestTransferRetriedWhenVerifyFindsNothing(t *testing.T) {
+	var initiates, verifies int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transfer":
+			if atomic.AddInt32(&initiates, 1) == 1 {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			w.Write([]byte(`{"status":true,"message":"Transfer has been queued","data":{"reference":"wd-9","status":"pending","transfer_code":"TRF_v5hy3mwd1b8ahvq"}}`))
+		case "GET /transfer/verify/wd-9":
+			atomic.AddInt32(&verifies, 1)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":false,"message":"Transfer not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry))
+	response, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: 1000000, Recipient: "RCP_gx2wn530m0i3w3m", Reference: "wd-9"})
+	require.NoError(t, err)
+	require.Equal(t, "TRF_v5hy3mwd1b8ahvq", response.Data.TransferCode)
+	require.Equal(t, int32(2), atomic.LoadInt32(&initiates))
+	require.Equal(t, int32(1), atomic.LoadInt32(&verifies))
+}
+
+func TestTransferNotRetriedWhenVerifyFindsIt(t *testing.T) {
+	var initiates int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transfer":
+			atomic.AddInt32(&initiates, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		case "GET /transfer/verify/wd-9":
+			w.Write([]byte(verifiedTransferBody))
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry))
+	response, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: 1000000, Recipient: "RCP_gx2wn530m0i3w3m", Reference: "wd-9"})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&initiates))
+	require.Equal(t, "TRF_v5hy3mwd1b8ahvq", response.Data.TransferCode)
+	require.Equal(t, 9913, response.Data.Recipient)
+	require.Equal(t, 463433, response.Data.Integration)
+}
+
+func TestTransferVerifyFailureLeavesOutcomeUnknown(t *testing.T) {
+	var initiates int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			atomic.AddInt32(&initiates, 1)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry))
+	_, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: 1000000, Recipient: "RCP_gx2wn530m0i3w3m", Reference: "wd-9"})
+	require.ErrorIs(t, err, ErrTransferOutcomeUnknown)
+	require.Equal(t, int32(1), atomic.LoadInt32(&initiates))
+}
+
+func TestTransferOutcomeUnknownWrapsCallError(t *testing.T) {
+	var initiates int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transfer":
+			atomic.AddInt32(&initiates, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "GET /transfer/verify/wd-9":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":false,"message":"Too many requests"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(fastRetry))
+	_, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: 1000000, Recipient: "RCP_gx2wn530m0i3w3m", Reference: "wd-9"})
+	require.ErrorIs(t, err, ErrTransferOutcomeUnknown)
+	require.ErrorContains(t, err, "Too many requests")
+	require.Equal(t, int32(1), atomic.LoadInt32(&initiates))
+
+	// The chain carries the 503 of the transfer, not the 429 of the lookup.
+	paystackErr, ok := AsPaystackError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusServiceUnavailable, paystackErr.StatusCode)
+}
+
+func TestTransferRequiresReference(t *testing.T) {
+	client := NewPaystackClient("http://127.0.0.1:0", testSecretKey)
+	_, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: 1000000, Recipient: "RCP_gx2wn530m0i3w3m"})
+	require.Error(t, err)
+}
+
+func TestTransferRequiresPositiveAmount(t *testing.T) {
+	client := NewPaystackClient("http://127.0.0.1:0", testSecretKey)
+	for _, amount := range []int64{0, -500} {
+		_, err := client.InitiateTransfer(&TransferFundsRequest{Source: "balance", AmountMinor: amount, Recipient: "RCP_gx2wn530m0i3w3m", Reference: "wd-9"})
+		require.EqualError(t, err, "amount must be positive")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	require.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	require.Equal(t, 400*time.Millisecond, policy.Backoff(3))
+	require.Equal(t, time.Second, policy.Backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		require.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		require.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
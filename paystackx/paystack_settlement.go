@@ -0,0 +1,246 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// Settlement statuses.
+const (
+	SettlementStatusPending    = "pending"
+	SettlementStatusProcessing = "processing"
+	SettlementStatusSuccess    = "success"
+	SettlementStatusFailed     = "failed"
+)
+
+// BalanceLedgerEntry is one movement of the balance. Difference is negative
+// for debits and Balance is the balance after the movement, both in minor
+// units. ModelRow is the transfer, charge or refund that caused it.
+type BalanceLedgerEntry struct {
+	ID               int             `json:"id"`
+	Integration      int             `json:"integration"`
+	Domain           string          `json:"domain"`
+	Balance          int64           `json:"balance"`
+	Currency         string          `json:"currency"`
+	Difference       int64           `json:"difference"`
+	Reason           string          `json:"reason"`
+	ModelResponsible string          `json:"model_responsible"`
+	ModelRow         json.RawMessage `json:"model_row,omitempty"`
+	CreatedAt        string          `json:"createdAt"`
+	UpdatedAt        string          `json:"updatedAt"`
+}
+
+// Settlement is a payout of collected funds to the settlement bank account.
+// Amounts are in minor units; EffectiveAmount is what reached the bank after
+// fees and deductions.
+type Settlement struct {
+	ID              int     `json:"id"`
+	Integration     int     `json:"integration"`
+	Domain          string  `json:"domain"`
+	Status          string  `json:"status"`
+	Currency        string  `json:"currency"`
+	TotalAmount     int64   `json:"total_amount"`
+	EffectiveAmount int64   `json:"effective_amount"`
+	TotalFees       int64   `json:"total_fees"`
+	TotalProcessed  int64   `json:"total_processed"`
+	Deductions      *int64  `json:"deductions"`
+	SettlementDate  string  `json:"settlement_date"`
+	SettledBy       *string `json:"settled_by"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+// ListBalanceLedgerRequest filters ListBalanceLedger. Zero values are omitted.
+type ListBalanceLedgerRequest struct {
+	PerPage int
+	Page    int
+	From    time.Time
+	To      time.Time
+}
+
+func (r *ListBalanceLedgerRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListBalanceLedgerResponse struct {
+	Status  bool                 `json:"status"`
+	Message string               `json:"message"`
+	Data    []BalanceLedgerEntry `json:"data"`
+	Meta    Meta                 `json:"meta"`
+}
+
+// ListSettlementsRequest filters ListSettlements. Subaccount "none" lists
+// only settlements of the main account. Zero values are omitted.
+type ListSettlementsRequest struct {
+	PerPage    int
+	Page       int
+	Status     string
+	Subaccount string
+	From       time.Time
+	To         time.Time
+}
+
+func (r *ListSettlementsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Status != "" {
+		query.Set("status", r.Status)
+	}
+	if r.Subaccount != "" {
+		query.Set("subaccount", r.Subaccount)
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListSettlementsResponse struct {
+	Status  bool         `json:"status"`
+	Message string       `json:"message"`
+	Data    []Settlement `json:"data"`
+	Meta    Meta         `json:"meta"`
+}
+
+// ListSettlementTransactionsRequest filters ListSettlementTransactions. Zero
+// values are omitted.
+type ListSettlementTransactionsRequest struct {
+	PerPage int
+	Page    int
+	From    time.Time
+	To      time.Time
+}
+
+func (r *ListSettlementTransactionsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+// Money returns the balance after the entry.
+func (e *BalanceLedgerEntry) Money() moneyx.Money {
+	return moneyx.New(e.Balance, e.Currency)
+}
+
+// DifferenceMoney returns the signed amount the entry moved.
+func (e *BalanceLedgerEntry) DifferenceMoney() moneyx.Money {
+	return moneyx.New(e.Difference, e.Currency)
+}
+
+// Money returns the amount paid out to the bank.
+func (s *Settlement) Money() moneyx.Money {
+	return moneyx.New(s.EffectiveAmount, s.Currency)
+}
+
+// FeesMoney returns the fees charged on the settled transactions.
+func (s *Settlement) FeesMoney() moneyx.Money {
+	return moneyx.New(s.TotalFees, s.Currency)
+}
+
+// ListBalanceLedger returns one page of balance movements, newest first.
+func (p *paystackClient) ListBalanceLedger(filter *ListBalanceLedgerRequest) (*ListBalanceLedgerResponse, error) {
+	return p.ListBalanceLedgerWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListBalanceLedgerWithContext(ctx context.Context, filter *ListBalanceLedgerRequest) (*ListBalanceLedgerResponse, error) {
+	endpoint := "balance/ledger"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListBalanceLedgerResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListSettlements(filter *ListSettlementsRequest) (*ListSettlementsResponse, error) {
+	return p.ListSettlementsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListSettlementsWithContext(ctx context.Context, filter *ListSettlementsRequest) (*ListSettlementsResponse, error) {
+	endpoint := "settlement"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListSettlementsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListSettlementTransactions returns one page of the transactions paid out in
+// a settlement.
+func (p *paystackClient) ListSettlementTransactions(settlementID int, filter *ListSettlementTransactionsRequest) (*ListTransactionsResponse, error) {
+	return p.ListSettlementTransactionsWithContext(context.Background(), settlementID, filter)
+}
+
+func (p *paystackClient) ListSettlementTransactionsWithContext(ctx context.Context, settlementID int, filter *ListSettlementTransactionsRequest) (*ListTransactionsResponse, error) {
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("settlement id is required")
+	}
+
+	endpoint := fmt.Sprintf("settlement/%d/transactions", settlementID)
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListTransactionsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,172 @@
+package paystackx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettlementRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.Method + " " + r.URL.Path {
+		case "GET /balance/ledger":
+			require.Equal(t, "2024-01-01T00:00:00Z", query.Get("from"))
+			require.Equal(t, "2024-01-31T23:00:00Z", query.Get("to"))
+			w.Write([]byte(`{"status":true,"message":"Balance ledger retrieved","data":[{"integration":463433,"domain":"test","balance":2078224,"currency":"NGN","difference":-50000,"reason":"Salary","model_responsible":"Transfer","model_row":{"id":1},"id":12345,"createdAt":"2024-01-10T09:00:00.000Z"}],"meta":{"total":1,"perPage":50,"page":1,"pageCount":1}}`))
+		case "GET /settlement":
+			require.Equal(t, "success", query.Get("status"))
+			require.Equal(t, "none", query.Get("subaccount"))
+			w.Write([]byte(`{"status":true,"message":"Settlements retrieved","data":[{"integration":463433,"settled_by":null,"settlement_date":"2024-01-11T00:00:00.000Z","domain":"test","total_amount":500000,"effective_amount":492500,"total_fees":7500,"total_processed":500000,"deductions":null,"status":"success","id":3090024,"currency":"NGN"}],"meta":{"total":1,"perPage":50,"page":1,"pageCount":1}}`))
+		case "GET /settlement/3090024/transactions":
+			require.Equal(t, "2", query.Get("page"))
+			w.Write([]byte(`{"status":true,"message":"Transactions retrieved","data":[{"id":1504248187,"status":"success","reference":"T4xv5fkh4x","amount":500000,"fees":7500,"channel":"card","currency":"NGN","paid_at":"2024-01-10T08:00:00.000Z"}],"meta":{"total":1,"perPage":50,"page":2,"pageCount":2}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	from := time.Date(2024, 1, 1, 1, 0, 0, 0, time.FixedZone("WAT", 3600))
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.FixedZone("WAT", 3600))
+	ledger, err := client.ListBalanceLedger(&ListBalanceLedgerRequest{From: from, To: to})
+	require.NoError(t, err)
+	require.Equal(t, "-500.00", ledger.Data[0].DifferenceMoney().Decimal())
+	require.Equal(t, "20782.24", ledger.Data[0].Money().Decimal())
+	require.JSONEq(t, `{"id":1}`, string(ledger.Data[0].ModelRow))
+
+	settlements, err := client.ListSettlements(&ListSettlementsRequest{Status: SettlementStatusSuccess, Subaccount: "none"})
+	require.NoError(t, err)
+	require.Equal(t, "4925.00", settlements.Data[0].Money().Decimal())
+	require.Equal(t, "75.00", settlements.Data[0].FeesMoney().Decimal())
+	require.Nil(t, settlements.Data[0].Deductions)
+
+	transactions, err := client.ListSettlementTransactions(3090024, &ListSettlementTransactionsRequest{Page: 2})
+	require.NoError(t, err)
+	require.Equal(t, "T4xv5fkh4x", transactions.Data[0].Reference)
+	require.False(t, transactions.Meta.HasNextPage())
+
+	_, err = client.ListSettlementTransactions(0, nil)
+	require.EqualError(t, err, "settlement id is required")
+}
+
+// pagedServer serves items from fixtures split into pages of perPage and counts
+// the requests per path.
+func pagedServer(t *testing.T, fixtures map[string][]map[string]interface{}, requests map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items, ok := fixtures[r.URL.Path]
+		if !ok {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		requests[r.URL.Path]++
+
+		var page, perPage int
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		fmt.Sscan(r.URL.Query().Get("perPage"), &perPage)
+		start := (page - 1) * perPage
+		end := start + perPage
+		if start > len(items) {
+			start = len(items)
+		}
+		if end > len(items) {
+			end = len(items)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  true,
+			"message": "Retrieved",
+			"data":    items[start:end],
+			"meta":    map[string]interface{}{"total": len(items), "perPage": perPage, "page": page, "pageCount": (len(items) + perPage - 1) / perPage},
+		})
+	}))
+}
+
+func TestReportExporter(t *testing.T) {
+	requests := make(map[string]int)
+	server := pagedServer(t, map[string][]map[string]interface{}{
+		"/balance/ledger": {
+			{"id": 1, "currency": "NGN", "balance": 150000, "difference": 150000, "reason": "Deposit", "model_responsible": "Transaction", "createdAt": "2024-01-02T10:00:00.000Z"},
+			{"id": 2, "currency": "NGN", "balance": 100000, "difference": -50000, "reason": "Rent, January", "model_responsible": "Transfer", "createdAt": "2024-01-03T10:00:00.000Z"},
+			{"id": 3, "currency": "NGN", "balance": 99950, "difference": -50, "reason": "Transfer fee", "model_responsible": "Transfer", "createdAt": "2024-01-03T10:00:01.000Z"},
+		},
+		"/settlement": {
+			{"id": 10, "status": "success", "currency": "NGN", "total_amount": 300000, "total_fees": 4500, "effective_amount": 295500, "settlement_date": "2024-01-04T00:00:00.000Z"},
+			{"id": 11, "status": "pending", "currency": "NGN", "total_amount": 0, "total_fees": 0, "effective_amount": 0, "settlement_date": "2024-01-05T00:00:00.000Z"},
+		},
+		"/settlement/10/transactions": {
+			{"id": 100, "reference": "ref-1", "channel": "card", "currency": "NGN", "amount": 200000, "fees": 3000, "paid_at": "2024-01-03T08:00:00.000Z"},
+			{"id": 101, "reference": "ref-2", "channel": "bank_transfer", "currency": "NGN", "amount": 100000, "fees": 1500, "paid_at": "2024-01-03T09:00:00.000Z"},
+		},
+		"/settlement/11/transactions": {},
+	}, requests)
+	defer server.Close()
+
+	exporter := NewReportExporter(NewPaystackClient(server.URL, testSecretKey), 2)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	var ledgerCSV bytes.Buffer
+	require.NoError(t, exporter.ExportBalanceLedger(ctx, &ledgerCSV, ReportFormatCSV, from, to))
+	require.Equal(t, 2, requests["/balance/ledger"])
+	require.Equal(t, "id,created_at,currency,difference,balance,reason,model_responsible\n"+
+		"1,2024-01-02T10:00:00.000Z,NGN,1500.00,1500.00,Deposit,Transaction\n"+
+		"2,2024-01-03T10:00:00.000Z,NGN,-500.00,1000.00,\"Rent, January\",Transfer\n"+
+		"3,2024-01-03T10:00:01.000Z,NGN,-0.50,999.50,Transfer fee,Transfer\n", ledgerCSV.String())
+
+	var ledgerJSON bytes.Buffer
+	require.NoError(t, exporter.ExportBalanceLedger(ctx, &ledgerJSON, ReportFormatJSON, from, to))
+	var entries []BalanceLedgerEntry
+	require.NoError(t, json.Unmarshal(ledgerJSON.Bytes(), &entries))
+	require.Len(t, entries, 3)
+	require.Equal(t, int64(-50), entries[2].Difference)
+
+	var settlementCSV bytes.Buffer
+	require.NoError(t, exporter.ExportSettlements(ctx, &settlementCSV, ReportFormatCSV, from, to))
+	require.Equal(t, "settlement_id,settlement_date,settlement_status,currency,total_amount,total_fees,effective_amount,transaction_id,reference,channel,amount,fees,paid_at\n"+
+		"10,2024-01-04T00:00:00.000Z,success,NGN,3000.00,45.00,2955.00,100,ref-1,card,2000.00,30.00,2024-01-03T08:00:00.000Z\n"+
+		"10,2024-01-04T00:00:00.000Z,success,NGN,3000.00,45.00,2955.00,101,ref-2,bank_transfer,1000.00,15.00,2024-01-03T09:00:00.000Z\n"+
+		"11,2024-01-05T00:00:00.000Z,pending,NGN,0.00,0.00,0.00,,,,,,\n", settlementCSV.String())
+
+	var settlementJSON bytes.Buffer
+	require.NoError(t, exporter.ExportSettlements(ctx, &settlementJSON, ReportFormatJSON, from, to))
+	var reports []SettlementReport
+	require.NoError(t, json.Unmarshal(settlementJSON.Bytes(), &reports))
+	require.Len(t, reports, 2)
+	require.Equal(t, 10, reports[0].ID)
+	require.Len(t, reports[0].Transactions, 2)
+	require.Empty(t, reports[1].Transactions)
+	require.Contains(t, settlementJSON.String(), `"transactions": []`)
+
+	err := exporter.ExportSettlements(ctx, &bytes.Buffer{}, "xlsx", from, to)
+	require.EqualError(t, err, `unsupported report format "xlsx"`)
+	_, err = exporter.BalanceLedger(ctx, to, from)
+	require.EqualError(t, err, "report range ends before it starts")
+}
+
+func TestReportExporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":false,"message":"Invalid date range"}`))
+			return
+		}
+		w.Write([]byte(`{"status":true,"message":"Retrieved","data":[{"id":1}],"meta":{"total":2,"perPage":1,"page":1,"pageCount":2}}`))
+	}))
+	defer server.Close()
+
+	exporter := NewReportExporter(NewPaystackClient(server.URL, testSecretKey), 1)
+	_, err := exporter.BalanceLedger(context.Background(), time.Time{}, time.Time{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to list balance ledger page 2")
+	_, ok := AsPaystackError(err)
+	require.True(t, ok)
+}
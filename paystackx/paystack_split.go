@@ -0,0 +1,283 @@
+package paystackx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Split types. Percentage shares are whole percents of the payment; flat
+// shares are amounts in the minor unit of the split currency.
+const (
+	SplitTypePercentage = "percentage"
+	SplitTypeFlat       = "flat"
+)
+
+// Split bearer types: who pays the Paystack fees of a split payment.
+const (
+	SplitBearerAccount         = "account"
+	SplitBearerSubaccount      = "subaccount"
+	SplitBearerAllProportional = "all-proportional"
+	SplitBearerAll             = "all"
+)
+
+// SplitSubaccount is a subaccount code and its share in a split request.
+type SplitSubaccount struct {
+	Subaccount string `json:"subaccount"`
+	Share      int64  `json:"share"`
+}
+
+// CreateSplitRequest creates a split that can be named at charge time with
+// InitializeTransactionRequest.SplitCode. Whatever is left after the shares
+// goes to the main account. BearerSubaccount is required when BearerType is
+// SplitBearerSubaccount.
+type CreateSplitRequest struct {
+	Name             string            `json:"name"`
+	Type             string            `json:"type"`
+	Currency         string            `json:"currency"`
+	Subaccounts      []SplitSubaccount `json:"subaccounts"`
+	BearerType       string            `json:"bearer_type,omitempty"`
+	BearerSubaccount string            `json:"bearer_subaccount,omitempty"`
+}
+
+// UpdateSplitRequest changes a split. Zero values are left unchanged; set
+// Active to false to retire the split.
+type UpdateSplitRequest struct {
+	Name             string `json:"name,omitempty"`
+	Active           *bool  `json:"active,omitempty"`
+	BearerType       string `json:"bearer_type,omitempty"`
+	BearerSubaccount string `json:"bearer_subaccount,omitempty"`
+}
+
+// SplitShare is a subaccount and its share in a split.
+type SplitShare struct {
+	Subaccount Subaccount `json:"subaccount"`
+	Share      int64      `json:"share"`
+}
+
+type Split struct {
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`
+	Type             string       `json:"type"`
+	Currency         string       `json:"currency"`
+	Integration      int          `json:"integration"`
+	Domain           string       `json:"domain"`
+	SplitCode        string       `json:"split_code"`
+	Active           bool         `json:"active"`
+	BearerType       string       `json:"bearer_type"`
+	BearerSubaccount *int         `json:"bearer_subaccount"`
+	Subaccounts      []SplitShare `json:"subaccounts"`
+	TotalSubaccounts int          `json:"total_subaccounts"`
+	CreatedAt        string       `json:"createdAt"`
+	UpdatedAt        string       `json:"updatedAt"`
+}
+
+type SplitResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    Split  `json:"data"`
+}
+
+// ListSplitsRequest filters ListSplits. Zero values are omitted.
+type ListSplitsRequest struct {
+	PerPage int
+	Page    int
+	Name    string
+	Active  *bool
+	From    time.Time
+	To      time.Time
+}
+
+func (r *ListSplitsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Name != "" {
+		query.Set("name", r.Name)
+	}
+	if r.Active != nil {
+		query.Set("active", strconv.FormatBool(*r.Active))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListSplitsResponse struct {
+	Status  bool    `json:"status"`
+	Message string  `json:"message"`
+	Data    []Split `json:"data"`
+	Meta    Meta    `json:"meta"`
+}
+
+var splitBearers = map[string]bool{
+	SplitBearerAccount:         true,
+	SplitBearerSubaccount:      true,
+	SplitBearerAllProportional: true,
+	SplitBearerAll:             true,
+}
+
+func (r *CreateSplitRequest) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("split name is required")
+	}
+	if r.Type != SplitTypePercentage && r.Type != SplitTypeFlat {
+		return fmt.Errorf("invalid split type %q", r.Type)
+	}
+	if r.Currency == "" {
+		return fmt.Errorf("currency is required")
+	}
+	if len(r.Subaccounts) == 0 {
+		return fmt.Errorf("at least one subaccount is required")
+	}
+	var total int64
+	for _, subaccount := range r.Subaccounts {
+		if subaccount.Subaccount == "" || subaccount.Share <= 0 {
+			return fmt.Errorf("every subaccount needs a code and a positive share")
+		}
+		total += subaccount.Share
+	}
+	if r.Type == SplitTypePercentage && total > 100 {
+		return fmt.Errorf("percentage shares add up to %d, more than 100", total)
+	}
+	return validateBearer(r.BearerType, r.BearerSubaccount)
+}
+
+func validateBearer(bearerType, bearerSubaccount string) error {
+	if bearerType != "" && !splitBearers[bearerType] {
+		return fmt.Errorf("invalid bearer type %q", bearerType)
+	}
+	if bearerType == SplitBearerSubaccount && bearerSubaccount == "" {
+		return fmt.Errorf("bearer subaccount is required when the subaccount bears the fees")
+	}
+	return nil
+}
+
+func (p *paystackClient) CreateSplit(data *CreateSplitRequest) (*SplitResponse, error) {
+	return p.CreateSplitWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateSplitWithContext(ctx context.Context, data *CreateSplitRequest) (*SplitResponse, error) {
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+
+	var response SplitResponse
+	if err := p.doJSON(ctx, "POST", "split", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListSplits(filter *ListSplitsRequest) (*ListSplitsResponse, error) {
+	return p.ListSplitsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListSplitsWithContext(ctx context.Context, filter *ListSplitsRequest) (*ListSplitsResponse, error) {
+	endpoint := "split"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListSplitsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchSplit(id int) (*SplitResponse, error) {
+	return p.FetchSplitWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) FetchSplitWithContext(ctx context.Context, id int) (*SplitResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("split id is required")
+	}
+
+	var response SplitResponse
+	if err := p.doJSON(ctx, "GET", "split/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// UpdateSplit changes a split. Paystack has no delete; deactivate with Active
+// set to false instead.
+func (p *paystackClient) UpdateSplit(id int, data *UpdateSplitRequest) (*SplitResponse, error) {
+	return p.UpdateSplitWithContext(context.Background(), id, data)
+}
+
+func (p *paystackClient) UpdateSplitWithContext(ctx context.Context, id int, data *UpdateSplitRequest) (*SplitResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("split id is required")
+	}
+	if err := validateBearer(data.BearerType, data.BearerSubaccount); err != nil {
+		return nil, err
+	}
+
+	var response SplitResponse
+	if err := p.doJSON(ctx, "PUT", "split/"+strconv.Itoa(id), data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// AddSplitSubaccount adds a subaccount to a split, or changes its share if it
+// is already part of it.
+func (p *paystackClient) AddSplitSubaccount(id int, data *SplitSubaccount) (*SplitResponse, error) {
+	return p.AddSplitSubaccountWithContext(context.Background(), id, data)
+}
+
+func (p *paystackClient) AddSplitSubaccountWithContext(ctx context.Context, id int, data *SplitSubaccount) (*SplitResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("split id is required")
+	}
+	if data.Subaccount == "" || data.Share <= 0 {
+		return nil, fmt.Errorf("subaccount code and a positive share are required")
+	}
+
+	var response SplitResponse
+	if err := p.doJSON(ctx, "POST", "split/"+strconv.Itoa(id)+"/subaccount/add", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) RemoveSplitSubaccount(id int, subaccountCode string) (*MessageResponse, error) {
+	return p.RemoveSplitSubaccountWithContext(context.Background(), id, subaccountCode)
+}
+
+func (p *paystackClient) RemoveSplitSubaccountWithContext(ctx context.Context, id int, subaccountCode string) (*MessageResponse, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("split id is required")
+	}
+	if subaccountCode == "" {
+		return nil, fmt.Errorf("subaccount code is required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "split/"+strconv.Itoa(id)+"/subaccount/remove", map[string]string{"subaccount": subaccountCode}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,115 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /split":
+			var body CreateSplitRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, []SplitSubaccount{{Subaccount: "ACCT_4hl4xenwpjy5wb", Share: 90}}, body.Subaccounts)
+			w.Write([]byte(`{"status":true,"message":"Split created","data":{"id":142,"name":"Voucher redemptions","type":"percentage","currency":"NGN","split_code":"SPL_e7jnRLtzla","active":true,"bearer_type":"subaccount","bearer_subaccount":55,"subaccounts":[{"subaccount":{"id":55,"subaccount_code":"ACCT_4hl4xenwpjy5wb","business_name":"Mama Put"},"share":90}],"total_subaccounts":1}}`))
+		case "GET /split":
+			require.Equal(t, "true", r.URL.Query().Get("active"))
+			w.Write([]byte(`{"status":true,"message":"Split retrieved","data":[],"meta":{"total":0,"perPage":50,"page":1,"pageCount":0}}`))
+		case "PUT /split/142":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]interface{}{"active": false}, body)
+			w.Write([]byte(`{"status":true,"message":"Split group updated","data":{"id":142,"active":false}}`))
+		case "POST /split/142/subaccount/add":
+			w.Write([]byte(`{"status":true,"message":"Subaccount added","data":{"id":142,"total_subaccounts":2}}`))
+		case "POST /split/142/subaccount/remove":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "ACCT_eg4sob4590pq9vb", body["subaccount"])
+			w.Write([]byte(`{"status":true,"message":"Subaccount removed"}`))
+		case "POST /transaction/initialize":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "SPL_e7jnRLtzla", body["split_code"])
+			w.Write([]byte(`{"status":true,"message":"Authorization URL created","data":{"reference":"voucher-1"}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	split, err := client.CreateSplit(&CreateSplitRequest{
+		Name:             "Voucher redemptions",
+		Type:             SplitTypePercentage,
+		Currency:         "NGN",
+		Subaccounts:      []SplitSubaccount{{Subaccount: "ACCT_4hl4xenwpjy5wb", Share: 90}},
+		BearerType:       SplitBearerSubaccount,
+		BearerSubaccount: "ACCT_4hl4xenwpjy5wb",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Mama Put", split.Data.Subaccounts[0].Subaccount.BusinessName)
+	require.Equal(t, 55, *split.Data.BearerSubaccount)
+
+	active := true
+	_, err = client.ListSplits(&ListSplitsRequest{Active: &active})
+	require.NoError(t, err)
+	active = false
+	_, err = client.UpdateSplit(142, &UpdateSplitRequest{Active: &active})
+	require.NoError(t, err)
+	added, err := client.AddSplitSubaccount(142, &SplitSubaccount{Subaccount: "ACCT_eg4sob4590pq9vb", Share: 5})
+	require.NoError(t, err)
+	require.Equal(t, 2, added.Data.TotalSubaccounts)
+	_, err = client.RemoveSplitSubaccount(142, "ACCT_eg4sob4590pq9vb")
+	require.NoError(t, err)
+
+	_, err = client.InitializeTransaction(&InitializeTransactionRequest{Email: "ada@example.com", Amount: 500000, Reference: "voucher-1", SplitCode: split.Data.SplitCode})
+	require.NoError(t, err)
+}
+
+func TestSplitValidation(t *testing.T) {
+	client := NewPaystackClient("http://127.0.0.1:0", testSecretKey)
+	valid := func() *CreateSplitRequest {
+		return &CreateSplitRequest{Name: "Vouchers", Type: SplitTypePercentage, Currency: "NGN", Subaccounts: []SplitSubaccount{{Subaccount: "ACCT_a", Share: 60}}}
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*CreateSplitRequest)
+		err    string
+	}{
+		{"type", func(r *CreateSplitRequest) { r.Type = "ratio" }, `invalid split type "ratio"`},
+		{"no subaccounts", func(r *CreateSplitRequest) { r.Subaccounts = nil }, "at least one subaccount is required"},
+		{"zero share", func(r *CreateSplitRequest) { r.Subaccounts[0].Share = 0 }, "every subaccount needs a code and a positive share"},
+		{"over 100", func(r *CreateSplitRequest) {
+			r.Subaccounts = append(r.Subaccounts, SplitSubaccount{Subaccount: "ACCT_b", Share: 50})
+		}, "percentage shares add up to 110, more than 100"},
+		{"bearer", func(r *CreateSplitRequest) { r.BearerType = "merchant" }, `invalid bearer type "merchant"`},
+		{"bearer subaccount", func(r *CreateSplitRequest) { r.BearerType = SplitBearerSubaccount }, "bearer subaccount is required when the subaccount bears the fees"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := valid()
+			tc.modify(request)
+			_, err := client.CreateSplit(request)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	// Flat shares are amounts, so they may add up to more than 100.
+	flat := valid()
+	flat.Type = SplitTypeFlat
+	flat.Subaccounts[0].Share = 150000
+	require.NoError(t, flat.validate())
+
+	_, err := client.InitializeTransaction(&InitializeTransactionRequest{Email: "ada@example.com", Amount: 100, Subaccount: "ACCT_a", SplitCode: "SPL_a"})
+	require.EqualError(t, err, "set either a subaccount or a split code, not both")
+	_, err = client.FetchSplit(0)
+	require.EqualError(t, err, "split id is required")
+}
@@ -0,0 +1,235 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+)
+
+// Subaccount settlement schedules.
+const (
+	SettlementScheduleAuto    = "auto"
+	SettlementScheduleWeekly  = "weekly"
+	SettlementScheduleMonthly = "monthly"
+	SettlementScheduleManual  = "manual"
+)
+
+// CreateSubaccountRequest creates a subaccount that settles to a bank
+// account. PercentageCharge is the share of each payment Longswipe keeps when
+// a payment names the subaccount directly.
+type CreateSubaccountRequest struct {
+	BusinessName        string          `json:"business_name"`
+	SettlementBank      string          `json:"settlement_bank"`
+	AccountNumber       string          `json:"account_number"`
+	PercentageCharge    float64         `json:"percentage_charge"`
+	Description         string          `json:"description,omitempty"`
+	PrimaryContactEmail string          `json:"primary_contact_email,omitempty"`
+	PrimaryContactName  string          `json:"primary_contact_name,omitempty"`
+	PrimaryContactPhone string          `json:"primary_contact_phone,omitempty"`
+	Metadata            json.RawMessage `json:"metadata,omitempty"`
+}
+
+// NewMerchantSubaccountRequest builds a subaccount for a merchant business.
+// The merchant code is kept in the metadata so the subaccount can be traced
+// back to the business.
+func NewMerchantSubaccountRequest(business interfacesx.FetchBusinessByResponse, bankCode, accountNumber string, percentageCharge float64) *CreateSubaccountRequest {
+	name := business.TradingName
+	if name == "" {
+		name = business.BusinessName
+	}
+	fields := map[string]string{"merchant_code": business.MerchantCode}
+	if !business.ID.IsNil() {
+		fields["business_id"] = business.ID.String()
+	}
+	metadata, _ := json.Marshal(fields)
+	return &CreateSubaccountRequest{
+		BusinessName:        name,
+		SettlementBank:      bankCode,
+		AccountNumber:       accountNumber,
+		PercentageCharge:    percentageCharge,
+		Description:         business.BusinessDescription,
+		PrimaryContactEmail: business.BusinessEmail,
+		Metadata:            metadata,
+	}
+}
+
+// UpdateSubaccountRequest changes a subaccount. Zero values are left
+// unchanged; set Active to false to stop settling to it.
+type UpdateSubaccountRequest struct {
+	BusinessName        string          `json:"business_name,omitempty"`
+	SettlementBank      string          `json:"settlement_bank,omitempty"`
+	AccountNumber       string          `json:"account_number,omitempty"`
+	PercentageCharge    *float64        `json:"percentage_charge,omitempty"`
+	Description         string          `json:"description,omitempty"`
+	PrimaryContactEmail string          `json:"primary_contact_email,omitempty"`
+	PrimaryContactName  string          `json:"primary_contact_name,omitempty"`
+	PrimaryContactPhone string          `json:"primary_contact_phone,omitempty"`
+	SettlementSchedule  string          `json:"settlement_schedule,omitempty"`
+	Active              *bool           `json:"active,omitempty"`
+	Metadata            json.RawMessage `json:"metadata,omitempty"`
+}
+
+type Subaccount struct {
+	ID                  int             `json:"id"`
+	Integration         int             `json:"integration"`
+	Domain              string          `json:"domain"`
+	SubaccountCode      string          `json:"subaccount_code"`
+	BusinessName        string          `json:"business_name"`
+	Description         string          `json:"description"`
+	PrimaryContactName  *string         `json:"primary_contact_name"`
+	PrimaryContactEmail *string         `json:"primary_contact_email"`
+	PrimaryContactPhone *string         `json:"primary_contact_phone"`
+	Metadata            json.RawMessage `json:"metadata"`
+	PercentageCharge    float64         `json:"percentage_charge"`
+	IsVerified          bool            `json:"is_verified"`
+	SettlementBank      string          `json:"settlement_bank"`
+	AccountNumber       string          `json:"account_number"`
+	SettlementSchedule  string          `json:"settlement_schedule"`
+	Active              bool            `json:"active"`
+	Currency            string          `json:"currency"`
+	CreatedAt           string          `json:"createdAt"`
+	UpdatedAt           string          `json:"updatedAt"`
+}
+
+// MerchantCode returns the merchant code stored by NewMerchantSubaccountRequest.
+func (s *Subaccount) MerchantCode() string {
+	var metadata struct {
+		MerchantCode string `json:"merchant_code"`
+	}
+	if len(s.Metadata) == 0 || json.Unmarshal(s.Metadata, &metadata) != nil {
+		return ""
+	}
+	return metadata.MerchantCode
+}
+
+type SubaccountResponse struct {
+	Status  bool       `json:"status"`
+	Message string     `json:"message"`
+	Data    Subaccount `json:"data"`
+}
+
+// ListSubaccountsRequest filters ListSubaccounts. Zero values are omitted.
+type ListSubaccountsRequest struct {
+	PerPage int
+	Page    int
+	From    time.Time
+	To      time.Time
+}
+
+func (r *ListSubaccountsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListSubaccountsResponse struct {
+	Status  bool         `json:"status"`
+	Message string       `json:"message"`
+	Data    []Subaccount `json:"data"`
+	Meta    Meta         `json:"meta"`
+}
+
+func validPercentage(percentage float64) bool {
+	return percentage >= 0 && percentage <= 100
+}
+
+func (p *paystackClient) CreateSubaccount(data *CreateSubaccountRequest) (*SubaccountResponse, error) {
+	return p.CreateSubaccountWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateSubaccountWithContext(ctx context.Context, data *CreateSubaccountRequest) (*SubaccountResponse, error) {
+	if strings.TrimSpace(data.BusinessName) == "" {
+		return nil, fmt.Errorf("business name is required")
+	}
+	if data.SettlementBank == "" || data.AccountNumber == "" {
+		return nil, fmt.Errorf("settlement bank and account number are required")
+	}
+	if !validPercentage(data.PercentageCharge) {
+		return nil, fmt.Errorf("percentage charge must be between 0 and 100")
+	}
+
+	var response SubaccountResponse
+	if err := p.doJSON(ctx, "POST", "subaccount", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListSubaccounts(filter *ListSubaccountsRequest) (*ListSubaccountsResponse, error) {
+	return p.ListSubaccountsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListSubaccountsWithContext(ctx context.Context, filter *ListSubaccountsRequest) (*ListSubaccountsResponse, error) {
+	endpoint := "subaccount"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListSubaccountsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// FetchSubaccount fetches a subaccount by its ID or subaccount code.
+func (p *paystackClient) FetchSubaccount(idOrCode string) (*SubaccountResponse, error) {
+	return p.FetchSubaccountWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) FetchSubaccountWithContext(ctx context.Context, idOrCode string) (*SubaccountResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("subaccount id or code is required")
+	}
+
+	var response SubaccountResponse
+	if err := p.doJSON(ctx, "GET", "subaccount/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// UpdateSubaccount changes a subaccount. Paystack has no delete; deactivate
+// with Active set to false instead.
+func (p *paystackClient) UpdateSubaccount(idOrCode string, data *UpdateSubaccountRequest) (*SubaccountResponse, error) {
+	return p.UpdateSubaccountWithContext(context.Background(), idOrCode, data)
+}
+
+func (p *paystackClient) UpdateSubaccountWithContext(ctx context.Context, idOrCode string, data *UpdateSubaccountRequest) (*SubaccountResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("subaccount id or code is required")
+	}
+	if data.PercentageCharge != nil && !validPercentage(*data.PercentageCharge) {
+		return nil, fmt.Errorf("percentage charge must be between 0 and 100")
+	}
+
+	var response SubaccountResponse
+	if err := p.doJSON(ctx, "PUT", "subaccount/"+url.PathEscape(idOrCode), data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
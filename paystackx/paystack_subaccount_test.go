@@ -0,0 +1,66 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubaccountRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /subaccount":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "Mama Put", body["business_name"])
+			require.Equal(t, "058", body["settlement_bank"])
+			require.Equal(t, 2.5, body["percentage_charge"])
+			require.Equal(t, map[string]interface{}{"merchant_code": "MRC-001", "business_id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}, body["metadata"])
+			w.Write([]byte(`{"status":true,"message":"Subaccount created","data":{"id":55,"subaccount_code":"ACCT_4hl4xenwpjy5wb","business_name":"Mama Put","percentage_charge":2.5,"settlement_bank":"Guaranty Trust Bank","account_number":"0123456789","active":true,"currency":"NGN","metadata":{"merchant_code":"MRC-001"}}}`))
+		case "GET /subaccount":
+			require.Equal(t, "2", r.URL.Query().Get("page"))
+			w.Write([]byte(`{"status":true,"message":"Subaccounts retrieved","data":[],"meta":{"total":0,"perPage":50,"page":2,"pageCount":0}}`))
+		case "PUT /subaccount/ACCT_4hl4xenwpjy5wb":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]interface{}{"active": false}, body)
+			w.Write([]byte(`{"status":true,"message":"Subaccount updated","data":{"id":55,"subaccount_code":"ACCT_4hl4xenwpjy5wb","active":false}}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	business := interfacesx.FetchBusinessByResponse{
+		ID:           uuid.Must(uuid.FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8")),
+		BusinessName: "Mama Put Foods Ltd",
+		TradingName:  "Mama Put",
+		MerchantCode: "MRC-001",
+	}
+	created, err := client.CreateSubaccount(NewMerchantSubaccountRequest(business, "058", "0123456789", 2.5))
+	require.NoError(t, err)
+	require.Equal(t, "ACCT_4hl4xenwpjy5wb", created.Data.SubaccountCode)
+	require.Equal(t, "MRC-001", created.Data.MerchantCode())
+
+	_, err = client.ListSubaccounts(&ListSubaccountsRequest{Page: 2})
+	require.NoError(t, err)
+
+	inactive := false
+	updated, err := client.UpdateSubaccount("ACCT_4hl4xenwpjy5wb", &UpdateSubaccountRequest{Active: &inactive})
+	require.NoError(t, err)
+	require.False(t, updated.Data.Active)
+
+	_, err = client.CreateSubaccount(&CreateSubaccountRequest{BusinessName: "Mama Put", SettlementBank: "058", AccountNumber: "0123456789", PercentageCharge: 120})
+	require.EqualError(t, err, "percentage charge must be between 0 and 100")
+	_, err = client.CreateSubaccount(&CreateSubaccountRequest{BusinessName: " "})
+	require.EqualError(t, err, "business name is required")
+	_, err = client.FetchSubaccount("")
+	require.EqualError(t, err, "subaccount id or code is required")
+}
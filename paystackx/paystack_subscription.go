@@ -0,0 +1,427 @@
+package paystackx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+const (
+	PlanIntervalHourly     = "hourly"
+	PlanIntervalDaily      = "daily"
+	PlanIntervalWeekly     = "weekly"
+	PlanIntervalMonthly    = "monthly"
+	PlanIntervalQuarterly  = "quarterly"
+	PlanIntervalBiannually = "biannually"
+	PlanIntervalAnnually   = "annually"
+)
+
+const (
+	SubscriptionStatusActive      = "active"
+	SubscriptionStatusNonRenewing = "non-renewing"
+	SubscriptionStatusAttention   = "attention"
+	SubscriptionStatusCompleted   = "completed"
+	SubscriptionStatusCancelled   = "cancelled"
+)
+
+var planIntervals = map[string]bool{
+	PlanIntervalHourly:     true,
+	PlanIntervalDaily:      true,
+	PlanIntervalWeekly:     true,
+	PlanIntervalMonthly:    true,
+	PlanIntervalQuarterly:  true,
+	PlanIntervalBiannually: true,
+	PlanIntervalAnnually:   true,
+}
+
+// CreatePlanRequest creates a recurring plan. Amount is in the minor unit of
+// the currency.
+type CreatePlanRequest struct {
+	Name         string `json:"name"`
+	Amount       int64  `json:"amount"`
+	Interval     string `json:"interval"`
+	Description  string `json:"description,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	InvoiceLimit int    `json:"invoice_limit,omitempty"`
+	SendInvoices *bool  `json:"send_invoices,omitempty"`
+	SendSMS      *bool  `json:"send_sms,omitempty"`
+}
+
+// UpdatePlanRequest changes a plan. Zero values are left unchanged.
+type UpdatePlanRequest struct {
+	Name         string `json:"name,omitempty"`
+	Amount       int64  `json:"amount,omitempty"`
+	Interval     string `json:"interval,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	InvoiceLimit int    `json:"invoice_limit,omitempty"`
+	SendInvoices *bool  `json:"send_invoices,omitempty"`
+	SendSMS      *bool  `json:"send_sms,omitempty"`
+	// UpdateExistingSubscriptions applies the change to current subscribers.
+	UpdateExistingSubscriptions *bool `json:"update_existing_subscriptions,omitempty"`
+}
+
+// Plan is a plan as returned by the plan endpoints.
+type Plan struct {
+	PaystackEventPlan
+	Domain        string         `json:"domain"`
+	Integration   int            `json:"integration"`
+	InvoiceLimit  int            `json:"invoice_limit"`
+	HostedPage    bool           `json:"hosted_page"`
+	IsDeleted     bool           `json:"is_deleted"`
+	IsArchived    bool           `json:"is_archived"`
+	Subscriptions []Subscription `json:"subscriptions"`
+	CreatedAt     string         `json:"createdAt"`
+	UpdatedAt     string         `json:"updatedAt"`
+}
+
+type PlanResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    Plan   `json:"data"`
+}
+
+// ListPlansRequest filters ListPlans. Zero values are omitted.
+type ListPlansRequest struct {
+	PerPage  int
+	Page     int
+	Status   string
+	Interval string
+	Amount   int64
+}
+
+func (r *ListPlansRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Status != "" {
+		query.Set("status", r.Status)
+	}
+	if r.Interval != "" {
+		query.Set("interval", r.Interval)
+	}
+	if r.Amount > 0 {
+		query.Set("amount", strconv.FormatInt(r.Amount, 10))
+	}
+	return query
+}
+
+type ListPlansResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    []Plan `json:"data"`
+	Meta    Meta   `json:"meta"`
+}
+
+// Subscription is a customer's subscription to a plan. It is also the data
+// of the subscription.* webhooks.
+type Subscription struct {
+	ID               int                        `json:"id"`
+	Domain           string                     `json:"domain"`
+	Status           string                     `json:"status"`
+	SubscriptionCode string                     `json:"subscription_code"`
+	EmailToken       string                     `json:"email_token"`
+	Amount           int64                      `json:"amount"`
+	Quantity         int                        `json:"quantity"`
+	CronExpression   string                     `json:"cron_expression"`
+	NextPaymentDate  *string                    `json:"next_payment_date"`
+	OpenInvoice      *string                    `json:"open_invoice"`
+	InvoiceLimit     int                        `json:"invoice_limit"`
+	PaymentsCount    int                        `json:"payments_count"`
+	CancelledAt      *string                    `json:"cancelledAt"`
+	CreatedAt        string                     `json:"createdAt"`
+	Plan             PaystackEventPlan          `json:"plan"`
+	Customer         PaystackEventCustomer      `json:"customer"`
+	Authorization    PaystackEventAuthorization `json:"authorization"`
+}
+
+// Money returns the amount charged per interval.
+func (s *Subscription) Money() moneyx.Money {
+	return moneyx.New(s.Amount, s.Plan.Currency)
+}
+
+type SubscriptionResponse struct {
+	Status  bool         `json:"status"`
+	Message string       `json:"message"`
+	Data    Subscription `json:"data"`
+}
+
+// CreateSubscriptionRequest subscribes a customer, by email or customer code,
+// to a plan code. Without an authorization the customer's most recent one is charged.
+type CreateSubscriptionRequest struct {
+	Customer      string     `json:"customer"`
+	Plan          string     `json:"plan"`
+	Authorization string     `json:"authorization,omitempty"`
+	StartDate     *time.Time `json:"start_date,omitempty"`
+}
+
+// CreateSubscriptionResponse is returned by CreateSubscription. Unlike fetch,
+// the customer and plan come back as IDs.
+type CreateSubscriptionResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		ID               int                        `json:"id"`
+		Customer         int                        `json:"customer"`
+		Plan             int                        `json:"plan"`
+		Integration      int                        `json:"integration"`
+		Domain           string                     `json:"domain"`
+		Status           string                     `json:"status"`
+		Quantity         int                        `json:"quantity"`
+		Amount           int64                      `json:"amount"`
+		SubscriptionCode string                     `json:"subscription_code"`
+		EmailToken       string                     `json:"email_token"`
+		Authorization    PaystackEventAuthorization `json:"authorization"`
+		CreatedAt        string                     `json:"createdAt"`
+	} `json:"data"`
+}
+
+// ListSubscriptionsRequest filters ListSubscriptions. Zero values are omitted.
+type ListSubscriptionsRequest struct {
+	PerPage  int
+	Page     int
+	Customer int
+	Plan     int
+}
+
+func (r *ListSubscriptionsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Customer > 0 {
+		query.Set("customer", strconv.Itoa(r.Customer))
+	}
+	if r.Plan > 0 {
+		query.Set("plan", strconv.Itoa(r.Plan))
+	}
+	return query
+}
+
+type ListSubscriptionsResponse struct {
+	Status  bool           `json:"status"`
+	Message string         `json:"message"`
+	Data    []Subscription `json:"data"`
+	Meta    Meta           `json:"meta"`
+}
+
+type subscriptionToken struct {
+	Code  string `json:"code"`
+	Token string `json:"token"`
+}
+
+// InvoiceTransaction is the charge attempted for an invoice.
+type InvoiceTransaction struct {
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+// InvoiceEventData is the data of the invoice.create, invoice.update and
+// invoice.payment_failed webhooks.
+type InvoiceEventData struct {
+	Domain        string                     `json:"domain"`
+	InvoiceCode   string                     `json:"invoice_code"`
+	Amount        int64                      `json:"amount"`
+	PeriodStart   string                     `json:"period_start"`
+	PeriodEnd     string                     `json:"period_end"`
+	Status        string                     `json:"status"`
+	Paid          bool                       `json:"paid"`
+	PaidAt        *string                    `json:"paid_at"`
+	Description   *string                    `json:"description"`
+	Authorization PaystackEventAuthorization `json:"authorization"`
+	Subscription  Subscription               `json:"subscription"`
+	Customer      PaystackEventCustomer      `json:"customer"`
+	Transaction   *InvoiceTransaction        `json:"transaction"`
+	CreatedAt     string                     `json:"created_at"`
+}
+
+// Money returns the invoiced amount. The currency comes from the charge, so
+// it is empty for invoices that have not been charged yet.
+func (d *InvoiceEventData) Money() moneyx.Money {
+	if d.Transaction == nil {
+		return moneyx.New(d.Amount, "")
+	}
+	return moneyx.New(d.Amount, d.Transaction.Currency)
+}
+
+func (p *paystackClient) CreatePlan(data *CreatePlanRequest) (*PlanResponse, error) {
+	return p.CreatePlanWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreatePlanWithContext(ctx context.Context, data *CreatePlanRequest) (*PlanResponse, error) {
+	if data.Name == "" {
+		return nil, fmt.Errorf("plan name is required")
+	}
+	if data.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if !planIntervals[data.Interval] {
+		return nil, fmt.Errorf("invalid plan interval %q", data.Interval)
+	}
+
+	var response PlanResponse
+	if err := p.doJSON(ctx, "POST", "plan", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListPlans(filter *ListPlansRequest) (*ListPlansResponse, error) {
+	return p.ListPlansWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListPlansWithContext(ctx context.Context, filter *ListPlansRequest) (*ListPlansResponse, error) {
+	endpoint := "plan"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListPlansResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// FetchPlan fetches a plan by its ID or plan code.
+func (p *paystackClient) FetchPlan(idOrCode string) (*PlanResponse, error) {
+	return p.FetchPlanWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) FetchPlanWithContext(ctx context.Context, idOrCode string) (*PlanResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("plan id or code is required")
+	}
+
+	var response PlanResponse
+	if err := p.doJSON(ctx, "GET", "plan/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) UpdatePlan(idOrCode string, data *UpdatePlanRequest) (*MessageResponse, error) {
+	return p.UpdatePlanWithContext(context.Background(), idOrCode, data)
+}
+
+func (p *paystackClient) UpdatePlanWithContext(ctx context.Context, idOrCode string, data *UpdatePlanRequest) (*MessageResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("plan id or code is required")
+	}
+	if data.Interval != "" && !planIntervals[data.Interval] {
+		return nil, fmt.Errorf("invalid plan interval %q", data.Interval)
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "PUT", "plan/"+url.PathEscape(idOrCode), data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) CreateSubscription(data *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error) {
+	return p.CreateSubscriptionWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) CreateSubscriptionWithContext(ctx context.Context, data *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error) {
+	if data.Customer == "" || data.Plan == "" {
+		return nil, fmt.Errorf("customer and plan are required")
+	}
+
+	var response CreateSubscriptionResponse
+	if err := p.doJSON(ctx, "POST", "subscription", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListSubscriptions(filter *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error) {
+	return p.ListSubscriptionsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListSubscriptionsWithContext(ctx context.Context, filter *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error) {
+	endpoint := "subscription"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListSubscriptionsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// FetchSubscription fetches a subscription by its ID or subscription code.
+func (p *paystackClient) FetchSubscription(idOrCode string) (*SubscriptionResponse, error) {
+	return p.FetchSubscriptionWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) FetchSubscriptionWithContext(ctx context.Context, idOrCode string) (*SubscriptionResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("subscription id or code is required")
+	}
+
+	var response SubscriptionResponse
+	if err := p.doJSON(ctx, "GET", "subscription/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// EnableSubscription re-enables a subscription using its code and email token.
+func (p *paystackClient) EnableSubscription(code, token string) (*MessageResponse, error) {
+	return p.EnableSubscriptionWithContext(context.Background(), code, token)
+}
+
+func (p *paystackClient) EnableSubscriptionWithContext(ctx context.Context, code, token string) (*MessageResponse, error) {
+	return p.toggleSubscription(ctx, "subscription/enable", code, token)
+}
+
+// DisableSubscription stops a subscription using its code and email token.
+func (p *paystackClient) DisableSubscription(code, token string) (*MessageResponse, error) {
+	return p.DisableSubscriptionWithContext(context.Background(), code, token)
+}
+
+func (p *paystackClient) DisableSubscriptionWithContext(ctx context.Context, code, token string) (*MessageResponse, error) {
+	return p.toggleSubscription(ctx, "subscription/disable", code, token)
+}
+
+func (p *paystackClient) toggleSubscription(ctx context.Context, endpoint, code, token string) (*MessageResponse, error) {
+	if code == "" || token == "" {
+		return nil, fmt.Errorf("subscription code and email token are required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", endpoint, subscriptionToken{Code: code, Token: token}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
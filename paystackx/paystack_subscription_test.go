@@ -0,0 +1,84 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAndSubscriptionRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /plan":
+			var body CreatePlanRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, int64(500000), body.Amount)
+			require.Equal(t, PlanIntervalMonthly, body.Interval)
+			w.Write([]byte(`{"status":true,"message":"Plan created","data":{"name":"Premium","amount":500000,"interval":"monthly","integration":100032,"domain":"test","currency":"NGN","plan_code":"PLN_gx2wn530m0i3w3m","invoice_limit":0,"send_invoices":true,"send_sms":true,"hosted_page":false,"id":28,"createdAt":"2016-03-29T22:42:50.811Z","updatedAt":"2016-03-29T22:42:50.811Z"}}`))
+		case "GET /plan":
+			require.Equal(t, "monthly", r.URL.Query().Get("interval"))
+			w.Write([]byte(`{"status":true,"message":"Plans retrieved","data":[{"id":28,"name":"Premium","plan_code":"PLN_gx2wn530m0i3w3m","amount":500000,"interval":"monthly","currency":"NGN"}],"meta":{"total":1,"skipped":0,"perPage":50,"page":1,"pageCount":1}}`))
+		case "PUT /plan/PLN_gx2wn530m0i3w3m":
+			w.Write([]byte(`{"status":true,"message":"Plan updated. 1 subscription(s) affected"}`))
+		case "POST /subscription":
+			w.Write([]byte(`{"status":true,"message":"Subscription successfully created","data":{"customer":1173,"plan":28,"integration":100032,"domain":"test","start":1459296064,"status":"active","quantity":1,"amount":500000,"authorization":{"authorization_code":"AUTH_6tmt288t0o","reusable":true},"subscription_code":"SUB_vsyqdmlzble3uii","email_token":"d7gofp6yppn3qz7","id":9,"createdAt":"2016-03-30T00:01:04.687Z"}}`))
+		case "GET /subscription/SUB_vsyqdmlzble3uii":
+			w.Write([]byte(`{"status":true,"message":"Subscription retrieved successfully","data":{"id":9,"status":"active","subscription_code":"SUB_vsyqdmlzble3uii","email_token":"d7gofp6yppn3qz7","amount":500000,"cron_expression":"0 0 28 * *","next_payment_date":"2016-04-28T07:00:00.000Z","plan":{"id":28,"plan_code":"PLN_gx2wn530m0i3w3m","amount":500000,"interval":"monthly","currency":"NGN"},"customer":{"id":1173,"email":"ada@example.com","customer_code":"CUS_xnxdt6s1zg1f4nx"}}}`))
+		case "POST /subscription/disable":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]string{"code": "SUB_vsyqdmlzble3uii", "token": "d7gofp6yppn3qz7"}, body)
+			w.Write([]byte(`{"status":true,"message":"Subscription disabled successfully"}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	plan, err := client.CreatePlan(&CreatePlanRequest{Name: "Premium", Amount: 500000, Interval: PlanIntervalMonthly})
+	require.NoError(t, err)
+	require.Equal(t, "PLN_gx2wn530m0i3w3m", plan.Data.PlanCode)
+	require.Equal(t, "5000.00 NGN", plan.Data.Money().String())
+
+	plans, err := client.ListPlans(&ListPlansRequest{Interval: PlanIntervalMonthly})
+	require.NoError(t, err)
+	require.Len(t, plans.Data, 1)
+
+	updated, err := client.UpdatePlan(plan.Data.PlanCode, &UpdatePlanRequest{Amount: 600000})
+	require.NoError(t, err)
+	require.Contains(t, updated.Message, "1 subscription(s)")
+
+	created, err := client.CreateSubscription(&CreateSubscriptionRequest{Customer: "ada@example.com", Plan: plan.Data.PlanCode})
+	require.NoError(t, err)
+	require.Equal(t, 28, created.Data.Plan)
+
+	subscription, err := client.FetchSubscription(created.Data.SubscriptionCode)
+	require.NoError(t, err)
+	require.Equal(t, SubscriptionStatusActive, subscription.Data.Status)
+	require.Equal(t, "CUS_xnxdt6s1zg1f4nx", subscription.Data.Customer.CustomerCode)
+	require.Equal(t, "5000.00 NGN", subscription.Data.Money().String())
+
+	_, err = client.DisableSubscription(subscription.Data.SubscriptionCode, subscription.Data.EmailToken)
+	require.NoError(t, err)
+}
+
+func TestPlanAndSubscriptionValidation(t *testing.T) {
+	client := NewPaystackClient("http://127.0.0.1:0", testSecretKey)
+
+	_, err := client.CreatePlan(&CreatePlanRequest{Name: "Premium", Amount: 500000, Interval: "fortnightly"})
+	require.EqualError(t, err, `invalid plan interval "fortnightly"`)
+
+	_, err = client.CreatePlan(&CreatePlanRequest{Name: "Premium", Interval: PlanIntervalMonthly})
+	require.Error(t, err)
+
+	_, err = client.CreateSubscription(&CreateSubscriptionRequest{Customer: "ada@example.com"})
+	require.Error(t, err)
+
+	_, err = client.EnableSubscription("SUB_vsyqdmlzble3uii", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package paystackx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaystackErrorFromResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/customer":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":false,"message":"Invalid email address passed","meta":{"nextStep":"Provide a valid email address"},"type":"validation_error","code":"invalid_params"}`))
+		case "/balance":
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html>bad gateway</html>`))
+		case "/bank":
+			w.Write([]byte(`{"status":false,"message":"Invalid key"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey, WithRetryPolicy(NoRetry()))
+
+	_, err := client.CreateUser(PaystackCreateUserRequest{Email: "not-an-email"})
+	paystackErr, ok := AsPaystackError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusBadRequest, paystackErr.StatusCode)
+	require.Equal(t, "invalid_params", paystackErr.Code)
+	require.Equal(t, "validation_error", paystackErr.Type)
+	require.Equal(t, "Invalid email address passed", paystackErr.Message)
+	require.False(t, paystackErr.Temporary())
+
+	_, err = client.FetchBalance()
+	paystackErr, ok = AsPaystackError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusBadGateway, paystackErr.StatusCode)
+	require.Equal(t, "Bad Gateway", paystackErr.Message)
+	require.True(t, paystackErr.Temporary())
+
+	_, err = client.FetchBanks()
+	paystackErr, ok = AsPaystackError(err)
+	require.True(t, ok)
+	require.Equal(t, http.StatusOK, paystackErr.StatusCode)
+	require.Equal(t, "Invalid key", paystackErr.Message)
+}
+
+func TestPaystackContextAndTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := NewPaystackClient(server.URL, testSecretKey).FetchBalanceWithContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = NewPaystackClient(server.URL, testSecretKey, WithTimeout(20*time.Millisecond), WithRetryPolicy(NoRetry())).FetchBalance()
+	require.Error(t, err)
+	_, ok := AsPaystackError(err)
+	require.False(t, ok)
+}
+
+func TestWithTimeoutLeavesSharedClientAlone(t *testing.T) {
+	shared := &http.Client{Timeout: time.Minute}
+	for _, opts := range [][]ClientOption{
+		{WithHTTPClient(shared), WithTimeout(time.Second)},
+		{WithTimeout(time.Second), WithHTTPClient(shared)},
+	} {
+		client := NewPaystackClient("http://127.0.0.1:0", testSecretKey, opts...).(*paystackClient)
+		require.Equal(t, time.Second, client.client.Timeout)
+		require.NotSame(t, shared, client.client)
+	}
+	require.Equal(t, time.Minute, shared.Timeout)
+}
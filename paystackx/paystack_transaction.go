@@ -0,0 +1,271 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// Paystack transaction (charge) statuses.
+const (
+	ChargeStatusSuccess    = "success"
+	ChargeStatusFailed     = "failed"
+	ChargeStatusAbandoned  = "abandoned"
+	ChargeStatusOngoing    = "ongoing"
+	ChargeStatusPending    = "pending"
+	ChargeStatusProcessing = "processing"
+	ChargeStatusQueued     = "queued"
+	ChargeStatusReversed   = "reversed"
+)
+
+var chargeStatuses = map[string]interfacesx.TransactionStatus{
+	ChargeStatusSuccess:    interfacesx.Completed,
+	ChargeStatusFailed:     interfacesx.Failed,
+	ChargeStatusAbandoned:  interfacesx.Canceled,
+	ChargeStatusOngoing:    interfacesx.Pending,
+	ChargeStatusPending:    interfacesx.Pending,
+	ChargeStatusQueued:     interfacesx.Pending,
+	ChargeStatusProcessing: interfacesx.Processing,
+	ChargeStatusReversed:   interfacesx.Reversed,
+}
+
+// MapChargeStatus converts a Paystack transaction status to a
+// TransactionStatus. Unknown statuses map to Processing so they are never
+// treated as final.
+func MapChargeStatus(status string) interfacesx.TransactionStatus {
+	if mapped, ok := chargeStatuses[strings.ToLower(status)]; ok {
+		return mapped
+	}
+	return interfacesx.Processing
+}
+
+// TransactionStatus maps the charge status onto interfacesx.TransactionStatus.
+func (d *PaystackEventData) TransactionStatus() interfacesx.TransactionStatus {
+	return MapChargeStatus(d.Status)
+}
+
+// InitializeTransactionRequest starts a checkout. Amount is in the minor unit
+// of the currency (kobo for NGN).
+type InitializeTransactionRequest struct {
+	Email             string          `json:"email"`
+	Amount            int64           `json:"amount"`
+	Currency          string          `json:"currency,omitempty"`
+	Reference         string          `json:"reference,omitempty"`
+	CallbackURL       string          `json:"callback_url,omitempty"`
+	Plan              string          `json:"plan,omitempty"`
+	Channels          []string        `json:"channels,omitempty"`
+	Subaccount        string          `json:"subaccount,omitempty"`
+	SplitCode         string          `json:"split_code,omitempty"`
+	TransactionCharge int64           `json:"transaction_charge,omitempty"`
+	Bearer            string          `json:"bearer,omitempty"`
+	Metadata          json.RawMessage `json:"metadata,omitempty"`
+}
+
+type InitializeTransactionResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		AuthorizationURL string `json:"authorization_url"`
+		AccessCode       string `json:"access_code"`
+		Reference        string `json:"reference"`
+	} `json:"data"`
+}
+
+// ChargeAuthorizationRequest charges a reusable authorization from an earlier
+// payment. Amount is in the minor unit of the currency.
+type ChargeAuthorizationRequest struct {
+	Email             string          `json:"email"`
+	Amount            int64           `json:"amount"`
+	AuthorizationCode string          `json:"authorization_code"`
+	Currency          string          `json:"currency,omitempty"`
+	Reference         string          `json:"reference,omitempty"`
+	Queue             bool            `json:"queue,omitempty"`
+	Subaccount        string          `json:"subaccount,omitempty"`
+	SplitCode         string          `json:"split_code,omitempty"`
+	TransactionCharge int64           `json:"transaction_charge,omitempty"`
+	Bearer            string          `json:"bearer,omitempty"`
+	Metadata          json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ChargeRequest builds a ChargeAuthorizationRequest that reuses this authorization.
+func (a PaystackEventAuthorization) ChargeRequest(email string, amount moneyx.Money, reference string) *ChargeAuthorizationRequest {
+	return &ChargeAuthorizationRequest{
+		Email:             email,
+		Amount:            amount.Minor(),
+		Currency:          amount.Currency(),
+		AuthorizationCode: a.AuthorizationCode,
+		Reference:         reference,
+	}
+}
+
+// TransactionResponse is returned by verify, fetch and charge authorization.
+// The transaction has the same shape as the data of a charge.success event.
+type TransactionResponse struct {
+	Status  bool              `json:"status"`
+	Message string            `json:"message"`
+	Data    PaystackEventData `json:"data"`
+}
+
+// ListTransactionsRequest filters ListTransactions. Zero values are omitted.
+type ListTransactionsRequest struct {
+	PerPage  int
+	Page     int
+	Customer int
+	Status   string
+	Amount   int64
+	From     time.Time
+	To       time.Time
+}
+
+func (r *ListTransactionsRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Customer > 0 {
+		query.Set("customer", strconv.Itoa(r.Customer))
+	}
+	if r.Status != "" {
+		query.Set("status", r.Status)
+	}
+	if r.Amount > 0 {
+		query.Set("amount", strconv.FormatInt(r.Amount, 10))
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListTransactionsResponse struct {
+	Status  bool                `json:"status"`
+	Message string              `json:"message"`
+	Data    []PaystackEventData `json:"data"`
+	Meta    Meta                `json:"meta"`
+}
+
+func (p *paystackClient) InitializeTransaction(data *InitializeTransactionRequest) (*InitializeTransactionResponse, error) {
+	return p.InitializeTransactionWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) InitializeTransactionWithContext(ctx context.Context, data *InitializeTransactionRequest) (*InitializeTransactionResponse, error) {
+	if data.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if data.Subaccount != "" && data.SplitCode != "" {
+		return nil, fmt.Errorf("set either a subaccount or a split code, not both")
+	}
+
+	var response InitializeTransactionResponse
+	if err := p.doJSON(ctx, "POST", "transaction/initialize", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) VerifyTransaction(reference string) (*TransactionResponse, error) {
+	return p.VerifyTransactionWithContext(context.Background(), reference)
+}
+
+func (p *paystackClient) VerifyTransactionWithContext(ctx context.Context, reference string) (*TransactionResponse, error) {
+	if reference == "" {
+		return nil, fmt.Errorf("reference is required")
+	}
+
+	var response TransactionResponse
+	if err := p.doJSON(ctx, "GET", "transaction/verify/"+url.PathEscape(reference), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ChargeAuthorization(data *ChargeAuthorizationRequest) (*TransactionResponse, error) {
+	return p.ChargeAuthorizationWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) ChargeAuthorizationWithContext(ctx context.Context, data *ChargeAuthorizationRequest) (*TransactionResponse, error) {
+	if data.AuthorizationCode == "" {
+		return nil, fmt.Errorf("authorization code is required")
+	}
+	if data.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	var response TransactionResponse
+	charge := func() error {
+		return p.doJSONOnce(ctx, "POST", "transaction/charge_authorization", data, &response)
+	}
+	if data.Reference == "" {
+		if err := charge(); err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+
+	// With a reference the charge can be verified, so it is safe to retry.
+	err := p.withVerifiedRetry(ctx, charge, func() (bool, error) {
+		verified, err := p.VerifyTransactionWithContext(ctx, data.Reference)
+		if isNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		response = *verified
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListTransactions(filter *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return p.ListTransactionsWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListTransactionsWithContext(ctx context.Context, filter *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	endpoint := "transaction"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListTransactionsResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FetchTransaction(id int) (*TransactionResponse, error) {
+	return p.FetchTransactionWithContext(context.Background(), id)
+}
+
+func (p *paystackClient) FetchTransactionWithContext(ctx context.Context, id int) (*TransactionResponse, error) {
+	var response TransactionResponse
+	if err := p.doJSON(ctx, "GET", fmt.Sprintf("transaction/%d", id), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
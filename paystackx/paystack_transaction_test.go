@@ -0,0 +1,90 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionLifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer "+testSecretKey, r.Header.Get("Authorization"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transaction/initialize":
+			var body InitializeTransactionRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, int64(250000), body.Amount)
+			w.Write([]byte(`{"status":true,"message":"Authorization URL created","data":{"authorization_url":"https://checkout.paystack.com/0peioxfhpn","access_code":"0peioxfhpn","reference":"dep-1"}}`))
+		case "GET /transaction/verify/dep-1":
+			w.Write([]byte(`{"status":true,"message":"Verification successful","data":{"id":4099260516,"status":"success","reference":"dep-1","amount":250000,"currency":"NGN","channel":"card","fees":3750,"customer":{"id":181873746,"email":"demo@test.com","customer_code":"CUS_1rkzaqsv4rrhqo6"},"authorization":{"authorization_code":"AUTH_uh8bcl3zbn","reusable":true,"channel":"card"}}}`))
+		case "POST /transaction/charge_authorization":
+			var body ChargeAuthorizationRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "AUTH_uh8bcl3zbn", body.AuthorizationCode)
+			require.Equal(t, int64(250000), body.Amount)
+			require.Equal(t, "NGN", body.Currency)
+			w.Write([]byte(`{"status":true,"message":"Charge attempted","data":{"id":4099490251,"status":"success","reference":"dep-2","amount":100000,"currency":"NGN"}}`))
+		case "GET /transaction":
+			require.Equal(t, "2", r.URL.Query().Get("page"))
+			require.Equal(t, "success", r.URL.Query().Get("status"))
+			w.Write([]byte(`{"status":true,"message":"Transactions retrieved","data":[{"id":4099490251,"reference":"dep-2","amount":100000}],"meta":{"total":3,"skipped":2,"perPage":2,"page":2,"pageCount":2}}`))
+		case "GET /transaction/404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":false,"message":"Transaction not found"}`))
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	initialized, err := client.InitializeTransaction(&InitializeTransactionRequest{Email: "demo@test.com", Amount: 250000, Reference: "dep-1"})
+	require.NoError(t, err)
+	require.Equal(t, "0peioxfhpn", initialized.Data.AccessCode)
+
+	verified, err := client.VerifyTransaction("dep-1")
+	require.NoError(t, err)
+	require.Equal(t, int64(250000), verified.Data.Amount)
+	require.True(t, verified.Data.Authorization.Reusable)
+
+	charged, err := client.ChargeAuthorization(verified.Data.Authorization.ChargeRequest("demo@test.com", verified.Data.Money(), "dep-2"))
+	require.NoError(t, err)
+	require.Equal(t, "dep-2", charged.Data.Reference)
+
+	listed, err := client.ListTransactions(&ListTransactionsRequest{Page: 2, PerPage: 2, Status: "success"})
+	require.NoError(t, err)
+	require.Len(t, listed.Data, 1)
+	require.Equal(t, 3, listed.Meta.Total)
+	require.False(t, listed.Meta.HasNextPage())
+
+	_, err = client.FetchTransaction(404)
+	require.EqualError(t, err, "paystack GET transaction/404 failed: Transaction not found")
+}
+
+func TestTransactionValidation(t *testing.T) {
+	client := NewPaystackClient("http://127.0.0.1:0", testSecretKey)
+
+	_, err := client.InitializeTransaction(&InitializeTransactionRequest{Email: "demo@test.com"})
+	require.Error(t, err)
+
+	_, err = client.ChargeAuthorization(PaystackEventAuthorization{}.ChargeRequest("demo@test.com", moneyx.New(100, "NGN"), ""))
+	require.Error(t, err)
+
+	_, err = client.VerifyTransaction("")
+	require.Error(t, err)
+}
+
+func TestMapChargeStatus(t *testing.T) {
+	require.Equal(t, interfacesx.Completed, MapChargeStatus("success"))
+	require.Equal(t, interfacesx.Failed, MapChargeStatus("FAILED"))
+	require.Equal(t, interfacesx.Canceled, MapChargeStatus("abandoned"))
+	require.Equal(t, interfacesx.Pending, MapChargeStatus("ongoing"))
+	require.Equal(t, interfacesx.Processing, MapChargeStatus("something-new"))
+}
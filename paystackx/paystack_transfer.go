@@ -0,0 +1,373 @@
+package paystackx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+)
+
+// Paystack transfer statuses.
+const (
+	TransferStatusOTP        = "otp"
+	TransferStatusPending    = "pending"
+	TransferStatusQueued     = "queued"
+	TransferStatusReceived   = "received"
+	TransferStatusProcessing = "processing"
+	TransferStatusSuccess    = "success"
+	TransferStatusFailed     = "failed"
+	TransferStatusRejected   = "rejected"
+	TransferStatusAbandoned  = "abandoned"
+	TransferStatusBlocked    = "blocked"
+	TransferStatusReversed   = "reversed"
+)
+
+var transferStatuses = map[string]interfacesx.TransactionStatus{
+	TransferStatusOTP:        interfacesx.Pending,
+	TransferStatusPending:    interfacesx.Pending,
+	TransferStatusQueued:     interfacesx.Pending,
+	TransferStatusReceived:   interfacesx.Processing,
+	TransferStatusProcessing: interfacesx.Processing,
+	TransferStatusSuccess:    interfacesx.Completed,
+	TransferStatusFailed:     interfacesx.Failed,
+	TransferStatusRejected:   interfacesx.Failed,
+	TransferStatusAbandoned:  interfacesx.Canceled,
+	TransferStatusBlocked:    interfacesx.Hold,
+	TransferStatusReversed:   interfacesx.Reversed,
+}
+
+// MapTransferStatus converts a Paystack transfer status to a TransactionStatus.
+// Unknown statuses map to Processing so they are never treated as final.
+func MapTransferStatus(status string) interfacesx.TransactionStatus {
+	if mapped, ok := transferStatuses[strings.ToLower(status)]; ok {
+		return mapped
+	}
+	return interfacesx.Processing
+}
+
+// TransactionStatus maps the transfer status onto interfacesx.TransactionStatus.
+func (d *TransferEventData) TransactionStatus() interfacesx.TransactionStatus {
+	return MapTransferStatus(d.Status)
+}
+
+type FinalizeTransferRequest struct {
+	TransferCode string `json:"transfer_code"`
+	OTP          string `json:"otp"`
+}
+
+// ResendTransferOTPRequest asks Paystack to resend the OTP for a transfer.
+// Reason is "resend_otp" or "transfer"; it defaults to "resend_otp".
+type ResendTransferOTPRequest struct {
+	TransferCode string `json:"transfer_code"`
+	Reason       string `json:"reason"`
+}
+
+// MessageResponse is returned by endpoints whose only payload is a message.
+type MessageResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+}
+
+// Transfer is a transfer as the fetch, verify and list endpoints return it.
+// It differs from TransferEventData, the webhook shape: integration is an ID
+// and the timestamps are camelCase.
+type Transfer struct {
+	Amount        int64             `json:"amount"`
+	Currency      string            `json:"currency"`
+	Domain        string            `json:"domain"`
+	Failures      json.RawMessage   `json:"failures"`
+	ID            int               `json:"id"`
+	Integration   int               `json:"integration"`
+	Reason        string            `json:"reason"`
+	Reference     string            `json:"reference"`
+	Source        string            `json:"source"`
+	Status        string            `json:"status"`
+	TransferCode  string            `json:"transfer_code"`
+	TransferredAt *string           `json:"transferred_at"`
+	FeeCharged    int64             `json:"fee_charged"`
+	Recipient     TransferRecipient `json:"recipient"`
+	Session       TransferSession   `json:"session"`
+	CreatedAt     string            `json:"createdAt"`
+	UpdatedAt     string            `json:"updatedAt"`
+}
+
+// TransactionStatus maps the transfer status onto interfacesx.TransactionStatus.
+func (t *Transfer) TransactionStatus() interfacesx.TransactionStatus {
+	return MapTransferStatus(t.Status)
+}
+
+// TransferResponse is returned by verify and fetch.
+type TransferResponse struct {
+	Status  bool     `json:"status"`
+	Message string   `json:"message"`
+	Data    Transfer `json:"data"`
+}
+
+// otpResponse converts a verified transfer into the response InitiateTransfer returns.
+func (r *TransferResponse) otpResponse() TransferOTPResponse {
+	var response TransferOTPResponse
+	response.Status = r.Status
+	response.Message = r.Message
+	response.Data.Integration = r.Data.Integration
+	response.Data.Domain = r.Data.Domain
+	response.Data.Amount = r.Data.Amount
+	response.Data.Currency = r.Data.Currency
+	response.Data.Source = r.Data.Source
+	response.Data.Reason = r.Data.Reason
+	response.Data.Recipient = r.Data.Recipient.ID
+	response.Data.Status = r.Data.Status
+	response.Data.TransferCode = r.Data.TransferCode
+	response.Data.ID = r.Data.ID
+	response.Data.CreatedAt = r.Data.CreatedAt
+	response.Data.UpdatedAt = r.Data.UpdatedAt
+	return response
+}
+
+// ListTransfersRequest filters ListTransfers. Zero values are omitted.
+type ListTransfersRequest struct {
+	PerPage  int
+	Page     int
+	Customer int
+	Status   string
+	From     time.Time
+	To       time.Time
+}
+
+func (r *ListTransfersRequest) query() url.Values {
+	query := url.Values{}
+	if r == nil {
+		return query
+	}
+	if r.PerPage > 0 {
+		query.Set("perPage", strconv.Itoa(r.PerPage))
+	}
+	if r.Page > 0 {
+		query.Set("page", strconv.Itoa(r.Page))
+	}
+	if r.Customer > 0 {
+		query.Set("customer", strconv.Itoa(r.Customer))
+	}
+	if r.Status != "" {
+		query.Set("status", r.Status)
+	}
+	if !r.From.IsZero() {
+		query.Set("from", r.From.UTC().Format(time.RFC3339))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+type ListTransfersResponse struct {
+	Status  bool       `json:"status"`
+	Message string     `json:"message"`
+	Data    []Transfer `json:"data"`
+	Meta    Meta       `json:"meta"`
+}
+
+// BulkTransferItem is a single transfer in a bulk request. Amount is in the
+// minor unit of the currency.
+type BulkTransferItem struct {
+	Amount    int64  `json:"amount"`
+	Recipient string `json:"recipient"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type BulkTransferRequest struct {
+	Currency  string             `json:"currency,omitempty"`
+	Source    string             `json:"source"`
+	Transfers []BulkTransferItem `json:"transfers"`
+}
+
+type BulkTransferResult struct {
+	Reference    string `json:"reference"`
+	Recipient    string `json:"recipient"`
+	Amount       int64  `json:"amount"`
+	TransferCode string `json:"transfer_code"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+}
+
+// TransactionStatus maps the transfer status onto interfacesx.TransactionStatus.
+func (r *BulkTransferResult) TransactionStatus() interfacesx.TransactionStatus {
+	return MapTransferStatus(r.Status)
+}
+
+type BulkTransferResponse struct {
+	Status  bool                 `json:"status"`
+	Message string               `json:"message"`
+	Data    []BulkTransferResult `json:"data"`
+}
+
+func (p *paystackClient) FinalizeTransfer(data *FinalizeTransferRequest) (*TransferOTPResponse, error) {
+	return p.FinalizeTransferWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) FinalizeTransferWithContext(ctx context.Context, data *FinalizeTransferRequest) (*TransferOTPResponse, error) {
+	if data.TransferCode == "" || data.OTP == "" {
+		return nil, fmt.Errorf("transfer code and otp are required")
+	}
+
+	var response TransferOTPResponse
+	if err := p.doJSON(ctx, "POST", "transfer/finalize_transfer", data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ResendTransferOTP(data *ResendTransferOTPRequest) (*MessageResponse, error) {
+	return p.ResendTransferOTPWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) ResendTransferOTPWithContext(ctx context.Context, data *ResendTransferOTPRequest) (*MessageResponse, error) {
+	if data.TransferCode == "" {
+		return nil, fmt.Errorf("transfer code is required")
+	}
+	// Defaults go on a copy so the caller's request is left as it was.
+	request := *data
+	if request.Reason == "" {
+		request.Reason = "resend_otp"
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "transfer/resend_otp", &request, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DisableTransferOTP requests an OTP to turn off OTP for transfers. Submit the
+// OTP sent to the business phone with FinalizeDisableTransferOTP.
+func (p *paystackClient) DisableTransferOTP() (*MessageResponse, error) {
+	return p.DisableTransferOTPWithContext(context.Background())
+}
+
+func (p *paystackClient) DisableTransferOTPWithContext(ctx context.Context) (*MessageResponse, error) {
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "transfer/disable_otp", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) FinalizeDisableTransferOTP(otp string) (*MessageResponse, error) {
+	return p.FinalizeDisableTransferOTPWithContext(context.Background(), otp)
+}
+
+func (p *paystackClient) FinalizeDisableTransferOTPWithContext(ctx context.Context, otp string) (*MessageResponse, error) {
+	if otp == "" {
+		return nil, fmt.Errorf("otp is required")
+	}
+
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "transfer/disable_otp_finalize", map[string]string{"otp": otp}, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) EnableTransferOTP() (*MessageResponse, error) {
+	return p.EnableTransferOTPWithContext(context.Background())
+}
+
+func (p *paystackClient) EnableTransferOTPWithContext(ctx context.Context) (*MessageResponse, error) {
+	var response MessageResponse
+	if err := p.doJSON(ctx, "POST", "transfer/enable_otp", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) VerifyTransfer(reference string) (*TransferResponse, error) {
+	return p.VerifyTransferWithContext(context.Background(), reference)
+}
+
+func (p *paystackClient) VerifyTransferWithContext(ctx context.Context, reference string) (*TransferResponse, error) {
+	if reference == "" {
+		return nil, fmt.Errorf("reference is required")
+	}
+
+	var response TransferResponse
+	if err := p.doJSON(ctx, "GET", "transfer/verify/"+url.PathEscape(reference), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// FetchTransfer fetches a transfer by its ID or transfer code.
+func (p *paystackClient) FetchTransfer(idOrCode string) (*TransferResponse, error) {
+	return p.FetchTransferWithContext(context.Background(), idOrCode)
+}
+
+func (p *paystackClient) FetchTransferWithContext(ctx context.Context, idOrCode string) (*TransferResponse, error) {
+	if idOrCode == "" {
+		return nil, fmt.Errorf("transfer id or code is required")
+	}
+
+	var response TransferResponse
+	if err := p.doJSON(ctx, "GET", "transfer/"+url.PathEscape(idOrCode), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (p *paystackClient) ListTransfers(filter *ListTransfersRequest) (*ListTransfersResponse, error) {
+	return p.ListTransfersWithContext(context.Background(), filter)
+}
+
+func (p *paystackClient) ListTransfersWithContext(ctx context.Context, filter *ListTransfersRequest) (*ListTransfersResponse, error) {
+	endpoint := "transfer"
+	if query := filter.query(); len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var response ListTransfersResponse
+	if err := p.doJSON(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// InitiateBulkTransfer queues several transfers at once. OTP must be disabled
+// on the integration. Every transfer needs a reference so it can be verified
+// individually later.
+func (p *paystackClient) InitiateBulkTransfer(data *BulkTransferRequest) (*BulkTransferResponse, error) {
+	return p.InitiateBulkTransferWithContext(context.Background(), data)
+}
+
+func (p *paystackClient) InitiateBulkTransferWithContext(ctx context.Context, data *BulkTransferRequest) (*BulkTransferResponse, error) {
+	if len(data.Transfers) == 0 {
+		return nil, fmt.Errorf("at least one transfer is required")
+	}
+	for i, transfer := range data.Transfers {
+		if transfer.Amount <= 0 || transfer.Recipient == "" || transfer.Reference == "" {
+			return nil, fmt.Errorf("transfer %d needs a positive amount, a recipient and a reference", i)
+		}
+	}
+	request := *data
+	if request.Source == "" {
+		request.Source = "balance"
+	}
+
+	var response BulkTransferResponse
+	if err := p.doJSON(ctx, "POST", "transfer/bulk", &request, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
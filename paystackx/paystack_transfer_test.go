@@ -0,0 +1,138 @@
+package paystackx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferLifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /transfer/finalize_transfer":
+			var body FinalizeTransferRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "928783", body.OTP)
+			w.Write([]byte(`{"status":true,"message":"Transfer has been queued","data":{"domain":"test","amount":1000000,"currency":"NGN","reference":"wd-7","source":"balance","reason":"Withdrawal","status":"success","transfer_code":"TRF_vsyqdmlzble3uii","id":476948,"recipient":9913}}`))
+		case "POST /transfer/resend_otp":
+			var body ResendTransferOTPRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "resend_otp", body.Reason)
+			w.Write([]byte(`{"status":true,"message":"OTP has been resent"}`))
+		case "GET /transfer/verify/wd-7", "GET /transfer/TRF_vsyqdmlzble3uii":
+			w.Write([]byte(`{"status":true,"message":"Transfer retrieved","data":{"amount":1000000,"currency":"NGN","reference":"wd-7","status":"reversed","transfer_code":"TRF_vsyqdmlzble3uii","recipient":{"recipient_code":"RCP_2x5j67tnnw1t98k","details":{"account_number":"0000000000","bank_code":"058"}}}}`))
+		case "GET /transfer":
+			require.Equal(t, "1", r.URL.Query().Get("page"))
+			w.Write([]byte(`{"status":true,"message":"Transfers retrieved","data":[{"reference":"wd-7","status":"pending"},{"reference":"wd-8","status":"otp"}],"meta":{"total":4,"perPage":2,"page":1,"pageCount":2}}`))
+		case "POST /transfer/bulk":
+			var body BulkTransferRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "balance", body.Source)
+			require.Len(t, body.Transfers, 2)
+			w.Write([]byte(`{"status":true,"message":"2 transfers queued.","data":[{"reference":"bulk-1","recipient":"RCP_db342dvqvz9qcrn","amount":50000,"transfer_code":"TRF_jblrgs0xk4m7ztm","currency":"NGN","status":"received"},{"reference":"bulk-2","recipient":"RCP_db342dvqvz9qcrn","amount":50000,"transfer_code":"TRF_yk7sm6ezgqaxuyt","currency":"NGN","status":"received"}]}`))
+		case "POST /transfer/disable_otp_finalize":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":false,"message":"Invalid OTP"}`))
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	finalized, err := client.FinalizeTransfer(&FinalizeTransferRequest{TransferCode: "TRF_vsyqdmlzble3uii", OTP: "928783"})
+	require.NoError(t, err)
+	require.Equal(t, "TRF_vsyqdmlzble3uii", finalized.Data.TransferCode)
+
+	resend := &ResendTransferOTPRequest{TransferCode: "TRF_vsyqdmlzble3uii"}
+	_, err = client.ResendTransferOTP(resend)
+	require.NoError(t, err)
+	require.Empty(t, resend.Reason)
+
+	verified, err := client.VerifyTransfer("wd-7")
+	require.NoError(t, err)
+	require.Equal(t, interfacesx.Reversed, verified.Data.TransactionStatus())
+
+	fetched, err := client.FetchTransfer("TRF_vsyqdmlzble3uii")
+	require.NoError(t, err)
+	require.Equal(t, "RCP_2x5j67tnnw1t98k", fetched.Data.Recipient.RecipientCode)
+
+	listed, err := client.ListTransfers(&ListTransfersRequest{Page: 1, PerPage: 2})
+	require.NoError(t, err)
+	require.True(t, listed.Meta.HasNextPage())
+	require.Equal(t, interfacesx.Pending, listed.Data[1].TransactionStatus())
+
+	bulkRequest := &BulkTransferRequest{Transfers: []BulkTransferItem{
+		{Amount: 50000, Recipient: "RCP_db342dvqvz9qcrn", Reference: "bulk-1"},
+		{Amount: 50000, Recipient: "RCP_db342dvqvz9qcrn", Reference: "bulk-2"},
+	}}
+	bulk, err := client.InitiateBulkTransfer(bulkRequest)
+	require.NoError(t, err)
+	require.Empty(t, bulkRequest.Source)
+	require.Len(t, bulk.Data, 2)
+	require.Equal(t, interfacesx.Processing, bulk.Data[0].TransactionStatus())
+
+	_, err = client.FinalizeDisableTransferOTP("000000")
+	require.EqualError(t, err, "paystack POST transfer/disable_otp_finalize failed: Invalid OTP")
+
+	_, err = client.InitiateBulkTransfer(&BulkTransferRequest{Transfers: []BulkTransferItem{{Amount: 50000, Recipient: "RCP_db342dvqvz9qcrn"}}})
+	require.Error(t, err)
+}
+
+func TestTransferAPIFixtures(t *testing.T) {
+	fixtures := map[string]string{
+		"/transfer/verify/acv_2627bbfe-1a2a-4a1a-8d0e-9d2ee6c31496": "transfer_verify.json",
+		"/transfer/TRF_2x5j67tnnw1t98k":                             "transfer_fetch.json",
+		"/transfer":                                                 "transfer_list.json",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := fixtures[r.URL.Path]
+		if !ok {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "api", name))
+		require.NoError(t, err)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewPaystackClient(server.URL, testSecretKey)
+
+	verified, err := client.VerifyTransfer("acv_2627bbfe-1a2a-4a1a-8d0e-9d2ee6c31496")
+	require.NoError(t, err)
+	require.Equal(t, 463433, verified.Data.Integration)
+	require.Equal(t, "2024-10-29T13:38:20.000Z", verified.Data.CreatedAt)
+	require.Equal(t, "2024-10-29T13:38:21.000Z", verified.Data.UpdatedAt)
+	require.Equal(t, int64(1000), verified.Data.FeeCharged)
+	require.Equal(t, "2023-07-31T10:14:40.000Z", verified.Data.Recipient.CreatedAt)
+	require.Equal(t, "058", verified.Data.Recipient.Details.BankCode)
+	require.Equal(t, interfacesx.Completed, verified.Data.TransactionStatus())
+
+	fetched, err := client.FetchTransfer("TRF_2x5j67tnnw1t98k")
+	require.NoError(t, err)
+	require.Equal(t, 100073, fetched.Data.Integration)
+	require.Equal(t, "2017-03-25T17:51:24.000Z", fetched.Data.CreatedAt)
+	require.Nil(t, fetched.Data.TransferredAt)
+
+	listed, err := client.ListTransfers(&ListTransfersRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.Data, 1)
+	require.Equal(t, 463433, listed.Data[0].Integration)
+	require.Equal(t, "2024-08-07T10:41:52.000Z", listed.Data[0].CreatedAt)
+	require.Equal(t, "2023-07-12T10:37:31.000Z", listed.Data[0].Recipient.UpdatedAt)
+}
+
+func TestMapTransferStatus(t *testing.T) {
+	require.Equal(t, interfacesx.Completed, MapTransferStatus("success"))
+	require.Equal(t, interfacesx.Failed, MapTransferStatus("FAILED"))
+	require.Equal(t, interfacesx.Canceled, MapTransferStatus("abandoned"))
+	require.Equal(t, interfacesx.Hold, MapTransferStatus("blocked"))
+	require.Equal(t, interfacesx.Processing, MapTransferStatus("something-new"))
+}
@@ -0,0 +1,454 @@
+package paystackx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const PaystackSignatureHeader = "x-paystack-signature"
+
+const (
+	EventChargeSuccess                 = "charge.success"
+	EventTransferSuccess               = "transfer.success"
+	EventTransferFailed                = "transfer.failed"
+	EventTransferReversed              = "transfer.reversed"
+	EventDedicatedAccountAssignSuccess = "dedicatedaccount.assign.success"
+	EventDedicatedAccountAssignFailed  = "dedicatedaccount.assign.failed"
+	EventSubscriptionCreate            = "subscription.create"
+	EventSubscriptionDisable           = "subscription.disable"
+	EventSubscriptionNotRenew          = "subscription.not_renew"
+	EventInvoiceCreate                 = "invoice.create"
+	EventInvoiceUpdate                 = "invoice.update"
+	EventInvoicePaymentFailed          = "invoice.payment_failed"
+	EventRefundPending                 = "refund.pending"
+	EventRefundProcessing              = "refund.processing"
+	EventRefundProcessed               = "refund.processed"
+	EventRefundFailed                  = "refund.failed"
+	EventChargeDisputeCreate           = "charge.dispute.create"
+	EventChargeDisputeRemind           = "charge.dispute.remind"
+	EventChargeDisputeResolve          = "charge.dispute.resolve"
+	EventCustomerIdentificationSuccess = "customeridentification.success"
+	EventCustomerIdentificationFailed  = "customeridentification.failed"
+)
+
+// PaystackWebhookIPs are the addresses Paystack sends webhooks from.
+var PaystackWebhookIPs = []string{"52.31.139.75", "52.49.173.169", "52.214.14.220"}
+
+// VerifySignature checks the x-paystack-signature header, an HMAC-SHA512 of
+// the raw request body keyed with the secret key. An empty secret key rejects
+// every signature, since anyone can compute an HMAC with it.
+func VerifySignature(secretKey string, body []byte, signature string) bool {
+	if secretKey == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha512.New, []byte(secretKey))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SignPayload returns the x-paystack-signature value for body.
+func SignPayload(secretKey string, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secretKey))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookEvent is a verified webhook delivery. Data holds the raw event data
+// so it can be decoded into the struct matching Event.
+type WebhookEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Charge decodes the data of a charge event.
+func (e *WebhookEvent) Charge() (*PaystackEventData, error) {
+	var data PaystackEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Transfer decodes the data of a transfer event.
+func (e *WebhookEvent) Transfer() (*TransferEventData, error) {
+	var data TransferEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// DedicatedAccount decodes the data of a dedicated account assignment event.
+func (e *WebhookEvent) DedicatedAccount() (*DedicatedAccountEventData, error) {
+	var data DedicatedAccountEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Subscription decodes the data of a subscription event.
+func (e *WebhookEvent) Subscription() (*Subscription, error) {
+	var data Subscription
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Invoice decodes the data of an invoice event.
+func (e *WebhookEvent) Invoice() (*InvoiceEventData, error) {
+	var data InvoiceEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Refund decodes the data of a refund event.
+func (e *WebhookEvent) Refund() (*RefundEventData, error) {
+	var data RefundEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Dispute decodes the data of a charge.dispute event.
+func (e *WebhookEvent) Dispute() (*Dispute, error) {
+	var data Dispute
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// CustomerIdentification decodes the data of a customeridentification event.
+func (e *WebhookEvent) CustomerIdentification() (*CustomerIdentificationEventData, error) {
+	var data CustomerIdentificationEventData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// WebhookCallback handles a single webhook event. Returning an error responds
+// with a 500 so Paystack retries the delivery.
+type WebhookCallback func(c *gin.Context, event *WebhookEvent) error
+
+// WebhookDeduplicator remembers webhook deliveries that have already been processed.
+type WebhookDeduplicator interface {
+	// Seen reports whether key was already recorded and records it otherwise.
+	Seen(key string) bool
+	// Forget removes key so the delivery can be processed again.
+	Forget(key string)
+}
+
+// MemoryDeduplicator is an in-memory WebhookDeduplicator that forgets keys after a TTL.
+type MemoryDeduplicator struct {
+	ttl  time.Duration
+	now  func() time.Time
+	keys map[string]time.Time
+	// queue holds keys in the order they expire. Every key shares the TTL,
+	// so that is the order they were first seen.
+	queue []dedupEntry
+	mu    sync.Mutex
+}
+
+type dedupEntry struct {
+	key    string
+	expiry time.Time
+}
+
+// NewMemoryDeduplicator creates a MemoryDeduplicator that remembers keys for ttl.
+func NewMemoryDeduplicator(ttl time.Duration) *MemoryDeduplicator {
+	return &MemoryDeduplicator{
+		ttl:  ttl,
+		now:  time.Now,
+		keys: make(map[string]time.Time),
+	}
+}
+
+func (d *MemoryDeduplicator) Seen(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.expire(now)
+
+	if _, ok := d.keys[key]; ok {
+		return true
+	}
+	expiry := now.Add(d.ttl)
+	d.keys[key] = expiry
+	d.queue = append(d.queue, dedupEntry{key: key, expiry: expiry})
+	return false
+}
+
+// expire drops the keys that expired by now from the front of the queue. An
+// entry whose key was forgotten and seen again since only removes the key if
+// the expiry still matches.
+func (d *MemoryDeduplicator) expire(now time.Time) {
+	n := 0
+	for ; n < len(d.queue) && now.After(d.queue[n].expiry); n++ {
+		entry := d.queue[n]
+		if expiry, ok := d.keys[entry.key]; ok && expiry.Equal(entry.expiry) {
+			delete(d.keys, entry.key)
+		}
+	}
+	if n > 0 {
+		d.queue = d.queue[n:]
+	}
+}
+
+func (d *MemoryDeduplicator) Forget(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.keys, key)
+}
+
+// WebhookHandler receives Paystack webhooks, verifies them and dispatches
+// them to the callback registered for each event.
+type WebhookHandler struct {
+	secretKey   string
+	allowedIPs  map[string]bool
+	deduplicate WebhookDeduplicator
+	callbacks   map[string]WebhookCallback
+	fallback    WebhookCallback
+	mu          sync.RWMutex
+}
+
+// NewWebhookHandler creates a handler that verifies signatures with secretKey,
+// which must be set. Repeat deliveries are dropped for 24 hours by default.
+func NewWebhookHandler(secretKey string) (*WebhookHandler, error) {
+	if secretKey == "" {
+		return nil, errors.New("webhook secret key is required")
+	}
+	return &WebhookHandler{
+		secretKey:   secretKey,
+		deduplicate: NewMemoryDeduplicator(24 * time.Hour),
+		callbacks:   make(map[string]WebhookCallback),
+	}, nil
+}
+
+// AllowIPs only accepts webhooks whose client IP, as resolved by gin, is one of
+// ips. Pass PaystackWebhookIPs to accept Paystack's published addresses.
+func (h *WebhookHandler) AllowIPs(ips ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.allowedIPs = make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		h.allowedIPs[ip] = true
+	}
+}
+
+// SetDeduplicator replaces the store used to drop repeat deliveries. Pass nil to disable it.
+func (h *WebhookHandler) SetDeduplicator(deduplicator WebhookDeduplicator) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deduplicate = deduplicator
+}
+
+// On registers the callback for an event.
+func (h *WebhookHandler) On(event string, callback WebhookCallback) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callbacks[event] = callback
+}
+
+// OnUnhandled registers the callback for events without a specific callback.
+func (h *WebhookHandler) OnUnhandled(callback WebhookCallback) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = callback
+}
+
+// OnChargeSuccess registers the callback for charge.success.
+func (h *WebhookHandler) OnChargeSuccess(callback func(c *gin.Context, data *PaystackEventData) error) {
+	h.On(EventChargeSuccess, func(c *gin.Context, event *WebhookEvent) error {
+		data, err := event.Charge()
+		if err != nil {
+			return err
+		}
+		return callback(c, data)
+	})
+}
+
+// OnTransfer registers the same callback for transfer.success, transfer.failed
+// and transfer.reversed.
+func (h *WebhookHandler) OnTransfer(callback func(c *gin.Context, event string, data *TransferEventData) error) {
+	for _, name := range []string{EventTransferSuccess, EventTransferFailed, EventTransferReversed} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.Transfer()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnDedicatedAccountAssign registers the same callback for
+// dedicatedaccount.assign.success and dedicatedaccount.assign.failed.
+func (h *WebhookHandler) OnDedicatedAccountAssign(callback func(c *gin.Context, event string, data *DedicatedAccountEventData) error) {
+	for _, name := range []string{EventDedicatedAccountAssignSuccess, EventDedicatedAccountAssignFailed} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.DedicatedAccount()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnSubscription registers the same callback for subscription.create,
+// subscription.disable and subscription.not_renew.
+func (h *WebhookHandler) OnSubscription(callback func(c *gin.Context, event string, data *Subscription) error) {
+	for _, name := range []string{EventSubscriptionCreate, EventSubscriptionDisable, EventSubscriptionNotRenew} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.Subscription()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnInvoice registers the same callback for invoice.create, invoice.update
+// and invoice.payment_failed.
+func (h *WebhookHandler) OnInvoice(callback func(c *gin.Context, event string, data *InvoiceEventData) error) {
+	for _, name := range []string{EventInvoiceCreate, EventInvoiceUpdate, EventInvoicePaymentFailed} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.Invoice()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnRefund registers the same callback for refund.pending, refund.processing,
+// refund.processed and refund.failed.
+func (h *WebhookHandler) OnRefund(callback func(c *gin.Context, event string, data *RefundEventData) error) {
+	for _, name := range []string{EventRefundPending, EventRefundProcessing, EventRefundProcessed, EventRefundFailed} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.Refund()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnDispute registers the same callback for charge.dispute.create,
+// charge.dispute.remind and charge.dispute.resolve.
+func (h *WebhookHandler) OnDispute(callback func(c *gin.Context, event string, data *Dispute) error) {
+	for _, name := range []string{EventChargeDisputeCreate, EventChargeDisputeRemind, EventChargeDisputeResolve} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.Dispute()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// OnCustomerIdentification registers the same callback for
+// customeridentification.success and customeridentification.failed. Use
+// MapIdentificationEvent to turn event into the customer's KycStatus.
+func (h *WebhookHandler) OnCustomerIdentification(callback func(c *gin.Context, event string, data *CustomerIdentificationEventData) error) {
+	for _, name := range []string{EventCustomerIdentificationSuccess, EventCustomerIdentificationFailed} {
+		h.On(name, func(c *gin.Context, event *WebhookEvent) error {
+			data, err := event.CustomerIdentification()
+			if err != nil {
+				return err
+			}
+			return callback(c, event.Event, data)
+		})
+	}
+}
+
+// Handle is the gin handler for the webhook route.
+func (h *WebhookHandler) Handle(c *gin.Context) {
+	h.mu.RLock()
+	allowedIPs := h.allowedIPs
+	deduplicate := h.deduplicate
+	h.mu.RUnlock()
+
+	if len(allowedIPs) > 0 && !allowedIPs[c.ClientIP()] {
+		logrus.Warn("paystack webhook from unexpected ip ", c.ClientIP())
+		webhookError(c, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		webhookError(c, http.StatusBadRequest, "unable to read request body")
+		return
+	}
+
+	if !VerifySignature(h.secretKey, body, c.GetHeader(PaystackSignatureHeader)) {
+		webhookError(c, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Event == "" {
+		webhookError(c, http.StatusBadRequest, "invalid webhook payload")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	key := hex.EncodeToString(sum[:])
+	if deduplicate != nil && deduplicate.Seen(key) {
+		c.JSON(http.StatusOK, interfacesx.SuccessResponse{Message: "duplicate event ignored", Code: http.StatusOK, Status: "success"})
+		return
+	}
+
+	h.mu.RLock()
+	callback, ok := h.callbacks[event.Event]
+	if !ok {
+		callback = h.fallback
+	}
+	h.mu.RUnlock()
+
+	if callback != nil {
+		if err := callback(c, &event); err != nil {
+			logrus.WithField("event", event.Event).Error("paystack webhook callback failed: ", err)
+			if deduplicate != nil {
+				deduplicate.Forget(key)
+			}
+			webhookError(c, http.StatusInternalServerError, "failed to process event")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, interfacesx.SuccessResponse{Message: "event received", Code: http.StatusOK, Status: "success"})
+}
+
+func webhookError(c *gin.Context, code int, message string) {
+	c.AbortWithStatusJSON(code, interfacesx.ErrorResponse{Message: message, Code: code, Status: "error"})
+}
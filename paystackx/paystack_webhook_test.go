@@ -0,0 +1,135 @@
+package paystackx
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretKey = "sk_test_webhook"
+
+const transferSuccessBody = `{"event":"transfer.success","data":{"amount":30000,"currency":"NGN","id":37272792,"reason":"Withdrawal","reference":"wd-1","source":"balance","status":"success","transfer_code":"TRF_wpl1dem4967avzm","recipient":{"recipient_code":"RCP_a8wkxiychzdzfgs","type":"nuban","details":{"account_number":"0000000000","bank_code":"011","bank_name":"First Bank of Nigeria"}}}}`
+
+func newWebhookRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/webhooks/paystack", handler.Handle)
+	return router
+}
+
+func deliverWebhook(router *gin.Engine, body, signature, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/paystack", bytes.NewBufferString(body))
+	req.Header.Set(PaystackSignatureHeader, signature)
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestWebhookDispatchesVerifiedTransfer(t *testing.T) {
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+
+	var received []*TransferEventData
+	handler.OnTransfer(func(c *gin.Context, event string, data *TransferEventData) error {
+		require.Equal(t, EventTransferSuccess, event)
+		received = append(received, data)
+		return nil
+	})
+	router := newWebhookRouter(handler)
+
+	signature := SignPayload(testSecretKey, []byte(transferSuccessBody))
+	require.Equal(t, http.StatusOK, deliverWebhook(router, transferSuccessBody, signature, "").Code)
+	require.Equal(t, http.StatusOK, deliverWebhook(router, transferSuccessBody, signature, "").Code)
+
+	require.Len(t, received, 1)
+	require.Equal(t, int64(30000), received[0].Amount)
+	require.Equal(t, "wd-1", received[0].Reference)
+	require.Equal(t, "RCP_a8wkxiychzdzfgs", received[0].Recipient.RecipientCode)
+}
+
+func TestWebhookRejectsBadSignature(t *testing.T) {
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+	handler.OnTransfer(func(*gin.Context, string, *TransferEventData) error {
+		t.Fatal("callback must not run")
+		return nil
+	})
+	router := newWebhookRouter(handler)
+
+	require.Equal(t, http.StatusUnauthorized, deliverWebhook(router, transferSuccessBody, SignPayload("sk_other", []byte(transferSuccessBody)), "").Code)
+	require.Equal(t, http.StatusUnauthorized, deliverWebhook(router, transferSuccessBody, "", "").Code)
+}
+
+func TestWebhookRejectsEmptySecretKey(t *testing.T) {
+	_, err := NewWebhookHandler("")
+	require.EqualError(t, err, "webhook secret key is required")
+
+	body := []byte(transferSuccessBody)
+	require.False(t, VerifySignature("", body, SignPayload("", body)))
+	require.True(t, VerifySignature(testSecretKey, body, SignPayload(testSecretKey, body)))
+}
+
+func TestWebhookRejectsUnknownIP(t *testing.T) {
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+	handler.AllowIPs(PaystackWebhookIPs...)
+	router := newWebhookRouter(handler)
+
+	signature := SignPayload(testSecretKey, []byte(transferSuccessBody))
+	require.Equal(t, http.StatusForbidden, deliverWebhook(router, transferSuccessBody, signature, "10.0.0.1:4000").Code)
+	require.Equal(t, http.StatusOK, deliverWebhook(router, transferSuccessBody, signature, "52.31.139.75:4000").Code)
+}
+
+func TestWebhookRetriesAfterCallbackError(t *testing.T) {
+	handler, err := NewWebhookHandler(testSecretKey)
+	require.NoError(t, err)
+
+	calls := 0
+	handler.OnTransfer(func(*gin.Context, string, *TransferEventData) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+	router := newWebhookRouter(handler)
+
+	signature := SignPayload(testSecretKey, []byte(transferSuccessBody))
+	require.Equal(t, http.StatusInternalServerError, deliverWebhook(router, transferSuccessBody, signature, "").Code)
+	require.Equal(t, http.StatusOK, deliverWebhook(router, transferSuccessBody, signature, "").Code)
+	require.Equal(t, 2, calls)
+}
+
+func TestMemoryDeduplicatorExpiresKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dedup := NewMemoryDeduplicator(time.Minute)
+	dedup.now = func() time.Time { return now }
+
+	require.False(t, dedup.Seen("a"))
+	now = now.Add(30 * time.Second)
+	require.False(t, dedup.Seen("b"))
+	require.True(t, dedup.Seen("a"))
+
+	// a expires, b has not yet.
+	now = now.Add(45 * time.Second)
+	require.True(t, dedup.Seen("b"))
+	require.False(t, dedup.Seen("a"))
+
+	// A key forgotten and seen again keeps its new expiry when the old
+	// queue entry expires.
+	dedup.Forget("b")
+	require.False(t, dedup.Seen("b"))
+	now = now.Add(30 * time.Second)
+	require.True(t, dedup.Seen("b"))
+	require.Len(t, dedup.keys, 2)
+	require.Len(t, dedup.queue, 2)
+}
@@ -0,0 +1,281 @@
+package paystacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+type deposit struct {
+	accountNumber string
+	charge        paystackx.PaystackEventData
+	notified      bool
+}
+
+// ReceiveTransfer pays amount, in the minor unit, into a dedicated account and
+// credits the balance. With notify the charge.success webhook is sent at once;
+// without it the webhook is only sent when the account is requeried, like a
+// deposit whose notification Paystack missed.
+func (s *Server) ReceiveTransfer(accountNumber string, amount int64, notify bool) error {
+	s.mu.Lock()
+	account := s.accountByNumber(accountNumber)
+	if account == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("dedicated account %s not found", accountNumber)
+	}
+	customer := s.customers[account.Customer.CustomerCode]
+
+	id := s.newID()
+	now := timestamp()
+	charge := paystackx.PaystackEventData{
+		ID:              id,
+		Domain:          "test",
+		Status:          "success",
+		Reference:       fmt.Sprintf("%d_%s", id, accountNumber),
+		Amount:          amount,
+		GatewayResponse: "Approved",
+		PaidAt:          now,
+		CreatedAt:       now,
+		Channel:         "dedicated_nuban",
+		Currency:        account.Currency,
+		Customer:        *customer,
+		Authorization: paystackx.PaystackEventAuthorization{
+			AuthorizationCode:         fmt.Sprintf("AUTH_%010d", id),
+			Channel:                   "dedicated_nuban",
+			Bank:                      account.Bank.Name,
+			CountryCode:               "NG",
+			SenderBank:                "Test Bank",
+			SenderBankAccountNumber:   "XXXXXX4321",
+			SenderName:                "Test Sender",
+			ReceiverBankAccountNumber: account.AccountNumber,
+			ReceiverBank:              account.Bank.Name,
+		},
+	}
+	s.balances[account.Currency] += amount
+	s.deposits = append(s.deposits, &deposit{accountNumber: accountNumber, charge: charge, notified: notify})
+	s.mu.Unlock()
+
+	if !notify {
+		return nil
+	}
+	return s.SendWebhook(paystackx.EventChargeSuccess, charge)
+}
+
+// accountByNumber must be called with s.mu held.
+func (s *Server) accountByNumber(accountNumber string) *paystackx.VirtaualAccountData {
+	for _, account := range s.accounts {
+		if account.AccountNumber == accountNumber {
+			return account
+		}
+	}
+	return nil
+}
+
+// accountByID must be called with s.mu held.
+func (s *Server) accountByID(id string) *paystackx.VirtaualAccountData {
+	for _, account := range s.accounts {
+		if strconv.Itoa(account.ID) == id {
+			return account
+		}
+	}
+	return nil
+}
+
+func (s *Server) assignDedicatedAccount(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.AssignDedicatedAccountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if !strings.Contains(request.Email, "@") || request.FirstName == "" || request.LastName == "" || request.Phone == "" {
+		writeValidationError(w, "Email, first name, last name and phone are required")
+		return
+	}
+
+	s.mu.Lock()
+	customer := s.findCustomer(request.Email)
+	if customer == nil {
+		id := s.newID()
+		customer = &paystackx.PaystackEventCustomer{
+			ID:           id,
+			Email:        request.Email,
+			CustomerCode: fmt.Sprintf("CUS_%013d", id),
+			RiskAction:   "default",
+		}
+		s.customers[customer.CustomerCode] = customer
+	}
+	customer.FirstName = request.FirstName
+	customer.LastName = request.LastName
+	customer.Phone = request.Phone
+
+	_, existed, ok := s.assignAccount(customer, request.PreferredBank)
+	failed := paystackx.DedicatedAccountEventData{
+		Customer:       *customer,
+		Identification: paystackx.DedicatedAccountIdentification{Status: "failed"},
+	}
+	code := customer.CustomerCode
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		s.SendWebhook(paystackx.EventDedicatedAccountAssignFailed, failed)
+	case !existed:
+		s.sendAssignSuccess(code)
+	}
+	writeSuccess(w, "Assign dedicated account in progress", nil, nil)
+}
+
+func (s *Server) listDedicatedAccounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	var accounts []paystackx.VirtaualAccountData
+	for _, account := range s.accounts {
+		if active := query.Get("active"); active != "" && strconv.FormatBool(account.Active) != active {
+			continue
+		}
+		if currency := query.Get("currency"); currency != "" && !strings.EqualFold(account.Currency, currency) {
+			continue
+		}
+		if slug := query.Get("provider_slug"); slug != "" && account.Bank.Slug != slug {
+			continue
+		}
+		if customer := query.Get("customer"); customer != "" && strconv.Itoa(account.Customer.ID) != customer {
+			continue
+		}
+		accounts = append(accounts, *account)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+	start, end, meta := paginate(r, len(accounts))
+	writeSuccess(w, "Managed Accounts Successfully Retrieved", accounts[start:end], meta)
+}
+
+func (s *Server) fetchDedicatedAccount(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	account := s.accountByID(id)
+	if account == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Dedicated account not found")
+		return
+	}
+	data := *account
+	s.mu.Unlock()
+
+	writeSuccess(w, "Customer retrieved", data, nil)
+}
+
+func (s *Server) deactivateDedicatedAccount(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	account := s.accountByID(id)
+	if account == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Dedicated account not found")
+		return
+	}
+	account.Active = false
+	account.Assigned = false
+	account.UpdatedAt = timestamp()
+	data := *account
+	s.mu.Unlock()
+
+	writeSuccess(w, "Managed Account Successfully Unassigned", data, nil)
+}
+
+func (s *Server) requeryDedicatedAccount(w http.ResponseWriter, r *http.Request) {
+	accountNumber := r.URL.Query().Get("account_number")
+
+	s.mu.Lock()
+	if s.accountByNumber(accountNumber) == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Dedicated account not found")
+		return
+	}
+	var missed []paystackx.PaystackEventData
+	for _, deposit := range s.deposits {
+		if deposit.accountNumber == accountNumber && !deposit.notified {
+			deposit.notified = true
+			missed = append(missed, deposit.charge)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, charge := range missed {
+		s.SendWebhook(paystackx.EventChargeSuccess, charge)
+	}
+	writeSuccess(w, "We are checking the status of your transfer. We will send you a notification once it has been confirmed", nil, nil)
+}
+
+func (s *Server) splitDedicatedAccount(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.SplitDedicatedAccountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Subaccount == "" && request.SplitCode == "" {
+		writeValidationError(w, "Subaccount or split code is required")
+		return
+	}
+
+	s.mu.Lock()
+	customer := s.findCustomer(request.Customer)
+	if customer == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+	account, existed, ok := s.assignAccount(customer, request.PreferredBank)
+	if !ok {
+		s.mu.Unlock()
+		writeValidationError(w, "Invalid preferred bank")
+		return
+	}
+	account.SplitConfig, _ = json.Marshal(map[string]string{"subaccount": request.Subaccount, "split_code": request.SplitCode})
+	data := *account
+	code := customer.CustomerCode
+	s.mu.Unlock()
+
+	if !existed {
+		s.sendAssignSuccess(code)
+	}
+	writeSuccess(w, "Assigned Managed Account Successfully Created", data, nil)
+}
+
+func (s *Server) removeDedicatedAccountSplit(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		AccountNumber string `json:"account_number"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	account := s.accountByNumber(request.AccountNumber)
+	if account == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Dedicated account not found")
+		return
+	}
+	account.SplitConfig = nil
+	data := *account
+	s.mu.Unlock()
+
+	writeSuccess(w, "Subaccount unassigned", data, nil)
+}
+
+func (s *Server) dedicatedAccountProviders(w http.ResponseWriter) {
+	s.mu.Lock()
+	var providers []paystackx.DedicatedAccountProvider
+	for _, slug := range []string{"wema-bank", "access-bank"} {
+		if bank := s.bankBySlug(slug); bank != nil {
+			providers = append(providers, paystackx.DedicatedAccountProvider{ID: len(providers) + 1, ProviderSlug: bank.Slug, BankID: bank.ID, BankName: bank.Name})
+		}
+	}
+	s.mu.Unlock()
+
+	writeSuccess(w, "Dedicated account providers retrieved", providers, nil)
+}
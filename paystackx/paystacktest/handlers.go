@@ -0,0 +1,902 @@
+package paystacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+const integrationID = 100123
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requestsByPath[r.Method+" "+r.URL.Path]++
+	s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+s.SecretKey {
+		writeError(w, http.StatusUnauthorized, "Invalid key")
+		return
+	}
+
+	failure := s.takeFailure(r)
+	if failure == nil {
+		s.route(w, r)
+		return
+	}
+	if failure.AfterEffect {
+		s.route(httptest.NewRecorder(), r)
+	}
+	if failure.Drop {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	if failure.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(failure.RetryAfter.Seconds()))))
+	}
+	writeError(w, failure.StatusCode, failure.Message)
+}
+
+func (s *Server) takeFailure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.failures) - 1; i >= 0; i-- {
+		failure := s.failures[i]
+		if failure.Method != "" && failure.Method != r.Method {
+			continue
+		}
+		if failure.Path != "" && !strings.HasPrefix(r.URL.Path, failure.Path) {
+			continue
+		}
+		if failure.ExactPath && r.URL.Path != failure.Path {
+			continue
+		}
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return failure
+	}
+	return nil
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case r.Method == http.MethodPost && path == "customer":
+		s.createCustomer(w, r)
+	case r.Method == http.MethodPut && len(segments) == 2 && segments[0] == "customer":
+		s.updateCustomer(w, r, segments[1])
+	case r.Method == http.MethodPost && len(segments) == 3 && segments[0] == "customer" && segments[2] == "identification":
+		s.validateCustomer(w, r, segments[1])
+	case r.Method == http.MethodPost && path == "dedicated_account":
+		s.createDedicatedAccount(w, r)
+	case r.Method == http.MethodPost && path == "dedicated_account/assign":
+		s.assignDedicatedAccount(w, r)
+	case r.Method == http.MethodGet && path == "dedicated_account":
+		s.listDedicatedAccounts(w, r)
+	case r.Method == http.MethodGet && path == "dedicated_account/requery":
+		s.requeryDedicatedAccount(w, r)
+	case r.Method == http.MethodGet && path == "dedicated_account/available_providers":
+		s.dedicatedAccountProviders(w)
+	case r.Method == http.MethodPost && path == "dedicated_account/split":
+		s.splitDedicatedAccount(w, r)
+	case r.Method == http.MethodDelete && path == "dedicated_account/split":
+		s.removeDedicatedAccountSplit(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "dedicated_account":
+		s.fetchDedicatedAccount(w, segments[1])
+	case r.Method == http.MethodDelete && len(segments) == 2 && segments[0] == "dedicated_account":
+		s.deactivateDedicatedAccount(w, segments[1])
+	case r.Method == http.MethodPost && path == "transferrecipient":
+		s.createRecipient(w, r)
+	case r.Method == http.MethodPost && path == "transferrecipient/bulk":
+		s.createBulkRecipients(w, r)
+	case r.Method == http.MethodGet && path == "transferrecipient":
+		s.listRecipients(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "transferrecipient":
+		s.fetchRecipient(w, segments[1])
+	case r.Method == http.MethodPut && len(segments) == 2 && segments[0] == "transferrecipient":
+		s.updateRecipient(w, r, segments[1])
+	case r.Method == http.MethodDelete && len(segments) == 2 && segments[0] == "transferrecipient":
+		s.deleteRecipient(w, segments[1])
+	case r.Method == http.MethodPost && path == "transfer":
+		s.initiateTransfer(w, r)
+	case r.Method == http.MethodPost && path == "transfer/finalize_transfer":
+		s.finalizeTransfer(w, r)
+	case r.Method == http.MethodPost && path == "transfer/resend_otp":
+		s.resendOTP(w, r)
+	case r.Method == http.MethodPost && path == "transfer/disable_otp":
+		writeSuccess(w, "OTP has been sent to mobile number ending with 4321", nil, nil)
+	case r.Method == http.MethodPost && path == "transfer/disable_otp_finalize":
+		s.finalizeDisableOTP(w, r)
+	case r.Method == http.MethodPost && path == "transfer/enable_otp":
+		s.SetOTPRequired(true)
+		writeSuccess(w, "OTP requirement for transfers has been enabled", nil, nil)
+	case r.Method == http.MethodPost && path == "transfer/bulk":
+		s.bulkTransfer(w, r)
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "transfer" && segments[1] == "verify":
+		s.fetchTransfer(w, segments[2])
+	case r.Method == http.MethodGet && path == "transfer":
+		s.listTransfers(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "transfer":
+		s.fetchTransfer(w, segments[1])
+	case r.Method == http.MethodPost && path == "subaccount":
+		s.createSubaccount(w, r)
+	case r.Method == http.MethodGet && path == "subaccount":
+		s.listSubaccounts(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "subaccount":
+		s.fetchSubaccount(w, segments[1])
+	case r.Method == http.MethodPut && len(segments) == 2 && segments[0] == "subaccount":
+		s.updateSubaccount(w, r, segments[1])
+	case r.Method == http.MethodPost && path == "split":
+		s.createSplit(w, r)
+	case r.Method == http.MethodGet && path == "split":
+		s.listSplits(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "split":
+		s.fetchSplit(w, segments[1])
+	case r.Method == http.MethodPut && len(segments) == 2 && segments[0] == "split":
+		s.updateSplit(w, r, segments[1])
+	case r.Method == http.MethodPost && len(segments) == 4 && segments[0] == "split" && segments[2] == "subaccount" && segments[3] == "add":
+		s.addSplitSubaccount(w, r, segments[1])
+	case r.Method == http.MethodPost && len(segments) == 4 && segments[0] == "split" && segments[2] == "subaccount" && segments[3] == "remove":
+		s.removeSplitSubaccount(w, r, segments[1])
+	case r.Method == http.MethodPost && path == "plan":
+		s.createPlan(w, r)
+	case r.Method == http.MethodGet && path == "transaction":
+		s.listTransactions(w, r)
+	case r.Method == http.MethodGet && path == "plan":
+		s.listPlans(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "plan":
+		s.fetchPlan(w, segments[1])
+	case r.Method == http.MethodPut && len(segments) == 2 && segments[0] == "plan":
+		s.updatePlan(w, r, segments[1])
+	case r.Method == http.MethodPost && path == "subscription":
+		s.createSubscription(w, r)
+	case r.Method == http.MethodPost && (path == "subscription/enable" || path == "subscription/disable"):
+		s.toggleSubscription(w, r, segments[1] == "enable")
+	case r.Method == http.MethodGet && path == "subscription":
+		s.listSubscriptions(w, r)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "subscription":
+		s.fetchSubscription(w, segments[1])
+	case r.Method == http.MethodGet && path == "balance":
+		s.balance(w)
+	case r.Method == http.MethodGet && path == "balance/ledger":
+		s.listLedger(w, r)
+	case r.Method == http.MethodGet && path == "settlement":
+		s.listSettlements(w, r)
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "settlement" && segments[2] == "transactions":
+		s.listSettlementTransactions(w, r, segments[1])
+	case r.Method == http.MethodGet && path == "bank":
+		s.listBanks(w, r)
+	case r.Method == http.MethodGet && path == "bank/resolve":
+		s.resolveAccount(w, r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s is not supported by paystacktest", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.PaystackCreateUserRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if !strings.Contains(request.Email, "@") {
+		writeValidationError(w, "Invalid email address passed")
+		return
+	}
+
+	s.mu.Lock()
+	var customer *paystackx.PaystackEventCustomer
+	for _, existing := range s.customers {
+		if strings.EqualFold(existing.Email, request.Email) {
+			customer = existing
+		}
+	}
+	if customer == nil {
+		id := s.newID()
+		customer = &paystackx.PaystackEventCustomer{
+			ID:           id,
+			FirstName:    request.FirstName,
+			LastName:     request.LastName,
+			Email:        request.Email,
+			CustomerCode: fmt.Sprintf("CUS_%013d", id),
+			Phone:        request.Phone,
+			RiskAction:   "default",
+		}
+		s.customers[customer.CustomerCode] = customer
+	}
+	data := customerData(customer, s.identified[customer.CustomerCode])
+	s.mu.Unlock()
+
+	writeSuccess(w, "Customer created", data, nil)
+}
+
+func (s *Server) updateCustomer(w http.ResponseWriter, r *http.Request, code string) {
+	var request paystackx.PaystackUpdateUserRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	customer, ok := s.customers[code]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+	customer.FirstName = request.FirstName
+	customer.LastName = request.LastName
+	customer.Phone = request.Phone
+	data := customerData(customer, s.identified[customer.CustomerCode])
+	s.mu.Unlock()
+
+	writeSuccess(w, "Customer updated", data, nil)
+}
+
+func customerData(customer *paystackx.PaystackEventCustomer, identified bool) map[string]interface{} {
+	return map[string]interface{}{
+		"email":         customer.Email,
+		"integration":   integrationID,
+		"domain":        "test",
+		"customer_code": customer.CustomerCode,
+		"id":            customer.ID,
+		"identified":    identified,
+		"first_name":    customer.FirstName,
+		"last_name":     customer.LastName,
+		"phone":         customer.Phone,
+	}
+}
+
+func (s *Server) createDedicatedAccount(w http.ResponseWriter, r *http.Request) {
+	var request interfacesx.CreatePaystackVirtualAccountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	customer := s.customers[request.Customer]
+	if customer == nil {
+		for _, existing := range s.customers {
+			if strconv.Itoa(existing.ID) == request.Customer {
+				customer = existing
+			}
+		}
+	}
+	if customer == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
+	if request.FirstName != "" {
+		customer.FirstName = request.FirstName
+	}
+	if request.LastName != "" {
+		customer.LastName = request.LastName
+	}
+	if request.Phone != "" {
+		customer.Phone = request.Phone
+	}
+
+	account, existed, ok := s.assignAccount(customer, request.PreferredBank)
+	if !ok {
+		s.mu.Unlock()
+		writeValidationError(w, "Invalid preferred bank")
+		return
+	}
+	data := *account
+	s.mu.Unlock()
+
+	if !existed {
+		s.sendAssignSuccess(customer.CustomerCode)
+	}
+	writeSuccess(w, "NUBAN successfully created", data, nil)
+}
+
+// assignAccount returns the customer's dedicated account, creating it at the
+// preferred bank if needed. ok is false if the bank is unknown. It must be
+// called with s.mu held.
+func (s *Server) assignAccount(customer *paystackx.PaystackEventCustomer, preferredBank string) (account *paystackx.VirtaualAccountData, existed, ok bool) {
+	if account, existed := s.accounts[customer.CustomerCode]; existed && account.Active {
+		return account, true, true
+	}
+
+	bank := s.bankBySlug(preferredBank)
+	if bank == nil {
+		return nil, false, false
+	}
+
+	id := s.newID()
+	now := timestamp()
+	account = &paystackx.VirtaualAccountData{
+		Bank:          paystackx.Bank{Name: bank.Name, ID: bank.ID, Slug: bank.Slug},
+		AccountName:   strings.TrimSpace("PAYSTACK/" + customer.FirstName + " " + customer.LastName),
+		AccountNumber: fmt.Sprintf("%010d", 9000000000+id),
+		Assigned:      true,
+		Currency:      "NGN",
+		Active:        true,
+		ID:            id,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Assignment: paystackx.Assignment{
+			Integration:  integrationID,
+			AssigneeID:   customer.ID,
+			AssigneeType: "Customer",
+			AccountType:  "PAY-WITH-TRANSFER-RECURRING",
+			AssignedAt:   now,
+		},
+		Customer: paystackx.Customer{
+			ID:           customer.ID,
+			FirstName:    customer.FirstName,
+			LastName:     customer.LastName,
+			Email:        customer.Email,
+			CustomerCode: customer.CustomerCode,
+			Phone:        customer.Phone,
+			RiskAction:   customer.RiskAction,
+		},
+	}
+	s.accounts[customer.CustomerCode] = account
+	return account, false, true
+}
+
+// sendAssignSuccess sends dedicatedaccount.assign.success for a customer's account.
+func (s *Server) sendAssignSuccess(customerCode string) {
+	s.mu.Lock()
+	customer := *s.customers[customerCode]
+	account := *s.accounts[customerCode]
+	s.mu.Unlock()
+
+	s.SendWebhook(paystackx.EventDedicatedAccountAssignSuccess, paystackx.DedicatedAccountEventData{
+		Customer:         customer,
+		DedicatedAccount: &account,
+		Identification:   paystackx.DedicatedAccountIdentification{Status: "success"},
+	})
+}
+
+// bankBySlug must be called with s.mu held.
+func (s *Server) bankBySlug(slug string) *paystackx.Banks {
+	for i := range s.banks {
+		if s.banks[i].Slug == slug {
+			return &s.banks[i]
+		}
+	}
+	return nil
+}
+
+// bankByCode must be called with s.mu held.
+func (s *Server) bankByCode(code string) *paystackx.Banks {
+	for i := range s.banks {
+		if s.banks[i].Code == code {
+			return &s.banks[i]
+		}
+	}
+	return nil
+}
+
+// accountName must be called with s.mu held.
+func (s *Server) accountName(bankCode, accountNumber string) (string, bool) {
+	for _, account := range s.bankAccounts {
+		if account.bankCode == bankCode && account.number == accountNumber {
+			return account.name, true
+		}
+	}
+	return "", false
+}
+
+// recipientRequest is the body of transferrecipient as Paystack documents it.
+type recipientRequest struct {
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	AccountNumber string `json:"account_number"`
+	BankCode      string `json:"bank_code"`
+	Currency      string `json:"currency"`
+	Description   string `json:"description"`
+}
+
+func (s *Server) createRecipient(w http.ResponseWriter, r *http.Request) {
+	var request recipientRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	recipient, message := s.addRecipient(request)
+	if recipient == nil {
+		s.mu.Unlock()
+		writeValidationError(w, message)
+		return
+	}
+	data := recipient.resource()
+	s.mu.Unlock()
+
+	writeSuccess(w, "Transfer recipient created successfully", data, nil)
+}
+
+// addRecipient creates a recipient, or returns the existing one for the same
+// account. It returns a validation message instead when the bank is unknown
+// or does not pay out in the currency.
+// It must be called with s.mu held.
+func (s *Server) addRecipient(request recipientRequest) (*recipient, string) {
+	bank := s.bankByCode(request.BankCode)
+	if bank == nil {
+		return nil, "Unknown bank code: " + request.BankCode
+	}
+
+	for _, existing := range s.recipients {
+		if existing.accountNumber == request.AccountNumber && existing.bankCode == request.BankCode {
+			existing.active = true
+			return existing, ""
+		}
+	}
+
+	name := request.Name
+	if resolved, ok := s.accountName(request.BankCode, request.AccountNumber); ok {
+		name = resolved
+	}
+	currency := request.Currency
+	if currency == "" {
+		currency = "NGN"
+	}
+	if bank.Currency != "" && !strings.EqualFold(bank.Currency, currency) {
+		return nil, bank.Name + " does not support " + currency
+	}
+
+	id := s.newID()
+	now := timestamp()
+	created := &recipient{
+		id:            id,
+		code:          fmt.Sprintf("RCP_%013d", id),
+		recipientType: request.Type,
+		name:          name,
+		currency:      currency,
+		description:   request.Description,
+		accountNumber: request.AccountNumber,
+		accountName:   name,
+		bankCode:      request.BankCode,
+		bankName:      bank.Name,
+		active:        true,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+	s.recipients[created.code] = created
+	return created, ""
+}
+
+type transferRequest struct {
+	Source    string `json:"source"`
+	Reason    string `json:"reason"`
+	Amount    int64  `json:"amount"`
+	Recipient string `json:"recipient"`
+	Reference string `json:"reference"`
+	Currency  string `json:"currency"`
+}
+
+// queueTransfer validates and records a transfer, taking the amount from the
+// balance. It must be called with s.mu held.
+func (s *Server) queueTransfer(request transferRequest) (*transfer, int, string) {
+	recipient, ok := s.recipients[request.Recipient]
+	if !ok || !recipient.active {
+		return nil, http.StatusBadRequest, "Recipient specified is invalid"
+	}
+	if request.Amount <= 0 {
+		return nil, http.StatusBadRequest, "Invalid amount"
+	}
+	if request.Reference != "" && s.findTransfer(request.Reference) != nil {
+		return nil, http.StatusBadRequest, "Duplicate Transfer Reference"
+	}
+
+	currency := strings.ToUpper(request.Currency)
+	if currency == "" {
+		currency = recipient.currency
+	}
+	amount := request.Amount
+	if s.balances[currency] < amount {
+		return nil, http.StatusBadRequest, "Your balance is not enough to fulfil this request"
+	}
+	s.balances[currency] -= amount
+
+	id := s.newID()
+	reference := request.Reference
+	if reference == "" {
+		reference = fmt.Sprintf("ref_%013d", id)
+	}
+	source := request.Source
+	if source == "" {
+		source = "balance"
+	}
+	status := paystackx.TransferStatusPending
+	if s.otpEnabled {
+		status = paystackx.TransferStatusOTP
+	}
+
+	now := timestamp()
+	queued := &transfer{
+		id:        id,
+		amount:    amount,
+		currency:  currency,
+		reason:    request.Reason,
+		reference: reference,
+		source:    source,
+		status:    status,
+		code:      fmt.Sprintf("TRF_%013d", id),
+		recipient: recipient,
+		createdAt: now,
+		updatedAt: now,
+	}
+	s.transfers = append(s.transfers, queued)
+	return queued, 0, ""
+}
+
+// settle completes a pending transfer when auto-complete is on and returns
+// the webhook data to send. It must be called with s.mu held.
+func (s *Server) settle(transfer *transfer) map[string]interface{} {
+	if !s.autoComplete || transfer.status != paystackx.TransferStatusPending {
+		return nil
+	}
+	s.completeTransfer(transfer, paystackx.TransferStatusSuccess)
+	return transfer.event()
+}
+
+func (s *Server) initiateTransfer(w http.ResponseWriter, r *http.Request) {
+	var request transferRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	transfer, code, message := s.queueTransfer(request)
+	if transfer == nil {
+		s.mu.Unlock()
+		writeError(w, code, message)
+		return
+	}
+	settled := s.settle(transfer)
+	data := transfer.summary()
+	s.mu.Unlock()
+
+	message = "Transfer has been queued"
+	if transfer.status == paystackx.TransferStatusOTP {
+		message = "Transfer requires OTP to continue"
+	}
+	if settled != nil {
+		s.SendWebhook(paystackx.EventTransferSuccess, settled)
+	}
+	writeSuccess(w, message, data, nil)
+}
+
+func (s *Server) finalizeTransfer(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.FinalizeTransferRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	transfer := s.findTransfer(request.TransferCode)
+	switch {
+	case transfer == nil:
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Transfer not found")
+		return
+	case transfer.status != paystackx.TransferStatusOTP:
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "Transfer is not currently awaiting OTP")
+		return
+	case request.OTP != DefaultOTP:
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "Invalid OTP")
+		return
+	}
+	transfer.status = paystackx.TransferStatusPending
+	transfer.updatedAt = timestamp()
+	settled := s.settle(transfer)
+	data := transfer.summary()
+	s.mu.Unlock()
+
+	if settled != nil {
+		s.SendWebhook(paystackx.EventTransferSuccess, settled)
+	}
+	writeSuccess(w, "Transfer has been queued", data, nil)
+}
+
+func (s *Server) resendOTP(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.ResendTransferOTPRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	transfer := s.findTransfer(request.TransferCode)
+	awaitingOTP := transfer != nil && transfer.status == paystackx.TransferStatusOTP
+	s.mu.Unlock()
+
+	if !awaitingOTP {
+		writeError(w, http.StatusBadRequest, "Transfer is not currently awaiting OTP")
+		return
+	}
+	writeSuccess(w, "OTP has been resent", nil, nil)
+}
+
+func (s *Server) finalizeDisableOTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		OTP string `json:"otp"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.OTP != DefaultOTP {
+		writeError(w, http.StatusBadRequest, "Invalid OTP")
+		return
+	}
+
+	s.SetOTPRequired(false)
+	writeSuccess(w, "OTP requirement for transfers has been disabled", nil, nil)
+}
+
+func (s *Server) bulkTransfer(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Currency  string            `json:"currency"`
+		Source    string            `json:"source"`
+		Transfers []transferRequest `json:"transfers"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	if s.otpEnabled {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "You need to disable the Transfers OTP requirement to use this endpoint")
+		return
+	}
+
+	var total int64
+	for _, item := range request.Transfers {
+		total += item.Amount
+	}
+	currency := strings.ToUpper(request.Currency)
+	if currency == "" {
+		currency = "NGN"
+	}
+	if s.balances[currency] < total {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "Your balance is not enough to fulfil this request")
+		return
+	}
+
+	var results []map[string]interface{}
+	var settled []map[string]interface{}
+	for _, item := range request.Transfers {
+		item.Currency = currency
+		item.Source = request.Source
+		transfer, code, message := s.queueTransfer(item)
+		if transfer == nil {
+			s.mu.Unlock()
+			writeError(w, code, message)
+			return
+		}
+		if data := s.settle(transfer); data != nil {
+			settled = append(settled, data)
+		}
+		results = append(results, map[string]interface{}{
+			"reference":     transfer.reference,
+			"recipient":     transfer.recipient.code,
+			"amount":        transfer.amount,
+			"transfer_code": transfer.code,
+			"currency":      transfer.currency,
+			"status":        transfer.status,
+		})
+	}
+	s.mu.Unlock()
+
+	for _, data := range settled {
+		s.SendWebhook(paystackx.EventTransferSuccess, data)
+	}
+	writeSuccess(w, fmt.Sprintf("%d transfers queued.", len(results)), results, nil)
+}
+
+func (s *Server) fetchTransfer(w http.ResponseWriter, referenceOrCode string) {
+	s.mu.Lock()
+	transfer := s.findTransfer(referenceOrCode)
+	if transfer == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Transfer not found")
+		return
+	}
+	data := transfer.resource()
+	s.mu.Unlock()
+
+	writeSuccess(w, "Transfer retrieved", data, nil)
+}
+
+func (s *Server) listTransfers(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+
+	s.mu.Lock()
+	var transfers []map[string]interface{}
+	for _, transfer := range s.transfers {
+		if (status == "" || transfer.status == status) && inRange(transfer.createdAt, from, to) {
+			transfers = append(transfers, transfer.resource())
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(transfers))
+	writeSuccess(w, "Transfers retrieved", transfers[start:end], meta)
+}
+
+// paginate returns the slice bounds of the requested page of total items and
+// the matching meta.
+func paginate(r *http.Request, total int) (int, int, *paystackx.Meta) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("perPage"))
+	if perPage < 1 {
+		perPage = 50
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end, &paystackx.Meta{
+		Total:     total,
+		Skipped:   start,
+		PerPage:   perPage,
+		Page:      page,
+		PageCount: (total + perPage - 1) / perPage,
+	}
+}
+
+// dateRange parses the from and to query parameters. Missing ones are zero.
+func dateRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	var bounds [2]time.Time
+	for i, name := range []string{"from", "to"} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeValidationError(w, "Invalid "+name+" date")
+			return time.Time{}, time.Time{}, false
+		}
+		bounds[i] = parsed
+	}
+	return bounds[0], bounds[1], true
+}
+
+// inRange reports whether the timestamp at lies within from and to, inclusive.
+func inRange(at string, from, to time.Time) bool {
+	parsed, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return from.IsZero() && to.IsZero()
+	}
+	return (from.IsZero() || !parsed.Before(from)) && (to.IsZero() || !parsed.After(to))
+}
+
+func (s *Server) balance(w http.ResponseWriter) {
+	s.mu.Lock()
+	currencies := make([]string, 0, len(s.balances))
+	for currency := range s.balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var data []map[string]interface{}
+	for _, currency := range currencies {
+		data = append(data, map[string]interface{}{"currency": currency, "balance": s.balances[currency]})
+	}
+	s.mu.Unlock()
+
+	if len(data) == 0 {
+		data = append(data, map[string]interface{}{"currency": "NGN", "balance": 0})
+	}
+	writeSuccess(w, "Balances retrieved", data, nil)
+}
+
+func (s *Server) listBanks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	country := query.Get("country")
+	currency := query.Get("currency")
+	bankType := query.Get("type")
+
+	s.mu.Lock()
+	var banks []paystackx.Banks
+	for _, bank := range s.banks {
+		if country != "" && !strings.EqualFold(bank.Country, country) {
+			continue
+		}
+		if currency != "" && !strings.EqualFold(bank.Currency, currency) {
+			continue
+		}
+		if bankType != "" && bank.Type != bankType {
+			continue
+		}
+		banks = append(banks, bank)
+	}
+	s.mu.Unlock()
+
+	writeSuccess(w, "Banks retrieved", banks, &paystackx.Meta{PerPage: len(banks)})
+}
+
+func (s *Server) resolveAccount(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	name, ok := s.accountName(query.Get("bank_code"), query.Get("account_number"))
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Could not resolve account name. Check parameters or try again.")
+		return
+	}
+	writeSuccess(w, "Account number resolved", paystackx.AccountData{
+		AccountNumber: query.Get("account_number"),
+		AccountName:   name,
+	}, nil)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeValidationError(w, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeSuccess(w http.ResponseWriter, message string, data interface{}, meta *paystackx.Meta) {
+	body := map[string]interface{}{"status": true, "message": message}
+	if data != nil {
+		body["data"] = data
+	}
+	if meta != nil {
+		body["meta"] = meta
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]interface{}{"status": false, "message": message})
+}
+
+func writeValidationError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"status":  false,
+		"message": message,
+		"type":    "validation_error",
+		"code":    "invalid_params",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,80 @@
+package paystacktest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// validateCustomer passes identification when the bank account was added with
+// AddBankAccount under the customer's name and the BVN has 11 digits. The
+// outcome is sent as a customeridentification webhook.
+func (s *Server) validateCustomer(w http.ResponseWriter, r *http.Request, code string) {
+	var request paystackx.ValidateCustomerRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Type != paystackx.IdentificationTypeBankAccount || request.BVN == "" || request.BankCode == "" || request.AccountNumber == "" {
+		writeValidationError(w, "Type, BVN, bank code and account number are required")
+		return
+	}
+
+	s.mu.Lock()
+	customer, ok := s.customers[code]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
+	data := paystackx.CustomerIdentificationEventData{
+		CustomerID:   strconv.Itoa(customer.ID),
+		CustomerCode: customer.CustomerCode,
+		Email:        customer.Email,
+		Identification: paystackx.DedicatedAccountIdentification{
+			Country:       request.Country,
+			Type:          request.Type,
+			BVN:           mask(request.BVN),
+			AccountNumber: mask(request.AccountNumber),
+			BankCode:      request.BankCode,
+		},
+	}
+	name, found := s.accountName(request.BankCode, request.AccountNumber)
+	switch {
+	case len(request.BVN) != 11 || !found:
+		data.Reason = "Account number or BVN is incorrect"
+	case !nameMatches(name, request.FirstName, request.LastName):
+		data.Reason = "Names do not match the BVN"
+	}
+	event := paystackx.EventCustomerIdentificationSuccess
+	if data.Reason != "" {
+		event = paystackx.EventCustomerIdentificationFailed
+	} else {
+		s.identified[code] = true
+	}
+	s.mu.Unlock()
+
+	s.SendWebhook(event, data)
+	writeSuccess(w, "Customer Identification in progress", nil, nil)
+}
+
+func nameMatches(accountName string, names ...string) bool {
+	accountName = strings.ToLower(accountName)
+	for _, name := range names {
+		if !strings.Contains(accountName, strings.ToLower(strings.TrimSpace(name))) {
+			return false
+		}
+	}
+	return true
+}
+
+// mask hides all but the first and last three characters, as Paystack does
+// in identification webhooks.
+func mask(value string) string {
+	if len(value) <= 6 {
+		return value
+	}
+	return value[:3] + strings.Repeat("*", len(value)-6) + value[len(value)-3:]
+}
@@ -0,0 +1,124 @@
+package paystacktest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// findRecipient must be called with s.mu held.
+func (s *Server) findRecipient(idOrCode string) *recipient {
+	if recipient, ok := s.recipients[idOrCode]; ok {
+		return recipient
+	}
+	for _, recipient := range s.recipients {
+		if strconv.Itoa(recipient.id) == idOrCode {
+			return recipient
+		}
+	}
+	return nil
+}
+
+func (s *Server) createBulkRecipients(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Batch []recipientRequest `json:"batch"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+	if len(request.Batch) == 0 {
+		writeValidationError(w, "Batch is required")
+		return
+	}
+
+	success := []map[string]interface{}{}
+	errors := []map[string]interface{}{}
+
+	s.mu.Lock()
+	for _, entry := range request.Batch {
+		recipient, message := s.addRecipient(entry)
+		if recipient == nil {
+			errors = append(errors, map[string]interface{}{"error": message, "payload": entry})
+			continue
+		}
+		success = append(success, recipient.resource())
+	}
+	s.mu.Unlock()
+
+	writeSuccess(w, "Recipients added successfully", map[string]interface{}{"success": success, "errors": errors}, nil)
+}
+
+func (s *Server) listRecipients(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var active []*recipient
+	for _, recipient := range s.recipients {
+		if recipient.active {
+			active = append(active, recipient)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].id < active[j].id })
+	start, end, meta := paginate(r, len(active))
+	recipients := []map[string]interface{}{}
+	for _, recipient := range active[start:end] {
+		recipients = append(recipients, recipient.resource())
+	}
+	s.mu.Unlock()
+
+	writeSuccess(w, "Recipients retrieved", recipients, meta)
+}
+
+func (s *Server) fetchRecipient(w http.ResponseWriter, idOrCode string) {
+	s.mu.Lock()
+	recipient := s.findRecipient(idOrCode)
+	if recipient == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Recipient not found")
+		return
+	}
+	data := recipient.resource()
+	s.mu.Unlock()
+
+	writeSuccess(w, "Recipient retrieved", data, nil)
+}
+
+func (s *Server) updateRecipient(w http.ResponseWriter, r *http.Request, idOrCode string) {
+	var request paystackx.UpdateTransferRecipientRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Name == "" {
+		writeValidationError(w, "Name is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recipient := s.findRecipient(idOrCode)
+	if recipient == nil {
+		writeError(w, http.StatusNotFound, "Recipient not found")
+		return
+	}
+	recipient.name = request.Name
+	if request.Email != "" {
+		recipient.email = request.Email
+	}
+	recipient.updatedAt = timestamp()
+
+	writeSuccess(w, "Recipient updated", nil, nil)
+}
+
+func (s *Server) deleteRecipient(w http.ResponseWriter, idOrCode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recipient := s.findRecipient(idOrCode)
+	if recipient == nil {
+		writeError(w, http.StatusNotFound, "Recipient not found")
+		return
+	}
+	recipient.active = false
+	recipient.updatedAt = timestamp()
+
+	writeSuccess(w, "Transfer recipient set as inactive", nil, nil)
+}
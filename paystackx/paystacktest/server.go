@@ -0,0 +1,391 @@
+// Package paystacktest provides an in-process fake of the Paystack API for
+// tests. Point paystackx.NewPaystackClient at Server.URL, or use Server.Client.
+package paystacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// DefaultOTP is the OTP accepted by finalize_transfer and disable_otp_finalize.
+const DefaultOTP = "123456"
+
+// Failure makes matching requests fail. Empty Method or Path match anything;
+// Path matches as a prefix of the request path, for example "/transfer", or
+// the whole path when ExactPath is set.
+type Failure struct {
+	Method     string
+	Path       string
+	ExactPath  bool
+	StatusCode int
+	Message    string
+	// Times is how many requests fail; 0 fails every matching request.
+	Times int
+	// Drop closes the connection without a response instead of writing an error.
+	Drop bool
+	// AfterEffect processes the request before failing it, like a response
+	// lost on the way back after the money has already moved.
+	AfterEffect bool
+	// RetryAfter is sent as a Retry-After header, in whole seconds, for
+	// example with a 429 Too Many Requests.
+	RetryAfter time.Duration
+}
+
+// WebhookDelivery records a webhook sent to the target URL.
+type WebhookDelivery struct {
+	Event      string
+	Body       []byte
+	StatusCode int
+	Err        error
+}
+
+type bankAccount struct {
+	bankCode string
+	number   string
+	name     string
+}
+
+// Server is a stateful fake Paystack API running on httptest.
+type Server struct {
+	URL       string
+	SecretKey string
+
+	server *httptest.Server
+
+	mu             sync.Mutex
+	nextID         int
+	otpEnabled     bool
+	autoComplete   bool
+	webhookURL     string
+	balances       map[string]int64
+	banks          []paystackx.Banks
+	bankAccounts   []bankAccount
+	customers      map[string]*paystackx.PaystackEventCustomer
+	identified     map[string]bool
+	accounts       map[string]*paystackx.VirtaualAccountData
+	recipients     map[string]*recipient
+	transfers      []*transfer
+	plans          []*paystackx.Plan
+	subscriptions  []*paystackx.Subscription
+	deposits       []*deposit
+	ledger         []paystackx.BalanceLedgerEntry
+	settlements    []*settlement
+	subaccounts    []*paystackx.Subaccount
+	splits         []*paystackx.Split
+	failures       []*Failure
+	deliveries     []WebhookDelivery
+	webhookClient  *http.Client
+	requestsByPath map[string]int
+}
+
+// NewServer starts a fake that accepts secretKey and signs webhooks with it.
+// It starts with a few Nigerian banks, OTP disabled and an empty balance.
+func NewServer(secretKey string) *Server {
+	s := &Server{
+		SecretKey:      secretKey,
+		balances:       make(map[string]int64),
+		customers:      make(map[string]*paystackx.PaystackEventCustomer),
+		identified:     make(map[string]bool),
+		accounts:       make(map[string]*paystackx.VirtaualAccountData),
+		recipients:     make(map[string]*recipient),
+		webhookClient:  &http.Client{Timeout: 10 * time.Second},
+		requestsByPath: make(map[string]int),
+		banks: []paystackx.Banks{
+			{ID: 1, Name: "Access Bank", Slug: "access-bank", Code: "044", Country: "Nigeria", Currency: "NGN", Type: "nuban", Active: true},
+			{ID: 7, Name: "First Bank of Nigeria", Slug: "first-bank-of-nigeria", Code: "011", Country: "Nigeria", Currency: "NGN", Type: "nuban", Active: true},
+			{ID: 9, Name: "Guaranty Trust Bank", Slug: "guaranty-trust-bank", Code: "058", Country: "Nigeria", Currency: "NGN", Type: "nuban", Active: true},
+			{ID: 20, Name: "Wema Bank", Slug: "wema-bank", Code: "035", Country: "Nigeria", Currency: "NGN", Type: "nuban", Active: true},
+		},
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a paystackx client pointed at the fake.
+func (s *Server) Client(opts ...paystackx.ClientOption) paystackx.PaystackService {
+	return paystackx.NewPaystackClient(s.URL, s.SecretKey, opts...)
+}
+
+// SetBalance sets the available balance in the minor unit of currency.
+func (s *Server) SetBalance(currency string, amount int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[strings.ToUpper(currency)] = amount
+}
+
+// Balance returns the available balance in the minor unit of currency.
+func (s *Server) Balance(currency string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balances[strings.ToUpper(currency)]
+}
+
+// AddBank adds a bank to the bank list.
+func (s *Server) AddBank(bank paystackx.Banks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.banks = append(s.banks, bank)
+}
+
+// AddBankAccount registers an account that bank/resolve and recipient
+// creation can find.
+func (s *Server) AddBankAccount(bankCode, accountNumber, accountName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bankAccounts = append(s.bankAccounts, bankAccount{bankCode: bankCode, number: accountNumber, name: accountName})
+}
+
+// SetOTPRequired controls whether new transfers wait for finalize_transfer.
+func (s *Server) SetOTPRequired(required bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.otpEnabled = required
+}
+
+// SetAutoComplete makes transfers succeed, and send transfer.success, as soon
+// as they are queued instead of waiting for CompleteTransfer.
+func (s *Server) SetAutoComplete(autoComplete bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoComplete = autoComplete
+}
+
+// SetWebhookURL sets where webhooks are delivered. Leave it empty to disable them.
+func (s *Server) SetWebhookURL(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhookURL = url
+}
+
+// Fail injects a failure for matching requests. Later failures take precedence.
+func (s *Server) Fail(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if failure.StatusCode == 0 {
+		failure.StatusCode = http.StatusInternalServerError
+	}
+	if failure.Message == "" {
+		failure.Message = http.StatusText(failure.StatusCode)
+	}
+	s.failures = append(s.failures, &failure)
+}
+
+// ClearFailures removes every injected failure.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns how many requests reached path, including failed ones.
+func (s *Server) Requests(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requestsByPath[method+" "+path]
+}
+
+// Customer returns a customer by code.
+func (s *Server) Customer(code string) (paystackx.PaystackEventCustomer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	customer, ok := s.customers[code]
+	if !ok {
+		return paystackx.PaystackEventCustomer{}, false
+	}
+	return *customer, true
+}
+
+// Identified reports whether a customer passed identification.
+func (s *Server) Identified(customerCode string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.identified[customerCode]
+}
+
+// DedicatedAccount returns the dedicated account assigned to a customer code.
+func (s *Server) DedicatedAccount(customerCode string) (paystackx.VirtaualAccountData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[customerCode]
+	if !ok {
+		return paystackx.VirtaualAccountData{}, false
+	}
+	return *account, true
+}
+
+// Recipient returns a transfer recipient by code, decoded from the shape the
+// recipient endpoints return. It panics if paystackx.TransferRecipient no
+// longer decodes that shape.
+func (s *Server) Recipient(code string) (paystackx.TransferRecipient, bool) {
+	s.mu.Lock()
+	recipient, ok := s.recipients[code]
+	if !ok {
+		s.mu.Unlock()
+		return paystackx.TransferRecipient{}, false
+	}
+	resource := recipient.resource()
+	s.mu.Unlock()
+
+	var data paystackx.TransferRecipient
+	if err := convert(resource, &data); err != nil {
+		panic(err)
+	}
+	return data, true
+}
+
+// Transfer returns a transfer by reference or transfer code, decoded from the
+// shape the transfer endpoints return. It panics if paystackx.Transfer no
+// longer decodes that shape.
+func (s *Server) Transfer(referenceOrCode string) (paystackx.Transfer, bool) {
+	s.mu.Lock()
+	transfer := s.findTransfer(referenceOrCode)
+	if transfer == nil {
+		s.mu.Unlock()
+		return paystackx.Transfer{}, false
+	}
+	resource := transfer.resource()
+	s.mu.Unlock()
+
+	var data paystackx.Transfer
+	if err := convert(resource, &data); err != nil {
+		panic(err)
+	}
+	return data, true
+}
+
+// Transfers returns every transfer in the order they were created.
+func (s *Server) Transfers() []paystackx.Transfer {
+	s.mu.Lock()
+	resources := make([]map[string]interface{}, len(s.transfers))
+	for i, transfer := range s.transfers {
+		resources[i] = transfer.resource()
+	}
+	s.mu.Unlock()
+
+	transfers := make([]paystackx.Transfer, len(resources))
+	for i, resource := range resources {
+		if err := convert(resource, &transfers[i]); err != nil {
+			panic(err)
+		}
+	}
+	return transfers
+}
+
+// Webhooks returns every webhook delivery attempt.
+func (s *Server) Webhooks() []WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WebhookDelivery(nil), s.deliveries...)
+}
+
+// CompleteTransfer moves a queued transfer to "success", "failed" or
+// "reversed" and sends the matching transfer webhook. Failed and reversed
+// transfers are refunded to the balance.
+func (s *Server) CompleteTransfer(reference, status string) error {
+	s.mu.Lock()
+	transfer := s.findTransfer(reference)
+	if transfer == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("transfer %s not found", reference)
+	}
+	if err := s.completeTransfer(transfer, status); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	data := transfer.event()
+	s.mu.Unlock()
+
+	return s.SendWebhook("transfer."+status, data)
+}
+
+// completeTransfer must be called with s.mu held.
+func (s *Server) completeTransfer(transfer *transfer, status string) error {
+	switch status {
+	case paystackx.TransferStatusSuccess:
+		transfer.transferredAt = timestamp()
+	case paystackx.TransferStatusFailed, paystackx.TransferStatusReversed:
+		s.balances[transfer.currency] += transfer.amount
+	default:
+		return fmt.Errorf("cannot complete a transfer with status %q", status)
+	}
+	transfer.status = status
+	transfer.updatedAt = timestamp()
+	return nil
+}
+
+// SendWebhook signs {"event": event, "data": data} with the secret key and
+// posts it to the webhook URL. It is a no-op when no URL is set. Webhooks the
+// fake sends on its own are delivered before the API response is written, so
+// tests can assert on them as soon as the client call returns.
+func (s *Server) SendWebhook(event string, data interface{}) error {
+	s.mu.Lock()
+	url := s.webhookURL
+	s.mu.Unlock()
+	if url == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"event": event, "data": data})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(paystackx.PaystackSignatureHeader, paystackx.SignPayload(s.SecretKey, body))
+
+	delivery := WebhookDelivery{Event: event, Body: body}
+	res, err := s.webhookClient.Do(req)
+	if err == nil {
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		delivery.StatusCode = res.StatusCode
+		if res.StatusCode >= http.StatusMultipleChoices {
+			err = fmt.Errorf("webhook %s returned %d", event, res.StatusCode)
+		}
+	}
+	delivery.Err = err
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, delivery)
+	s.mu.Unlock()
+	return err
+}
+
+// findTransfer must be called with s.mu held.
+func (s *Server) findTransfer(referenceOrCode string) *transfer {
+	for _, transfer := range s.transfers {
+		if transfer.reference == referenceOrCode || transfer.code == referenceOrCode || fmt.Sprint(transfer.id) == referenceOrCode {
+			return transfer
+		}
+	}
+	return nil
+}
+
+// newID must be called with s.mu held.
+func (s *Server) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,121 @@
+package paystacktest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+type settlement struct {
+	settlement   paystackx.Settlement
+	transactions []paystackx.PaystackEventData
+}
+
+// AddLedgerEntry adds an entry to the balance ledger and returns it. ID,
+// Integration, Domain and a missing CreatedAt are filled in. The balance
+// itself is left alone; use SetBalance to match it.
+func (s *Server) AddLedgerEntry(entry paystackx.BalanceLedgerEntry) paystackx.BalanceLedgerEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.ID = s.newID()
+	entry.Integration = integrationID
+	entry.Domain = "test"
+	if entry.CreatedAt == "" {
+		entry.CreatedAt = timestamp()
+	}
+	entry.UpdatedAt = entry.CreatedAt
+	s.ledger = append(s.ledger, entry)
+	return entry
+}
+
+// AddSettlement adds a settlement paying out transactions and returns it. ID,
+// Integration, Domain and a missing SettlementDate are filled in, and the
+// totals are computed from the transactions when TotalAmount is zero.
+func (s *Server) AddSettlement(data paystackx.Settlement, transactions ...paystackx.PaystackEventData) paystackx.Settlement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data.ID = s.newID()
+	data.Integration = integrationID
+	data.Domain = "test"
+	if data.Status == "" {
+		data.Status = paystackx.SettlementStatusSuccess
+	}
+	if data.SettlementDate == "" {
+		data.SettlementDate = timestamp()
+	}
+	data.CreatedAt = data.SettlementDate
+	data.UpdatedAt = data.SettlementDate
+	if data.TotalAmount == 0 {
+		for _, transaction := range transactions {
+			data.TotalAmount += transaction.Amount
+			if transaction.Fees != nil {
+				data.TotalFees += *transaction.Fees
+			}
+		}
+		data.TotalProcessed = data.TotalAmount
+		data.EffectiveAmount = data.TotalAmount - data.TotalFees
+	}
+	s.settlements = append(s.settlements, &settlement{settlement: data, transactions: transactions})
+	return data
+}
+
+func (s *Server) listLedger(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	entries := []paystackx.BalanceLedgerEntry{}
+	for _, entry := range s.ledger {
+		if inRange(entry.CreatedAt, from, to) {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(entries))
+	writeSuccess(w, "Balance ledger retrieved", entries[start:end], meta)
+}
+
+func (s *Server) listSettlements(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+
+	s.mu.Lock()
+	settlements := []paystackx.Settlement{}
+	for _, settlement := range s.settlements {
+		if (status == "" || settlement.settlement.Status == status) && inRange(settlement.settlement.SettlementDate, from, to) {
+			settlements = append(settlements, settlement.settlement)
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(settlements))
+	writeSuccess(w, "Settlements retrieved", settlements[start:end], meta)
+}
+
+func (s *Server) listSettlementTransactions(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	var transactions []paystackx.PaystackEventData
+	found := false
+	for _, settlement := range s.settlements {
+		if strconv.Itoa(settlement.settlement.ID) == id {
+			transactions = append([]paystackx.PaystackEventData{}, settlement.transactions...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !found {
+		writeError(w, http.StatusNotFound, "Settlement not found")
+		return
+	}
+	start, end, meta := paginate(r, len(transactions))
+	writeSuccess(w, "Settlement transactions retrieved", transactions[start:end], meta)
+}
@@ -0,0 +1,332 @@
+package paystacktest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// findSubaccount must be called with s.mu held.
+func (s *Server) findSubaccount(idOrCode string) *paystackx.Subaccount {
+	for _, subaccount := range s.subaccounts {
+		if subaccount.SubaccountCode == idOrCode || strconv.Itoa(subaccount.ID) == idOrCode {
+			return subaccount
+		}
+	}
+	return nil
+}
+
+// findSplit must be called with s.mu held.
+func (s *Server) findSplit(id string) *paystackx.Split {
+	for _, split := range s.splits {
+		if strconv.Itoa(split.ID) == id {
+			return split
+		}
+	}
+	return nil
+}
+
+func (s *Server) createSubaccount(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.CreateSubaccountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.BusinessName == "" || request.PercentageCharge < 0 || request.PercentageCharge > 100 {
+		writeValidationError(w, "Business name and a percentage charge between 0 and 100 are required")
+		return
+	}
+
+	s.mu.Lock()
+	if _, ok := s.accountName(request.SettlementBank, request.AccountNumber); !ok {
+		s.mu.Unlock()
+		writeValidationError(w, "Account details are invalid")
+		return
+	}
+	id := s.newID()
+	now := timestamp()
+	subaccount := &paystackx.Subaccount{
+		ID:                 id,
+		Integration:        integrationID,
+		Domain:             "test",
+		SubaccountCode:     fmt.Sprintf("ACCT_%013d", id),
+		BusinessName:       request.BusinessName,
+		Description:        request.Description,
+		Metadata:           request.Metadata,
+		PercentageCharge:   request.PercentageCharge,
+		IsVerified:         true,
+		SettlementBank:     s.bankByCode(request.SettlementBank).Name,
+		AccountNumber:      request.AccountNumber,
+		SettlementSchedule: paystackx.SettlementScheduleAuto,
+		Active:             true,
+		Currency:           "NGN",
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if request.PrimaryContactEmail != "" {
+		subaccount.PrimaryContactEmail = &request.PrimaryContactEmail
+	}
+	s.subaccounts = append(s.subaccounts, subaccount)
+	data := *subaccount
+	s.mu.Unlock()
+
+	writeSuccess(w, "Subaccount created", data, nil)
+}
+
+func (s *Server) listSubaccounts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	subaccounts := []paystackx.Subaccount{}
+	for _, subaccount := range s.subaccounts {
+		subaccounts = append(subaccounts, *subaccount)
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(subaccounts))
+	writeSuccess(w, "Subaccounts retrieved", subaccounts[start:end], meta)
+}
+
+func (s *Server) fetchSubaccount(w http.ResponseWriter, idOrCode string) {
+	s.mu.Lock()
+	subaccount := s.findSubaccount(idOrCode)
+	if subaccount == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Subaccount not found")
+		return
+	}
+	data := *subaccount
+	s.mu.Unlock()
+
+	writeSuccess(w, "Subaccount retrieved", data, nil)
+}
+
+func (s *Server) updateSubaccount(w http.ResponseWriter, r *http.Request, idOrCode string) {
+	var request paystackx.UpdateSubaccountRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subaccount := s.findSubaccount(idOrCode)
+	if subaccount == nil {
+		writeError(w, http.StatusNotFound, "Subaccount not found")
+		return
+	}
+	if request.BusinessName != "" {
+		subaccount.BusinessName = request.BusinessName
+	}
+	if request.Description != "" {
+		subaccount.Description = request.Description
+	}
+	if request.PercentageCharge != nil {
+		subaccount.PercentageCharge = *request.PercentageCharge
+	}
+	if request.SettlementSchedule != "" {
+		subaccount.SettlementSchedule = request.SettlementSchedule
+	}
+	if request.Active != nil {
+		subaccount.Active = *request.Active
+	}
+	if len(request.Metadata) > 0 {
+		subaccount.Metadata = request.Metadata
+	}
+	subaccount.UpdatedAt = timestamp()
+
+	writeSuccess(w, "Subaccount updated", *subaccount, nil)
+}
+
+// splitShares resolves subaccount codes to split shares. It returns a
+// validation message instead when a subaccount is unknown or inactive. It
+// must be called with s.mu held.
+func (s *Server) splitShares(requested []paystackx.SplitSubaccount) ([]paystackx.SplitShare, string) {
+	shares := make([]paystackx.SplitShare, 0, len(requested))
+	for _, entry := range requested {
+		subaccount := s.findSubaccount(entry.Subaccount)
+		if subaccount == nil || !subaccount.Active {
+			return nil, "Subaccount " + entry.Subaccount + " is invalid"
+		}
+		shares = append(shares, paystackx.SplitShare{Subaccount: *subaccount, Share: entry.Share})
+	}
+	return shares, ""
+}
+
+// bearerID returns the ID of the bearer subaccount, or nil when there is none.
+// It must be called with s.mu held.
+func (s *Server) bearerID(code string) *int {
+	if subaccount := s.findSubaccount(code); subaccount != nil {
+		id := subaccount.ID
+		return &id
+	}
+	return nil
+}
+
+func (s *Server) createSplit(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.CreateSplitRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Name == "" || len(request.Subaccounts) == 0 {
+		writeValidationError(w, "Name and subaccounts are required")
+		return
+	}
+	if request.Type != paystackx.SplitTypePercentage && request.Type != paystackx.SplitTypeFlat {
+		writeValidationError(w, "Type is invalid")
+		return
+	}
+	bearerType := request.BearerType
+	if bearerType == "" {
+		bearerType = paystackx.SplitBearerAccount
+	}
+
+	s.mu.Lock()
+	shares, message := s.splitShares(request.Subaccounts)
+	if shares == nil {
+		s.mu.Unlock()
+		writeValidationError(w, message)
+		return
+	}
+	id := s.newID()
+	now := timestamp()
+	split := &paystackx.Split{
+		ID:               id,
+		Name:             request.Name,
+		Type:             request.Type,
+		Currency:         strings.ToUpper(request.Currency),
+		Integration:      integrationID,
+		Domain:           "test",
+		SplitCode:        fmt.Sprintf("SPL_%013d", id),
+		Active:           true,
+		BearerType:       bearerType,
+		BearerSubaccount: s.bearerID(request.BearerSubaccount),
+		Subaccounts:      shares,
+		TotalSubaccounts: len(shares),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	s.splits = append(s.splits, split)
+	data := *split
+	s.mu.Unlock()
+
+	writeSuccess(w, "Split created", data, nil)
+}
+
+func (s *Server) listSplits(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+	active := query.Get("active")
+
+	s.mu.Lock()
+	splits := []paystackx.Split{}
+	for _, split := range s.splits {
+		if (name == "" || split.Name == name) && (active == "" || strconv.FormatBool(split.Active) == active) {
+			splits = append(splits, *split)
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(splits))
+	writeSuccess(w, "Split retrieved", splits[start:end], meta)
+}
+
+func (s *Server) fetchSplit(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	split := s.findSplit(id)
+	if split == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Split not found")
+		return
+	}
+	data := *split
+	s.mu.Unlock()
+
+	writeSuccess(w, "Split retrieved", data, nil)
+}
+
+func (s *Server) updateSplit(w http.ResponseWriter, r *http.Request, id string) {
+	var request paystackx.UpdateSplitRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	split := s.findSplit(id)
+	if split == nil {
+		writeError(w, http.StatusNotFound, "Split not found")
+		return
+	}
+	if request.Name != "" {
+		split.Name = request.Name
+	}
+	if request.Active != nil {
+		split.Active = *request.Active
+	}
+	if request.BearerType != "" {
+		split.BearerType = request.BearerType
+		split.BearerSubaccount = s.bearerID(request.BearerSubaccount)
+	}
+	split.UpdatedAt = timestamp()
+
+	writeSuccess(w, "Split group updated", *split, nil)
+}
+
+func (s *Server) addSplitSubaccount(w http.ResponseWriter, r *http.Request, id string) {
+	var request paystackx.SplitSubaccount
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	split := s.findSplit(id)
+	if split == nil {
+		writeError(w, http.StatusNotFound, "Split not found")
+		return
+	}
+	shares, message := s.splitShares([]paystackx.SplitSubaccount{request})
+	if shares == nil {
+		writeValidationError(w, message)
+		return
+	}
+	split.UpdatedAt = timestamp()
+	for i := range split.Subaccounts {
+		if split.Subaccounts[i].Subaccount.SubaccountCode == request.Subaccount {
+			split.Subaccounts[i].Share = request.Share
+			writeSuccess(w, "Subaccount added", *split, nil)
+			return
+		}
+	}
+	split.Subaccounts = append(split.Subaccounts, shares[0])
+	split.TotalSubaccounts = len(split.Subaccounts)
+
+	writeSuccess(w, "Subaccount added", *split, nil)
+}
+
+func (s *Server) removeSplitSubaccount(w http.ResponseWriter, r *http.Request, id string) {
+	var request struct {
+		Subaccount string `json:"subaccount"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	split := s.findSplit(id)
+	if split == nil {
+		writeError(w, http.StatusNotFound, "Split not found")
+		return
+	}
+	for i := range split.Subaccounts {
+		if split.Subaccounts[i].Subaccount.SubaccountCode == request.Subaccount {
+			split.Subaccounts = append(split.Subaccounts[:i], split.Subaccounts[i+1:]...)
+			split.TotalSubaccounts = len(split.Subaccounts)
+			split.UpdatedAt = timestamp()
+			writeSuccess(w, "Subaccount removed", nil, nil)
+			return
+		}
+	}
+	writeValidationError(w, "Subaccount is not part of the split")
+}
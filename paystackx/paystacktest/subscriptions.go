@@ -0,0 +1,374 @@
+package paystacktest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+var planIntervals = map[string]time.Duration{
+	paystackx.PlanIntervalHourly:     time.Hour,
+	paystackx.PlanIntervalDaily:      24 * time.Hour,
+	paystackx.PlanIntervalWeekly:     7 * 24 * time.Hour,
+	paystackx.PlanIntervalMonthly:    30 * 24 * time.Hour,
+	paystackx.PlanIntervalQuarterly:  91 * 24 * time.Hour,
+	paystackx.PlanIntervalBiannually: 182 * 24 * time.Hour,
+	paystackx.PlanIntervalAnnually:   365 * 24 * time.Hour,
+}
+
+// Subscription returns a subscription by code.
+func (s *Server) Subscription(code string) (paystackx.Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription := s.findSubscription(code)
+	if subscription == nil {
+		return paystackx.Subscription{}, false
+	}
+	return *subscription, true
+}
+
+// ChargeSubscription bills the next invoice of a subscription. A paid invoice
+// sends invoice.update; an unpaid one moves the subscription to "attention"
+// and sends invoice.payment_failed.
+func (s *Server) ChargeSubscription(code string, paid bool) error {
+	s.mu.Lock()
+	subscription := s.findSubscription(code)
+	if subscription == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("subscription %s not found", code)
+	}
+
+	id := s.newID()
+	now := timestamp()
+	invoice := paystackx.InvoiceEventData{
+		Domain:        "test",
+		InvoiceCode:   fmt.Sprintf("INV_%013d", id),
+		Amount:        subscription.Amount,
+		PeriodStart:   now,
+		Status:        "success",
+		Paid:          paid,
+		Authorization: subscription.Authorization,
+		Customer:      subscription.Customer,
+		Transaction: &paystackx.InvoiceTransaction{
+			Reference: fmt.Sprintf("inv-%d", id),
+			Status:    paystackx.TransferStatusSuccess,
+			Amount:    subscription.Amount,
+			Currency:  subscription.Plan.Currency,
+		},
+		CreatedAt: now,
+	}
+	event := paystackx.EventInvoiceUpdate
+	if paid {
+		invoice.PaidAt = &now
+		subscription.PaymentsCount++
+		subscription.OpenInvoice = nil
+	} else {
+		invoice.Status = "failed"
+		invoice.Transaction.Status = "failed"
+		subscription.Status = paystackx.SubscriptionStatusAttention
+		subscription.OpenInvoice = &invoice.InvoiceCode
+		event = paystackx.EventInvoicePaymentFailed
+	}
+	invoice.Subscription = *subscription
+	s.mu.Unlock()
+
+	return s.SendWebhook(event, invoice)
+}
+
+// findPlan must be called with s.mu held.
+func (s *Server) findPlan(idOrCode string) *paystackx.Plan {
+	for _, plan := range s.plans {
+		if plan.PlanCode == idOrCode || fmt.Sprint(plan.ID) == idOrCode {
+			return plan
+		}
+	}
+	return nil
+}
+
+// findSubscription must be called with s.mu held.
+func (s *Server) findSubscription(idOrCode string) *paystackx.Subscription {
+	for _, subscription := range s.subscriptions {
+		if subscription.SubscriptionCode == idOrCode || fmt.Sprint(subscription.ID) == idOrCode {
+			return subscription
+		}
+	}
+	return nil
+}
+
+// findCustomer looks a customer up by email or customer code. It must be
+// called with s.mu held.
+func (s *Server) findCustomer(emailOrCode string) *paystackx.PaystackEventCustomer {
+	if customer, ok := s.customers[emailOrCode]; ok {
+		return customer
+	}
+	for _, customer := range s.customers {
+		if strings.EqualFold(customer.Email, emailOrCode) {
+			return customer
+		}
+	}
+	return nil
+}
+
+func (s *Server) createPlan(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.CreatePlanRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Name == "" || request.Amount <= 0 {
+		writeValidationError(w, "Name and amount are required")
+		return
+	}
+	if _, ok := planIntervals[request.Interval]; !ok {
+		writeValidationError(w, "Interval is invalid")
+		return
+	}
+	currency := strings.ToUpper(request.Currency)
+	if currency == "" {
+		currency = "NGN"
+	}
+
+	s.mu.Lock()
+	id := s.newID()
+	now := timestamp()
+	plan := &paystackx.Plan{
+		PaystackEventPlan: paystackx.PaystackEventPlan{
+			ID:          id,
+			Name:        request.Name,
+			PlanCode:    fmt.Sprintf("PLN_%013d", id),
+			Description: request.Description,
+			Amount:      request.Amount,
+			Interval:    request.Interval,
+			SendInvoice: request.SendInvoices == nil || *request.SendInvoices,
+			SendSMS:     request.SendSMS == nil || *request.SendSMS,
+			Currency:    currency,
+		},
+		Domain:       "test",
+		Integration:  integrationID,
+		InvoiceLimit: request.InvoiceLimit,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.plans = append(s.plans, plan)
+	data := *plan
+	s.mu.Unlock()
+
+	writeSuccess(w, "Plan created", data, nil)
+}
+
+func (s *Server) listPlans(w http.ResponseWriter, r *http.Request) {
+	interval := r.URL.Query().Get("interval")
+
+	s.mu.Lock()
+	var plans []paystackx.Plan
+	for _, plan := range s.plans {
+		if interval == "" || plan.Interval == interval {
+			plans = append(plans, *plan)
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(plans))
+	writeSuccess(w, "Plans retrieved", plans[start:end], meta)
+}
+
+func (s *Server) fetchPlan(w http.ResponseWriter, idOrCode string) {
+	s.mu.Lock()
+	plan := s.findPlan(idOrCode)
+	if plan == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Plan not found")
+		return
+	}
+	data := *plan
+	for _, subscription := range s.subscriptions {
+		if subscription.Plan.ID == plan.ID {
+			data.Subscriptions = append(data.Subscriptions, *subscription)
+		}
+	}
+	s.mu.Unlock()
+
+	writeSuccess(w, "Plan retrieved", data, nil)
+}
+
+func (s *Server) updatePlan(w http.ResponseWriter, r *http.Request, idOrCode string) {
+	var request paystackx.UpdatePlanRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if _, ok := planIntervals[request.Interval]; request.Interval != "" && !ok {
+		writeValidationError(w, "Interval is invalid")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plan := s.findPlan(idOrCode)
+	if plan == nil {
+		writeError(w, http.StatusNotFound, "Plan not found")
+		return
+	}
+	if request.Name != "" {
+		plan.Name = request.Name
+	}
+	if request.Amount > 0 {
+		plan.Amount = request.Amount
+	}
+	if request.Interval != "" {
+		plan.Interval = request.Interval
+	}
+	if request.Description != "" {
+		plan.Description = request.Description
+	}
+	if request.InvoiceLimit > 0 {
+		plan.InvoiceLimit = request.InvoiceLimit
+	}
+	if request.SendInvoices != nil {
+		plan.SendInvoice = *request.SendInvoices
+	}
+	if request.SendSMS != nil {
+		plan.SendSMS = *request.SendSMS
+	}
+	plan.UpdatedAt = timestamp()
+
+	affected := 0
+	if request.UpdateExistingSubscriptions != nil && *request.UpdateExistingSubscriptions {
+		for _, subscription := range s.subscriptions {
+			if subscription.Plan.ID == plan.ID {
+				subscription.Plan = plan.PaystackEventPlan
+				subscription.Amount = plan.Amount
+				affected++
+			}
+		}
+	}
+
+	writeSuccess(w, fmt.Sprintf("Plan updated. %d subscription(s) affected", affected), nil, nil)
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var request paystackx.CreateSubscriptionRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	customer := s.findCustomer(request.Customer)
+	plan := s.findPlan(request.Plan)
+	if customer == nil || plan == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "Customer or plan not found")
+		return
+	}
+
+	id := s.newID()
+	start := time.Now().UTC()
+	if request.StartDate != nil {
+		start = request.StartDate.UTC()
+	}
+	next := start.Add(planIntervals[plan.Interval]).Format("2006-01-02T15:04:05.000Z")
+	authorization := request.Authorization
+	if authorization == "" {
+		authorization = fmt.Sprintf("AUTH_%010d", customer.ID)
+	}
+	subscription := &paystackx.Subscription{
+		ID:               id,
+		Domain:           "test",
+		Status:           paystackx.SubscriptionStatusActive,
+		SubscriptionCode: fmt.Sprintf("SUB_%013d", id),
+		EmailToken:       fmt.Sprintf("tok%012d", id),
+		Amount:           plan.Amount,
+		Quantity:         1,
+		NextPaymentDate:  &next,
+		InvoiceLimit:     plan.InvoiceLimit,
+		CreatedAt:        timestamp(),
+		Plan:             plan.PaystackEventPlan,
+		Customer:         *customer,
+		Authorization:    paystackx.PaystackEventAuthorization{AuthorizationCode: authorization, Reusable: true, Channel: "card"},
+	}
+	s.subscriptions = append(s.subscriptions, subscription)
+	data := *subscription
+	s.mu.Unlock()
+
+	s.SendWebhook(paystackx.EventSubscriptionCreate, data)
+	writeSuccess(w, "Subscription successfully created", map[string]interface{}{
+		"id":                data.ID,
+		"customer":          data.Customer.ID,
+		"plan":              data.Plan.ID,
+		"integration":       integrationID,
+		"domain":            data.Domain,
+		"status":            data.Status,
+		"quantity":          data.Quantity,
+		"amount":            data.Amount,
+		"subscription_code": data.SubscriptionCode,
+		"email_token":       data.EmailToken,
+		"authorization":     data.Authorization,
+		"createdAt":         data.CreatedAt,
+	}, nil)
+}
+
+func (s *Server) toggleSubscription(w http.ResponseWriter, r *http.Request, enable bool) {
+	var request struct {
+		Code  string `json:"code"`
+		Token string `json:"token"`
+	}
+	if !decode(w, r, &request) {
+		return
+	}
+
+	s.mu.Lock()
+	subscription := s.findSubscription(request.Code)
+	if subscription == nil || subscription.EmailToken != request.Token {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Subscription with code not found or already inactive")
+		return
+	}
+	if enable {
+		subscription.Status = paystackx.SubscriptionStatusActive
+		subscription.CancelledAt = nil
+		s.mu.Unlock()
+		writeSuccess(w, "Subscription enabled successfully", nil, nil)
+		return
+	}
+
+	now := timestamp()
+	subscription.Status = paystackx.SubscriptionStatusCancelled
+	subscription.CancelledAt = &now
+	data := *subscription
+	s.mu.Unlock()
+
+	s.SendWebhook(paystackx.EventSubscriptionDisable, data)
+	writeSuccess(w, "Subscription disabled successfully", nil, nil)
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	customer := query.Get("customer")
+	plan := query.Get("plan")
+
+	s.mu.Lock()
+	var subscriptions []paystackx.Subscription
+	for _, subscription := range s.subscriptions {
+		if customer != "" && fmt.Sprint(subscription.Customer.ID) != customer {
+			continue
+		}
+		if plan != "" && fmt.Sprint(subscription.Plan.ID) != plan {
+			continue
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(subscriptions))
+	writeSuccess(w, "Subscriptions retrieved", subscriptions[start:end], meta)
+}
+
+func (s *Server) fetchSubscription(w http.ResponseWriter, idOrCode string) {
+	subscription, ok := s.Subscription(idOrCode)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Subscription not found")
+		return
+	}
+	writeSuccess(w, "Subscription retrieved", subscription, nil)
+}
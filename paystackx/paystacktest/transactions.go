@@ -0,0 +1,43 @@
+package paystacktest
+
+import (
+	"net/http"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// AddCharge records a transaction without crediting the balance or sending a
+// webhook, for example an abandoned checkout. ID, Domain and a missing
+// CreatedAt are filled in.
+func (s *Server) AddCharge(charge paystackx.PaystackEventData) paystackx.PaystackEventData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	charge.ID = s.newID()
+	charge.Domain = "test"
+	if charge.CreatedAt == "" {
+		charge.CreatedAt = timestamp()
+	}
+	s.deposits = append(s.deposits, &deposit{charge: charge, notified: true})
+	return charge
+}
+
+// listTransactions lists the charges of ReceiveTransfer and AddCharge.
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := dateRange(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+
+	s.mu.Lock()
+	charges := []paystackx.PaystackEventData{}
+	for _, deposit := range s.deposits {
+		if (status == "" || deposit.charge.Status == status) && inRange(deposit.charge.CreatedAt, from, to) {
+			charges = append(charges, deposit.charge)
+		}
+	}
+	s.mu.Unlock()
+
+	start, end, meta := paginate(r, len(charges))
+	writeSuccess(w, "Transactions retrieved", charges[start:end], meta)
+}
@@ -0,0 +1,200 @@
+package paystacktest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The fake keeps its own records of recipients and transfers instead of the
+// client's structs and renders them in the shapes Paystack documents. A client
+// struct that drifts from those shapes then fails against the fake the way it
+// would against Paystack.
+
+type recipient struct {
+	id            int
+	code          string
+	recipientType string
+	name          string
+	currency      string
+	description   string
+	email         string
+	accountNumber string
+	accountName   string
+	bankCode      string
+	bankName      string
+	active        bool
+	createdAt     string
+	updatedAt     string
+}
+
+func (r *recipient) details() map[string]interface{} {
+	return map[string]interface{}{
+		"authorization_code": nil,
+		"account_number":     r.accountNumber,
+		"account_name":       nullable(r.accountName),
+		"bank_code":          r.bankCode,
+		"bank_name":          r.bankName,
+	}
+}
+
+// resource renders the recipient as the recipient and transfer endpoints
+// return it.
+func (r *recipient) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"active":         r.active,
+		"createdAt":      r.createdAt,
+		"currency":       r.currency,
+		"description":    r.description,
+		"domain":         "test",
+		"email":          nullable(r.email),
+		"id":             r.id,
+		"integration":    integrationID,
+		"metadata":       nil,
+		"name":           r.name,
+		"recipient_code": r.code,
+		"type":           r.recipientType,
+		"updatedAt":      r.updatedAt,
+		"is_deleted":     false,
+		"isDeleted":      false,
+		"details":        r.details(),
+	}
+}
+
+// event renders the recipient as it appears inside a transfer webhook, where
+// the details carry no authorization code.
+func (r *recipient) event() map[string]interface{} {
+	details := r.details()
+	delete(details, "authorization_code")
+	return map[string]interface{}{
+		"active":         r.active,
+		"currency":       r.currency,
+		"description":    r.description,
+		"domain":         "test",
+		"email":          nullable(r.email),
+		"id":             r.id,
+		"integration":    integrationID,
+		"metadata":       nil,
+		"name":           r.name,
+		"recipient_code": r.code,
+		"type":           r.recipientType,
+		"is_deleted":     false,
+		"details":        details,
+		"created_at":     r.createdAt,
+		"updated_at":     r.updatedAt,
+	}
+}
+
+type transfer struct {
+	id            int
+	amount        int64
+	currency      string
+	reason        string
+	reference     string
+	source        string
+	status        string
+	code          string
+	recipient     *recipient
+	transferredAt string
+	createdAt     string
+	updatedAt     string
+}
+
+// summary renders the transfer as initiate and finalize return it, with the
+// recipient as an ID.
+func (t *transfer) summary() map[string]interface{} {
+	return map[string]interface{}{
+		"integration":   integrationID,
+		"domain":        "test",
+		"amount":        t.amount,
+		"currency":      t.currency,
+		"source":        t.source,
+		"reason":        t.reason,
+		"recipient":     t.recipient.id,
+		"reference":     t.reference,
+		"status":        t.status,
+		"transfer_code": t.code,
+		"id":            t.id,
+		"createdAt":     t.createdAt,
+		"updatedAt":     t.updatedAt,
+	}
+}
+
+// resource renders the transfer as the fetch, verify and list endpoints
+// return it: integration is an ID, timestamps are camelCase and the recipient
+// is expanded.
+func (t *transfer) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"amount":           t.amount,
+		"createdAt":        t.createdAt,
+		"currency":         t.currency,
+		"domain":           "test",
+		"failures":         nil,
+		"id":               t.id,
+		"integration":      integrationID,
+		"reason":           t.reason,
+		"reference":        t.reference,
+		"source":           t.source,
+		"source_details":   nil,
+		"status":           t.status,
+		"titan_code":       nil,
+		"transfer_code":    t.code,
+		"request":          t.id,
+		"transferred_at":   nullable(t.transferredAt),
+		"updatedAt":        t.updatedAt,
+		"recipient":        t.recipient.resource(),
+		"session":          map[string]interface{}{"provider": nil, "id": nil},
+		"fee_charged":      0,
+		"fees_breakdown":   nil,
+		"gateway_response": nil,
+	}
+}
+
+// event renders the transfer as the data of a transfer webhook: integration
+// is an object and timestamps are snake_case.
+func (t *transfer) event() map[string]interface{} {
+	return map[string]interface{}{
+		"amount":   t.amount,
+		"currency": t.currency,
+		"domain":   "test",
+		"failures": nil,
+		"id":       t.id,
+		"integration": map[string]interface{}{
+			"id":            integrationID,
+			"is_live":       false,
+			"business_name": "paystacktest",
+		},
+		"reason":         t.reason,
+		"reference":      t.reference,
+		"source":         t.source,
+		"source_details": nil,
+		"status":         t.status,
+		"titan_code":     nil,
+		"transfer_code":  t.code,
+		"transferred_at": nullable(t.transferredAt),
+		"recipient":      t.recipient.event(),
+		"session":        map[string]interface{}{"provider": nil, "id": nil},
+		"created_at":     t.createdAt,
+		"updated_at":     t.updatedAt,
+	}
+}
+
+// nullable renders an empty string as JSON null.
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// convert decodes a rendered resource into one of the client's structs, the
+// way the client would read it off the wire.
+func convert(resource map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("paystacktest: %T does not decode: %w", v, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package reconcilex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+)
+
+// paystackPageSize is the page size used to walk Paystack lists.
+const paystackPageSize = 100
+
+type paystackSource struct {
+	client paystackx.PaystackService
+}
+
+// NewPaystackSource reads the Paystack transactions and transfers of a window,
+// following pagination to the last page.
+func NewPaystackSource(client paystackx.PaystackService) RemoteSource {
+	return &paystackSource{client: client}
+}
+
+func (p *paystackSource) Records(ctx context.Context, from, to time.Time) ([]RemoteRecord, error) {
+	var records []RemoteRecord
+	for page := 1; ; page++ {
+		response, err := p.client.ListTransactionsWithContext(ctx, &paystackx.ListTransactionsRequest{PerPage: paystackPageSize, Page: page, From: from, To: to})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list paystack transactions page %d: %w", page, err)
+		}
+		for i := range response.Data {
+			charge := &response.Data[i]
+			records = append(records, RemoteRecord{
+				Kind:           KindCharge,
+				Reference:      charge.Reference,
+				Amount:         charge.Money(),
+				Status:         charge.TransactionStatus(),
+				ProviderStatus: charge.Status,
+				CreatedAt:      parseTime(charge.CreatedAt),
+			})
+		}
+		if !response.Meta.HasNextPage() || len(response.Data) == 0 {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		response, err := p.client.ListTransfersWithContext(ctx, &paystackx.ListTransfersRequest{PerPage: paystackPageSize, Page: page, From: from, To: to})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list paystack transfers page %d: %w", page, err)
+		}
+		for i := range response.Data {
+			transfer := &response.Data[i]
+			records = append(records, RemoteRecord{
+				Kind:           KindTransfer,
+				Reference:      transfer.Reference,
+				Amount:         transfer.Money(),
+				Status:         transfer.TransactionStatus(),
+				ProviderStatus: transfer.Status,
+				CreatedAt:      parseTime(transfer.CreatedAt),
+			})
+		}
+		if !response.Meta.HasNextPage() || len(response.Data) == 0 {
+			return records, nil
+		}
+	}
+}
+
+// parseTime reads a Paystack timestamp. An unreadable one is zero, which the
+// Reconciler treats as inside the window.
+func parseTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
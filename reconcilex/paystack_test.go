@@ -0,0 +1,70 @@
+package reconcilex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx"
+	"github.com/Telktia-LTD/longswipe-reuse/paystackx/paystacktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaystackReconciliation(t *testing.T) {
+	fake := paystacktest.NewServer("sk_test_reconcile")
+	defer fake.Close()
+	fake.SetBalance("NGN", 1000000)
+	fake.AddBankAccount("058", "0123456789", "ADA OBI")
+	client := fake.Client(paystackx.WithRetryPolicy(paystackx.NoRetry()))
+
+	// A deposit whose charge.success webhook never arrived, an abandoned
+	// checkout and a charge that was never recorded locally.
+	fake.AddCharge(paystackx.PaystackEventData{Reference: "dep-1", Amount: 250000, Currency: "NGN", Status: paystackx.ChargeStatusSuccess})
+	fake.AddCharge(paystackx.PaystackEventData{Reference: "checkout-1", Amount: 10000, Currency: "NGN", Status: paystackx.ChargeStatusAbandoned})
+	fake.AddCharge(paystackx.PaystackEventData{Reference: "dep-2", Amount: 70000, Currency: "NGN", Status: paystackx.ChargeStatusSuccess})
+
+	// A withdrawal that failed without the transfer.failed webhook.
+	recipient, err := client.CreateTransferRecipient(&paystackx.PaystackCreateTransferRecipientRequest{Type: "nuban", AccountNumber: "0123456789", BankCode: "058", Currency: "NGN"})
+	require.NoError(t, err)
+	_, err = client.InitiateTransfer(paystackx.NewTransferFundsRequest(moneyx.MustParse("1500", "NGN"), recipient.Data.RecipientCode, "wd-1", "Withdrawal"))
+	require.NoError(t, err)
+	require.NoError(t, fake.CompleteTransfer("wd-1", paystackx.TransferStatusFailed))
+
+	now := time.Now().UTC()
+	locals := []interfacesx.Transactions{
+		{ReferenceID: "dep-1", Amount: moneyx.MustParse("2500", "NGN"), Status: interfacesx.Pending, Currency: interfacesx.CurrencyDetails{Abbrev: "NGN"}, CreatedAt: now},
+		{ReferenceID: "wd-1", Amount: moneyx.MustParse("1500", "NGN"), Status: interfacesx.Processing, Currency: interfacesx.CurrencyDetails{Abbrev: "NGN"}, CreatedAt: now},
+		{ReferenceID: "wd-ghost", Amount: moneyx.MustParse("800", "NGN"), Status: interfacesx.Pending, Currency: interfacesx.CurrencyDetails{Abbrev: "NGN"}, CreatedAt: now},
+	}
+	localSource := LocalSourceFunc(func(ctx context.Context, from, to time.Time) ([]interfacesx.Transactions, error) {
+		return locals, nil
+	})
+
+	reconciler := NewReconciler(localSource, NewPaystackSource(client))
+	report, err := reconciler.Reconcile(context.Background(), now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+
+	require.Equal(t, 2, report.Count(StatusMismatch))
+	require.Equal(t, 1, report.Count(MissingRemotely))
+	require.Equal(t, 1, report.Count(MissingLocally))
+	require.Equal(t, 0, report.Count(AmountMismatch))
+
+	fixes := report.Fixes()
+	require.Len(t, fixes, 2)
+	require.Equal(t, "dep-1", fixes[0].Transaction.ReferenceID)
+	require.Equal(t, interfacesx.Completed, fixes[0].To)
+	require.Equal(t, "wd-1", fixes[1].Transaction.ReferenceID)
+	require.Equal(t, interfacesx.Failed, fixes[1].To)
+	for _, fix := range fixes {
+		require.True(t, fix.Valid)
+	}
+
+	for _, mismatch := range report.Mismatches {
+		if mismatch.Kind == MissingLocally {
+			require.Equal(t, "dep-2", mismatch.Reference)
+			require.Equal(t, KindCharge, mismatch.Remote.Kind)
+		}
+	}
+}
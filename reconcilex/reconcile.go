@@ -0,0 +1,299 @@
+// Package reconcilex compares internal transactions with what the payment
+// provider recorded for the same time window, classifies every difference and
+// proposes status fixes for transactions left behind by a missed webhook.
+package reconcilex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/emitterx"
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+)
+
+// RecordKind tells charges (money in) from transfers (money out).
+type RecordKind string
+
+const (
+	KindCharge   RecordKind = "charge"
+	KindTransfer RecordKind = "transfer"
+)
+
+// MismatchKind classifies a difference between the two sides.
+type MismatchKind string
+
+const (
+	// MissingLocally is a provider record with no internal transaction.
+	MissingLocally MismatchKind = "missing_locally"
+	// MissingRemotely is an internal transaction the provider has no record of.
+	MissingRemotely MismatchKind = "missing_remotely"
+	// AmountMismatch is a transaction whose amount or currency differs.
+	AmountMismatch MismatchKind = "amount_mismatch"
+	// StatusMismatch is a transaction whose status differs.
+	StatusMismatch MismatchKind = "status_mismatch"
+	// DuplicateLocally is an internal transaction whose ReferenceID an earlier
+	// one already uses. Only the first is compared with the provider.
+	DuplicateLocally MismatchKind = "duplicate_locally"
+)
+
+// RemoteRecord is a transaction as the provider reports it. Status is already
+// mapped onto interfacesx.TransactionStatus; ProviderStatus keeps the raw one.
+// A zero CreatedAt counts as inside the window the record was loaded for.
+type RemoteRecord struct {
+	Kind           RecordKind
+	Reference      string
+	Amount         moneyx.Money
+	Status         interfacesx.TransactionStatus
+	ProviderStatus string
+	CreatedAt      time.Time
+}
+
+// LocalSource loads the internal transactions created between from and to.
+type LocalSource interface {
+	Transactions(ctx context.Context, from, to time.Time) ([]interfacesx.Transactions, error)
+}
+
+// RemoteSource loads the provider records created between from and to.
+type RemoteSource interface {
+	Records(ctx context.Context, from, to time.Time) ([]RemoteRecord, error)
+}
+
+// LocalSourceFunc adapts a function to LocalSource.
+type LocalSourceFunc func(ctx context.Context, from, to time.Time) ([]interfacesx.Transactions, error)
+
+func (f LocalSourceFunc) Transactions(ctx context.Context, from, to time.Time) ([]interfacesx.Transactions, error) {
+	return f(ctx, from, to)
+}
+
+// RemoteSourceFunc adapts a function to RemoteSource.
+type RemoteSourceFunc func(ctx context.Context, from, to time.Time) ([]RemoteRecord, error)
+
+func (f RemoteSourceFunc) Records(ctx context.Context, from, to time.Time) ([]RemoteRecord, error) {
+	return f(ctx, from, to)
+}
+
+// StatusFix proposes moving an internal transaction to the provider's status.
+// Valid is false when the lifecycle does not allow the move, for example a
+// COMPLETED transaction the provider reports as FAILED; those need a person.
+type StatusFix struct {
+	Transaction interfacesx.Transactions
+	From        interfacesx.TransactionStatus
+	To          interfacesx.TransactionStatus
+	Valid       bool
+}
+
+// Mismatch is one difference. Local or Remote is nil when that side has no
+// record. Fix is only set for status mismatches whose amounts agree.
+type Mismatch struct {
+	Kind      MismatchKind
+	Reference string
+	Local     *interfacesx.Transactions
+	Remote    *RemoteRecord
+	Fix       *StatusFix
+}
+
+func (m Mismatch) String() string {
+	switch m.Kind {
+	case AmountMismatch:
+		return fmt.Sprintf("%s %s: local %s, remote %s", m.Kind, m.Reference, localAmountString(m.Local, m.Remote), m.Remote.Amount)
+	case StatusMismatch:
+		return fmt.Sprintf("%s %s: local %s, remote %s", m.Kind, m.Reference, m.Local.Status, m.Remote.Status)
+	}
+	return fmt.Sprintf("%s %s", m.Kind, m.Reference)
+}
+
+// Report is the outcome of one reconciliation run.
+type Report struct {
+	From       time.Time
+	To         time.Time
+	Matched    int
+	Mismatches []Mismatch
+}
+
+// Fixes returns the proposed status fixes, valid ones first.
+func (r *Report) Fixes() []StatusFix {
+	var fixes []StatusFix
+	for _, mismatch := range r.Mismatches {
+		if mismatch.Fix != nil {
+			fixes = append(fixes, *mismatch.Fix)
+		}
+	}
+	sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].Valid && !fixes[j].Valid })
+	return fixes
+}
+
+// Count returns how many mismatches of kind the report holds.
+func (r *Report) Count(kind MismatchKind) int {
+	count := 0
+	for _, mismatch := range r.Mismatches {
+		if mismatch.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Reconciler compares a LocalSource with a RemoteSource. Records are matched
+// by ReferenceID and Reference.
+type Reconciler struct {
+	local  LocalSource
+	remote RemoteSource
+	margin time.Duration
+}
+
+// ReconcilerOption configures the Reconciler returned by NewReconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithMargin also loads the remote records created within margin before and
+// after the window, so a transaction the provider timestamped just outside the
+// window is still matched. Records loaded for the margin are only used for
+// matching and are never reported as missing locally.
+func WithMargin(margin time.Duration) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.margin = margin
+	}
+}
+
+func NewReconciler(local LocalSource, remote RemoteSource, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{local: local, remote: remote}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reconcile compares the transactions created between from and to.
+//
+// PENDING and PROCESSING count as the same in-flight status, so a status
+// mismatch means at least one side has settled. A provider record that was
+// canceled before it ever reached the internal ledger, such as an abandoned
+// checkout, is not reported as missing locally.
+func (r *Reconciler) Reconcile(ctx context.Context, from, to time.Time) (*Report, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("reconciliation window ends before it starts")
+	}
+
+	locals, err := r.local.Transactions(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local transactions: %w", err)
+	}
+	remotes, err := r.remote.Records(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remote records: %w", err)
+	}
+	var nearby []RemoteRecord
+	if r.margin > 0 {
+		for _, strip := range [][2]time.Time{{from.Add(-r.margin), from}, {to, to.Add(r.margin)}} {
+			records, err := r.remote.Records(ctx, strip[0], strip[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to load remote records: %w", err)
+			}
+			nearby = append(nearby, records...)
+		}
+	}
+
+	// Records inside the window win over margin records with the same reference.
+	byReference := make(map[string]*RemoteRecord, len(remotes)+len(nearby))
+	for i := range nearby {
+		byReference[nearby[i].Reference] = &nearby[i]
+	}
+	for i := range remotes {
+		byReference[remotes[i].Reference] = &remotes[i]
+	}
+
+	report := &Report{From: from, To: to}
+	seen := make(map[string]bool, len(locals))
+	matched := make(map[string]bool, len(locals))
+	for i := range locals {
+		local := &locals[i]
+		if seen[local.ReferenceID] {
+			report.Mismatches = append(report.Mismatches, Mismatch{Kind: DuplicateLocally, Reference: local.ReferenceID, Local: local, Remote: byReference[local.ReferenceID]})
+			continue
+		}
+		seen[local.ReferenceID] = true
+
+		remote, ok := byReference[local.ReferenceID]
+		if !ok {
+			report.Mismatches = append(report.Mismatches, Mismatch{Kind: MissingRemotely, Reference: local.ReferenceID, Local: local})
+			continue
+		}
+		matched[local.ReferenceID] = true
+
+		mismatches := compare(local, remote)
+		if len(mismatches) == 0 {
+			report.Matched++
+		}
+		report.Mismatches = append(report.Mismatches, mismatches...)
+	}
+
+	for i := range remotes {
+		remote := &remotes[i]
+		if matched[remote.Reference] || remote.Status == interfacesx.Canceled {
+			continue
+		}
+		if !remote.CreatedAt.IsZero() && (remote.CreatedAt.Before(from) || remote.CreatedAt.After(to)) {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, Mismatch{Kind: MissingLocally, Reference: remote.Reference, Remote: remote})
+	}
+	return report, nil
+}
+
+// compare returns the amount and status mismatches between two matched records.
+func compare(local *interfacesx.Transactions, remote *RemoteRecord) []Mismatch {
+	var mismatches []Mismatch
+	amountsAgree := amountMatches(local, remote)
+	if !amountsAgree {
+		mismatches = append(mismatches, Mismatch{Kind: AmountMismatch, Reference: remote.Reference, Local: local, Remote: remote})
+	}
+	if sameStatus(local.Status, remote.Status) {
+		return mismatches
+	}
+
+	mismatch := Mismatch{Kind: StatusMismatch, Reference: remote.Reference, Local: local, Remote: remote}
+	if amountsAgree {
+		mismatch.Fix = &StatusFix{
+			Transaction: *local,
+			From:        local.Status,
+			To:          remote.Status,
+			Valid:       emitterx.IsValidTransition(local.Status, remote.Status),
+		}
+	}
+	return append(mismatches, mismatch)
+}
+
+// amountMatches compares in minor units. A local transaction without a
+// currency is taken to be in the remote currency.
+func amountMatches(local *interfacesx.Transactions, remote *RemoteRecord) bool {
+	amount, err := local.Amount.WithCurrency(localCurrency(local, remote))
+	if err != nil {
+		return false
+	}
+	return amount.Equal(remote.Amount)
+}
+
+func localCurrency(local *interfacesx.Transactions, remote *RemoteRecord) string {
+	if local.Currency.Abbrev != "" {
+		return local.Currency.Abbrev
+	}
+	return remote.Amount.Currency()
+}
+
+func localAmountString(local *interfacesx.Transactions, remote *RemoteRecord) string {
+	amount, err := local.Amount.WithCurrency(localCurrency(local, remote))
+	if err != nil {
+		return local.Amount.String()
+	}
+	return amount.String()
+}
+
+func sameStatus(local, remote interfacesx.TransactionStatus) bool {
+	return local == remote || (inFlight(local) && inFlight(remote))
+}
+
+func inFlight(status interfacesx.TransactionStatus) bool {
+	return status == interfacesx.Pending || status == interfacesx.Processing
+}
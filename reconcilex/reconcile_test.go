@@ -0,0 +1,201 @@
+package reconcilex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Telktia-LTD/longswipe-reuse/interfacesx"
+	"github.com/Telktia-LTD/longswipe-reuse/moneyx"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	windowStart = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd   = time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+)
+
+func local(reference, amount string, status interfacesx.TransactionStatus) interfacesx.Transactions {
+	return interfacesx.Transactions{
+		ReferenceID: reference,
+		Amount:      moneyx.MustParse(amount, "NGN"),
+		Status:      status,
+		Currency:    interfacesx.CurrencyDetails{Abbrev: "NGN"},
+		CreatedAt:   windowStart.Add(time.Hour),
+	}
+}
+
+func remote(reference, amount string, status interfacesx.TransactionStatus) RemoteRecord {
+	return RemoteRecord{
+		Kind:      KindCharge,
+		Reference: reference,
+		Amount:    moneyx.MustParse(amount, "NGN"),
+		Status:    status,
+		CreatedAt: windowStart.Add(time.Hour),
+	}
+}
+
+func sources(locals []interfacesx.Transactions, remotes []RemoteRecord) (LocalSource, RemoteSource) {
+	return LocalSourceFunc(func(ctx context.Context, from, to time.Time) ([]interfacesx.Transactions, error) {
+			return locals, nil
+		}), RemoteSourceFunc(func(ctx context.Context, from, to time.Time) ([]RemoteRecord, error) {
+			return remotes, nil
+		})
+}
+
+func TestReconcile(t *testing.T) {
+	cases := []struct {
+		name   string
+		local  []interfacesx.Transactions
+		remote []RemoteRecord
+		kinds  []MismatchKind
+		fix    *StatusFix
+	}{
+		{
+			name:   "matched",
+			local:  []interfacesx.Transactions{local("ref-1", "1500.5", interfacesx.Completed)},
+			remote: []RemoteRecord{remote("ref-1", "1500.50", interfacesx.Completed)},
+		},
+		{
+			name:   "both in flight",
+			local:  []interfacesx.Transactions{local("ref-1", "100", interfacesx.Pending)},
+			remote: []RemoteRecord{remote("ref-1", "100", interfacesx.Processing)},
+		},
+		{
+			name:   "missed success webhook",
+			local:  []interfacesx.Transactions{local("ref-1", "100", interfacesx.Pending)},
+			remote: []RemoteRecord{remote("ref-1", "100", interfacesx.Completed)},
+			kinds:  []MismatchKind{StatusMismatch},
+			fix:    &StatusFix{From: interfacesx.Pending, To: interfacesx.Completed, Valid: true},
+		},
+		{
+			name:   "completed locally but failed remotely",
+			local:  []interfacesx.Transactions{local("ref-1", "100", interfacesx.Completed)},
+			remote: []RemoteRecord{remote("ref-1", "100", interfacesx.Failed)},
+			kinds:  []MismatchKind{StatusMismatch},
+			fix:    &StatusFix{From: interfacesx.Completed, To: interfacesx.Failed, Valid: false},
+		},
+		{
+			name:   "amount and status differ",
+			local:  []interfacesx.Transactions{local("ref-1", "100", interfacesx.Processing)},
+			remote: []RemoteRecord{remote("ref-1", "99.99", interfacesx.Completed)},
+			kinds:  []MismatchKind{AmountMismatch, StatusMismatch},
+		},
+		{
+			name:   "currency differs",
+			local:  []interfacesx.Transactions{local("ref-1", "100", interfacesx.Completed)},
+			remote: []RemoteRecord{{Reference: "ref-1", Amount: moneyx.MustParse("100", "GHS"), Status: interfacesx.Completed}},
+			kinds:  []MismatchKind{AmountMismatch},
+		},
+		{
+			name:  "missing remotely",
+			local: []interfacesx.Transactions{local("ref-1", "100", interfacesx.Pending)},
+			kinds: []MismatchKind{MissingRemotely},
+		},
+		{
+			name:   "missing locally",
+			remote: []RemoteRecord{remote("ref-1", "100", interfacesx.Completed)},
+			kinds:  []MismatchKind{MissingLocally},
+		},
+		{
+			name:   "abandoned checkout is not missing locally",
+			remote: []RemoteRecord{remote("ref-1", "100", interfacesx.Canceled)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := NewReconciler(sources(tc.local, tc.remote)).Reconcile(context.Background(), windowStart, windowEnd)
+			require.NoError(t, err)
+
+			var kinds []MismatchKind
+			for _, mismatch := range report.Mismatches {
+				kinds = append(kinds, mismatch.Kind)
+			}
+			require.Equal(t, tc.kinds, kinds)
+			if len(tc.kinds) == 0 && len(tc.local) > 0 {
+				require.Equal(t, 1, report.Matched)
+			}
+
+			fixes := report.Fixes()
+			if tc.fix == nil {
+				require.Empty(t, fixes)
+				return
+			}
+			require.Len(t, fixes, 1)
+			require.Equal(t, tc.fix.From, fixes[0].From)
+			require.Equal(t, tc.fix.To, fixes[0].To)
+			require.Equal(t, tc.fix.Valid, fixes[0].Valid)
+			require.Equal(t, "ref-1", fixes[0].Transaction.ReferenceID)
+		})
+	}
+}
+
+func TestReconcileWindow(t *testing.T) {
+	early := remote("early", "100", interfacesx.Completed)
+	early.CreatedAt = windowStart.Add(-time.Minute)
+	late := remote("late", "100", interfacesx.Completed)
+	late.CreatedAt = windowEnd.Add(time.Minute)
+	// Undated records count as inside the window they were loaded for.
+	undatedLate := remote("undated-late", "100", interfacesx.Completed)
+	undatedLate.CreatedAt = time.Time{}
+	undated := remote("undated", "100", interfacesx.Completed)
+	undated.CreatedAt = time.Time{}
+
+	var requested [][2]time.Time
+	localSource, _ := sources([]interfacesx.Transactions{local("early", "100", interfacesx.Pending)}, nil)
+	remoteSource := RemoteSourceFunc(func(ctx context.Context, from, to time.Time) ([]RemoteRecord, error) {
+		requested = append(requested, [2]time.Time{from, to})
+		switch {
+		case to.Equal(windowStart):
+			return []RemoteRecord{early}, nil
+		case from.Equal(windowEnd):
+			return []RemoteRecord{late, undatedLate}, nil
+		}
+		return []RemoteRecord{undated}, nil
+	})
+
+	report, err := NewReconciler(localSource, remoteSource, WithMargin(5*time.Minute)).Reconcile(context.Background(), windowStart, windowEnd)
+	require.NoError(t, err)
+	require.Equal(t, [][2]time.Time{
+		{windowStart, windowEnd},
+		{windowStart.Add(-5 * time.Minute), windowStart},
+		{windowEnd, windowEnd.Add(5 * time.Minute)},
+	}, requested)
+	// early matches the local transaction; late and undated-late were only
+	// loaded for the margin; undated was loaded for the window.
+	require.Len(t, report.Mismatches, 2)
+	require.Equal(t, "status_mismatch early: local PENDING, remote COMPLETED", report.Mismatches[0].String())
+	require.Equal(t, "missing_locally undated", report.Mismatches[1].String())
+
+	_, err = NewReconciler(localSource, remoteSource).Reconcile(context.Background(), windowEnd, windowStart)
+	require.EqualError(t, err, "reconciliation window ends before it starts")
+}
+
+func TestReconcileDuplicateLocalReference(t *testing.T) {
+	first := local("ref-1", "100", interfacesx.Completed)
+	second := local("ref-1", "250", interfacesx.Pending)
+
+	report, err := NewReconciler(sources(
+		[]interfacesx.Transactions{first, second},
+		[]RemoteRecord{remote("ref-1", "100", interfacesx.Completed)},
+	)).Reconcile(context.Background(), windowStart, windowEnd)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, report.Matched)
+	require.Len(t, report.Mismatches, 1)
+	require.Equal(t, DuplicateLocally, report.Mismatches[0].Kind)
+	require.Equal(t, "250.00", report.Mismatches[0].Local.Amount.Decimal())
+	require.Empty(t, report.Fixes())
+}
+
+func TestReconcileSourceError(t *testing.T) {
+	localSource, _ := sources(nil, nil)
+	remoteSource := RemoteSourceFunc(func(ctx context.Context, from, to time.Time) ([]RemoteRecord, error) {
+		return nil, errors.New("boom")
+	})
+
+	_, err := NewReconciler(localSource, remoteSource).Reconcile(context.Background(), windowStart, windowEnd)
+	require.EqualError(t, err, "failed to load remote records: boom")
+}